@@ -1,30 +1,31 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"strconv"
-
-	"github.com/gostream-official/albums/impl/funcs/createalbum"
-	"github.com/gostream-official/albums/impl/funcs/deletealbum"
-	"github.com/gostream-official/albums/impl/funcs/getalbum"
-	"github.com/gostream-official/albums/impl/funcs/getalbums"
-	"github.com/gostream-official/albums/impl/funcs/getalbumtracks"
-	"github.com/gostream-official/albums/impl/funcs/updatealbum"
+	"os"
+
+	"github.com/gostream-official/albums/impl/apikeys"
+	"github.com/gostream-official/albums/impl/config"
 	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/impl/migrations"
+	"github.com/gostream-official/albums/impl/policy"
+	"github.com/gostream-official/albums/impl/routes"
+	"github.com/gostream-official/albums/pkg/auth"
 	"github.com/gostream-official/albums/pkg/env"
+	"github.com/gostream-official/albums/pkg/health"
+	"github.com/gostream-official/albums/pkg/metrics"
 	"github.com/gostream-official/albums/pkg/router"
 	"github.com/gostream-official/albums/pkg/store"
+	"github.com/gostream-official/albums/pkg/tracing"
 
-	"github.com/revx-official/output/log"
+	"github.com/gostream-official/albums/pkg/logging"
 )
 
 // Description:
 //
-//	The package initializer function.
-//	Initializes the log level to info.
-func init() {
-	log.Level = log.LevelInfo
-}
+//	The logger of the main package.
+var log = logging.Named("main")
 
 // Description:
 //
@@ -33,30 +34,36 @@ func init() {
 func main() {
 	log.Infof("booting service instance ...")
 
-	executionPortEnvVar := env.GetEnvironmentVariableWithFallback("PORT", "9871")
-	executionPort, err := strconv.Atoi(executionPortEnvVar)
-
+	serviceConfig, err := config.Load()
 	if err != nil {
-		log.Fatalf("Received invalid execution port")
+		log.Fatalf("failed to load configuration: %s", err)
 	}
 
-	if executionPort < 0 || executionPort > 65535 {
-		log.Fatalf("Received invalid execution port")
+	err = configureLogging(serviceConfig.Logging)
+	if err != nil {
+		log.Fatalf("failed to configure logging: %s", err)
 	}
 
-	mongoUsername, err := env.GetEnvironmentVariable("MONGO_USERNAME")
-	if err != nil {
-		log.Fatalf("Cannot retrieve mongo username via environment variable")
+	settings := make([]logging.Field, 0)
+	for _, setting := range env.Settings(serviceConfig) {
+		settings = append(settings, logging.F(setting.Name, setting.Value))
 	}
 
-	mongoPassword, err := env.GetEnvironmentVariable("MONGO_PASSWORD")
+	log.Log(logging.LevelInfo, "effective configuration", settings...)
+
+	shutdownTracing, err := tracing.Setup(tracing.Config{
+		Exporter:    serviceConfig.Tracing.Exporter,
+		ServiceName: serviceConfig.Tracing.ServiceName,
+		Endpoint:    serviceConfig.Tracing.Endpoint,
+		Insecure:    serviceConfig.Tracing.Insecure,
+		SampleRatio: serviceConfig.Tracing.SampleRatio,
+	})
+
 	if err != nil {
-		log.Fatalf("Cannot retrieve mongo password via environment variable")
+		log.Fatalf("failed to set up tracing: %s", err)
 	}
 
-	mongoHost := env.GetEnvironmentVariableWithFallback("MONGO_HOST", "127.0.0.1:27017")
-
-	connectionURI := fmt.Sprintf("mongodb://%s:%s@%s", mongoUsername, mongoPassword, mongoHost)
+	connectionURI := fmt.Sprintf("mongodb://%s:%s@%s", serviceConfig.Mongo.Username, serviceConfig.Mongo.Password, serviceConfig.Mongo.Host)
 	instance, err := store.NewMongoInstance(connectionURI)
 
 	log.Infof("establishing database connection ...")
@@ -66,22 +73,252 @@ func main() {
 
 	log.Infof("successfully established database connection")
 
-	injector := inject.Injector{
-		MongoInstance: instance,
+	log.Infof("applying database migrations ...")
+	err = instance.Migrate(context.Background(), inject.Database, migrations.All)
+
+	if err != nil {
+		log.Fatalf("failed to apply database migrations: %s", err)
 	}
 
+	checker := health.NewChecker(serviceConfig.Server.HealthCheckTimeout)
+
+	checker.Register("mongo", instance.Ping)
+	checker.RegisterStartup("migrations", func(ctx context.Context) error {
+		return instance.CheckMigrations(ctx, inject.Database, migrations.All)
+	})
+
+	injector := inject.New(instance, inject.WithPolicy(policy.New(policy.Config{
+		Enforced:       serviceConfig.Auth.Enabled,
+		AdminRole:      serviceConfig.Auth.AdminRole,
+		RestrictedRole: serviceConfig.Auth.RestrictedRole,
+		LabelClaim:     serviceConfig.Auth.LabelClaim,
+	})))
+
 	log.Infof("launching router engine ...")
-	engine := router.Default()
+	routerConfig := router.DefaultConfig()
+
+	routerConfig.Redaction.Headers = append(routerConfig.Redaction.Headers, serviceConfig.Logging.RedactHeaders...)
+	routerConfig.Redaction.Headers = append(routerConfig.Redaction.Headers, serviceConfig.Auth.APIKeyHeader)
+	routerConfig.Redaction.QueryParameters = append(routerConfig.Redaction.QueryParameters, serviceConfig.Logging.RedactQueryParameters...)
+	routerConfig.Redaction.BodyFields = append(routerConfig.Redaction.BodyFields, serviceConfig.Logging.RedactBodyFields...)
+	routerConfig.Redaction.MaxBodyLength = serviceConfig.Logging.MaxBodyLength
+	routerConfig.TrustedProxies = serviceConfig.Server.TrustedProxies
+	routerConfig.MaxBodySize = serviceConfig.Server.MaxBodySize
+	routerConfig.CORS = router.CORSConfig{
+		AllowedOrigins:   serviceConfig.CORS.AllowedOrigins,
+		AllowedMethods:   serviceConfig.CORS.AllowedMethods,
+		AllowedHeaders:   serviceConfig.CORS.AllowedHeaders,
+		ExposedHeaders:   serviceConfig.CORS.ExposedHeaders,
+		AllowCredentials: serviceConfig.CORS.AllowCredentials,
+		MaxAge:           serviceConfig.CORS.MaxAge,
+	}
+	routerConfig.Compression.MinSize = serviceConfig.Compression.MinSize
+	routerConfig.Compression.MaxDecompressedSize = serviceConfig.Compression.MaxDecompressedSize
+	routerConfig.Compression.Encodings = nil
+
+	if serviceConfig.Compression.Enabled {
+		routerConfig.Compression.Encodings = serviceConfig.Compression.Encodings
+	}
+
+	engine := router.New(routerConfig)
+
+	if serviceConfig.Logging.AccessLogFormat != "off" {
+		accessLog, err := router.AccessLog(serviceConfig.Logging.AccessLogFormat, os.Stdout, routerConfig.Redaction)
+		if err != nil {
+			log.Fatalf("failed to configure access log: %s", err)
+		}
+
+		engine.Observe(accessLog)
+	}
+
+	// Operational endpoints are served on the admin listener if configured.
+	adminEngine := engine
+	if serviceConfig.Server.AdminAddress != "" {
+		adminEngine = router.New(routerConfig)
+	}
+
+	adminEngine.Handle("GET", "/healthz", checker.LivenessHandler()).Hidden()
+	adminEngine.Handle("GET", "/startupz", checker.StartupHandler()).Hidden()
+	adminEngine.Handle("GET", "/readyz", checker.ReadinessHandler()).Hidden()
+	adminEngine.Handle("GET", "/health", checker.ReportHandler()).Hidden()
+
+	if serviceConfig.Metrics.Enabled {
+		metrics.RegisterRuntimeMetrics(metrics.Default)
+		metrics.RegisterPoolMetrics(metrics.Default, instance)
+
+		engine.Observe(metrics.HTTPObserver(metrics.Default))
+		instance.Observe(metrics.StoreObserver(metrics.Default))
+
+		adminEngine.Handle("GET", serviceConfig.Metrics.Path, metrics.Handler(metrics.Default)).Hidden()
+	}
+
+	guard, err := createGuard(serviceConfig.Auth, injector)
+	if err != nil {
+		log.Fatalf("failed to configure authentication: %s", err)
+	}
+
+	err = routes.Register(engine, guard, injector, serviceConfig)
+	if err != nil {
+		log.Fatalf("failed to register routes: %s", err)
+	}
+
+	if serviceConfig.OpenAPI.Enabled {
+		err = routes.RegisterOpenAPI(engine, serviceConfig)
+		if err != nil {
+			log.Fatalf("failed to generate OpenAPI specification: %s", err)
+		}
+	}
+
+	engine.OnDrain(checker.Drain)
+
+	// Registered first, so that spans of the remaining hooks are flushed.
+	engine.OnShutdown(shutdownTracing)
+
+	engine.OnShutdown(func(ctx context.Context) error {
+		log.Infof("closing database connection ...")
+		return instance.Disconnect(ctx)
+	})
+
+	serverConfig := router.ServerConfig{
+		Port:              serviceConfig.Port,
+		ReadTimeout:       serviceConfig.Server.ReadTimeout,
+		ReadHeaderTimeout: serviceConfig.Server.ReadHeaderTimeout,
+		WriteTimeout:      serviceConfig.Server.WriteTimeout,
+		IdleTimeout:       serviceConfig.Server.IdleTimeout,
+		ShutdownTimeout:   serviceConfig.Server.ShutdownTimeout,
+		DrainDelay:        serviceConfig.Server.DrainDelay,
+	}
+
+	if serviceConfig.Server.TLS.CertFile != "" {
+		serverConfig.TLS = &router.TLSConfig{
+			CertFile:       serviceConfig.Server.TLS.CertFile,
+			KeyFile:        serviceConfig.Server.TLS.KeyFile,
+			ClientCAFile:   serviceConfig.Server.TLS.ClientCAFile,
+			ReloadInterval: serviceConfig.Server.TLS.ReloadInterval,
+		}
+	}
 
-	engine.HandleWith("GET", "/albums", getalbums.Handler).Inject(injector)
-	engine.HandleWith("GET", "/albums/:id", getalbum.Handler).Inject(injector)
-	engine.HandleWith("GET", "/albums/:id/tracks", getalbumtracks.Handler).Inject(injector)
-	engine.HandleWith("POST", "/albums", createalbum.Handler).Inject(injector)
-	engine.HandleWith("PUT", "/albums/:id", updatealbum.Handler).Inject(injector)
-	engine.HandleWith("DELETE", "/albums/:id", deletealbum.Handler).Inject(injector)
+	for _, address := range serviceConfig.ListenAddresses {
+		serverConfig.Listeners = append(serverConfig.Listeners, router.ListenerConfig{
+			Address: address,
+			TLS:     serverConfig.TLS,
+		})
+	}
+
+	if serviceConfig.Server.AdminAddress != "" {
+		if len(serverConfig.Listeners) == 0 {
+			serverConfig.Listeners = append(serverConfig.Listeners, router.ListenerConfig{
+				Name:    "default",
+				Address: fmt.Sprintf(":%d", serviceConfig.Port),
+				TLS:     serverConfig.TLS,
+			})
+		}
+
+		serverConfig.Listeners = append(serverConfig.Listeners, router.ListenerConfig{
+			Name:    "admin",
+			Address: serviceConfig.Server.AdminAddress,
+			Router:  adminEngine,
+		})
+	}
+
+	err = engine.Run(serverConfig)
 
-	err = engine.Run(uint16(executionPort))
 	if err != nil {
-		log.Fatalf("failed to launch router engine: %s", err)
+		log.Fatalf("router engine stopped unexpectedly: %s", err)
+	}
+
+	log.Infof("service instance stopped gracefully")
+}
+
+// Description:
+//
+//	Creates the authentication guard, verifying JWTs with the configured keys and API keys via the API key store.
+//
+// Parameters:
+//
+//	config 		The authentication configuration.
+//	injector 	The injector, providing the clock and the API key store.
+//
+// Returns:
+//
+//	The guard, or an error if the JWKS file cannot be loaded.
+func createGuard(config config.AuthConfig, injector *inject.Injector) (*auth.Guard, error) {
+	keys := auth.MultiKeySet{}
+
+	if config.JWKSFile != "" {
+		fileKeys, err := auth.LoadKeySetFile(config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, fileKeys)
 	}
+
+	if config.JWKSURL != "" {
+		keys = append(keys, auth.NewRemoteKeySet(config.JWKSURL, config.JWKSCacheTTL, injector.Clock))
+	}
+
+	if config.HMACSecret != "" {
+		keys = append(keys, auth.SecretKeySet("", []byte(config.HMACSecret)))
+	}
+
+	// API keys are issued by administrators, which authenticate via JWT only.
+	if config.Enabled && len(keys) == 0 {
+		return nil, fmt.Errorf("no JWT verification keys configured, set AUTH_JWKS_FILE, AUTH_JWKS_URL or AUTH_HMAC_SECRET, or disable authentication with AUTH_ENABLED=false")
+	}
+
+	verifier := auth.NewVerifier(auth.VerifierConfig{
+		Keys:     keys,
+		Issuer:   config.Issuer,
+		Audience: config.Audience,
+		Leeway:   config.Leeway,
+		Clock:    injector.Clock,
+	})
+
+	return auth.NewGuard("albums",
+		auth.BearerAuthenticator(verifier),
+		auth.APIKeyAuthenticator(config.APIKeyHeader, apikeys.NewResolver(injector)),
+	), nil
+}
+
+// Description:
+//
+//	Configures the logging facade.
+//
+// Parameters:
+//
+//	config The logging configuration.
+//
+// Returns:
+//
+//	An error if the configuration is invalid.
+func configureLogging(config config.LoggingConfig) error {
+	level, err := logging.ParseLevel(config.Level)
+	if err != nil {
+		return err
+	}
+
+	levels, err := logging.ParseLevelOverrides(config.Levels)
+	if err != nil {
+		return err
+	}
+
+	var encoder logging.Encoder
+
+	switch config.Format {
+	case "console":
+		encoder = logging.ConsoleEncoder{}
+	case "json":
+		encoder = logging.JSONEncoder{}
+	default:
+		return fmt.Errorf("unknown log format: %s", config.Format)
+	}
+
+	logging.Configure(logging.Config{
+		Encoder: encoder,
+		Level:   level,
+		Levels:  levels,
+	})
+
+	return nil
 }
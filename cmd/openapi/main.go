@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+
+	"github.com/gostream-official/albums/impl/config"
+	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/impl/routes"
+	"github.com/gostream-official/albums/pkg/auth"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/openapi"
+	"github.com/gostream-official/albums/pkg/router"
+)
+
+// Description:
+//
+//	The logger of the generator.
+var log = logging.Named("openapi")
+
+// Description:
+//
+//	The OpenAPI generator.
+//	Registers the routes of the service with the default configuration and writes their specification.
+//	With -check, fails if the checked-in specification differs from the generated one instead.
+//
+// Example:
+//
+//	go run ./cmd/openapi -out docs/openapi.json
+//	go run ./cmd/openapi -check
+func main() {
+	out := flag.String("out", "docs/openapi.json", "the path of the specification")
+	check := flag.Bool("check", false, "fail if the specification at -out is outdated instead of writing it")
+	flag.Parse()
+
+	serviceConfig, err := config.Defaults()
+	if err != nil {
+		log.Fatalf("failed to load default configuration: %s", err)
+	}
+
+	// The handlers are never called, so no stores or keys are required.
+	engine := router.New(router.DefaultConfig())
+	err = routes.Register(engine, auth.NewGuard("albums"), inject.New(nil), serviceConfig)
+	if err != nil {
+		log.Fatalf("failed to register routes: %s", err)
+	}
+
+	data, err := openapi.Encode(routes.Document(engine, serviceConfig))
+	if err != nil {
+		log.Fatalf("failed to encode specification: %s", err)
+	}
+
+	if !*check {
+		err = os.WriteFile(*out, data, 0644)
+		if err != nil {
+			log.Fatalf("failed to write specification: %s", err)
+		}
+
+		log.Infof("wrote specification to %s", *out)
+		return
+	}
+
+	existing, err := os.ReadFile(*out)
+	if err != nil {
+		log.Fatalf("failed to read specification: %s", err)
+	}
+
+	if !bytes.Equal(existing, data) {
+		log.Fatalf("specification %s is outdated, regenerate it with: go run ./cmd/openapi", *out)
+	}
+
+	log.Infof("specification %s is up to date", *out)
+}
@@ -0,0 +1,266 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/impl/models"
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/auth"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/store/query"
+)
+
+const (
+
+	// The prefix of all API keys, which makes leaked keys easy to detect.
+	Prefix = "alb_"
+
+	// The number of random bytes of an API key.
+	keyBytes = 32
+
+	// The number of trailing key characters kept as hint.
+	hintLength = 4
+
+	// The minimum interval between updates of the last-used timestamp of a key.
+	lastUsedInterval = time.Minute
+)
+
+// Description:
+//
+//	The logger of this package.
+var logger = logging.Named("apikeys")
+
+// Description:
+//
+//	A newly issued API key. The plaintext key is only returned once, on creation and rotation.
+type Issued struct {
+	models.APIKeyInfo
+
+	// The plaintext key.
+	Key string `json:"key" xml:"key"`
+}
+
+// Description:
+//
+//	Resolves API keys via the API key store.
+type Resolver struct {
+
+	// The injector, providing the API key store and the clock.
+	injector *inject.Injector
+}
+
+// Description:
+//
+//	Generates a new API key.
+//
+// Returns:
+//
+//	The plaintext key, its hash and its hint, or an error if no randomness is available.
+func Generate() (string, string, string, error) {
+	random := make([]byte, keyBytes)
+
+	_, err := rand.Read(random)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	key := Prefix + base64.RawURLEncoding.EncodeToString(random)
+	return key, Hash(key), key[len(key)-hintLength:], nil
+}
+
+// Description:
+//
+//	Hashes an API key.
+//	A fast hash suffices, as keys carry 256 bits of randomness.
+//
+// Parameters:
+//
+//	key The plaintext key.
+//
+// Returns:
+//
+//	The hex encoded SHA-256 hash.
+func Hash(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// Description:
+//
+//	Issues a new API key. The key is not stored.
+//
+// Parameters:
+//
+//	injector 	The injector, providing the id generator and the clock.
+//	name 		The display name of the key.
+//	owner 		The subject the key acts on behalf of.
+//	scopes 		The scopes granted to the key.
+//	expiresAt 	The expiry time of the key, or nil.
+//
+// Returns:
+//
+//	The issued key, or an error if no randomness is available.
+func Issue(injector *inject.Injector, name string, owner string, scopes []string, expiresAt *time.Time) (*Issued, error) {
+	key, hash, hint, err := Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Issued{
+		APIKeyInfo: models.APIKeyInfo{
+			ID:        injector.IDs.NewID(),
+			Name:      name,
+			Owner:     owner,
+			Scopes:    scopes,
+			Hash:      hash,
+			Hint:      hint,
+			CreatedAt: injector.Clock.Now().UTC(),
+			ExpiresAt: expiresAt,
+		},
+		Key: key,
+	}, nil
+}
+
+// Description:
+//
+//	Finds an API key by its id.
+//
+// Parameters:
+//
+//	ctx 		The request context.
+//	injector 	The injector, providing the API key store.
+//	id 			The id of the key.
+//
+// Returns:
+//
+//	The key, nil if it does not exist, or an error if the lookup fails.
+func FindByID(ctx context.Context, injector *inject.Injector, id string) (*models.APIKeyInfo, error) {
+	filter := query.Filter{
+		Root: query.FilterOperatorEq{
+			Key:   "_id",
+			Value: id,
+		},
+		Limit: 1,
+	}
+
+	items, err := injector.APIKeys.FindItems(ctx, &filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	return &items[0], nil
+}
+
+// Description:
+//
+//	Creates a resolver of API keys.
+//
+// Parameters:
+//
+//	injector The injector, providing the API key store and the clock.
+//
+// Returns:
+//
+//	The created resolver.
+func NewResolver(injector *inject.Injector) auth.APIKeyResolver {
+	return &Resolver{injector: injector}
+}
+
+// Description:
+//
+//	Resolves an API key to a principal acting on behalf of the key owner.
+//	Records the time the key was used, at most once per minute.
+//
+// Parameters:
+//
+//	ctx The request context.
+//	key The plaintext API key.
+//
+// Returns:
+//
+//	The principal, or an error if the key is unknown, expired or revoked.
+//	The error wraps auth.ErrUnavailable if the key cannot be looked up.
+func (resolver *Resolver) Resolve(ctx context.Context, key string) (*api.Principal, error) {
+	filter := query.Filter{
+		Root: query.FilterOperatorEq{
+			Key:   "hash",
+			Value: Hash(key),
+		},
+		Limit: 1,
+	}
+
+	items, err := resolver.injector.APIKeys.FindItems(ctx, &filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: api key lookup failed: %s", auth.ErrUnavailable, err)
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("unknown api key")
+	}
+
+	info := items[0]
+	now := resolver.injector.Clock.Now()
+
+	if info.RevokedAt != nil {
+		return nil, fmt.Errorf("api key revoked")
+	}
+
+	if info.ExpiresAt != nil && now.After(*info.ExpiresAt) {
+		return nil, fmt.Errorf("api key expired")
+	}
+
+	if info.LastUsedAt == nil || now.Sub(*info.LastUsedAt) > lastUsedInterval {
+		resolver.touch(ctx, info.ID, now)
+	}
+
+	return &api.Principal{
+		Subject: info.Owner,
+		Method:  auth.MethodAPIKey,
+		Scopes:  info.Scopes,
+		Claims: map[string]interface{}{
+			"keyId": info.ID,
+		},
+	}, nil
+}
+
+// Description:
+//
+//	Records the time a key was used. Failures are logged only.
+//
+// Parameters:
+//
+//	ctx The request context.
+//	id 	The id of the key.
+//	now The current time.
+func (resolver *Resolver) touch(ctx context.Context, id string, now time.Time) {
+	filter := query.Filter{
+		Root: query.FilterOperatorEq{
+			Key:   "_id",
+			Value: id,
+		},
+	}
+
+	update := query.Update{
+		Root: query.UpdateOperatorSet{
+			Set: map[string]interface{}{
+				"lastUsedAt": now,
+			},
+		},
+	}
+
+	_, err := resolver.injector.APIKeys.UpdateItem(ctx, &filter, &update)
+	if err != nil {
+		logger.Warnf("failed to record usage of api key %s: %s", id, err)
+	}
+}
@@ -0,0 +1,117 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/impl/models"
+	"github.com/gostream-official/albums/pkg/auth"
+	"github.com/gostream-official/albums/pkg/clock"
+	"github.com/gostream-official/albums/pkg/ids"
+	"github.com/gostream-official/albums/pkg/store/storetest"
+)
+
+func TestResolve(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	past := now.Add(-time.Hour)
+
+	keys := storetest.NewMemoryStore[models.APIKeyInfo]()
+	injector := inject.New(nil,
+		inject.WithAPIKeyStore(keys),
+		inject.WithClock(clock.Fixed(now)),
+		inject.WithIDGenerator(ids.Sequence("active", "revoked", "expired")),
+	)
+
+	plaintexts := make(map[string]string)
+	for _, modify := range []func(info *models.APIKeyInfo){
+		func(info *models.APIKeyInfo) {},
+		func(info *models.APIKeyInfo) { info.RevokedAt = &past },
+		func(info *models.APIKeyInfo) { info.ExpiresAt = &past },
+	} {
+		issued, err := Issue(injector, "job", "owner", []string{"albums:write"}, nil)
+		if err != nil {
+			t.Fatalf("failed to issue key: %s", err)
+		}
+
+		modify(&issued.APIKeyInfo)
+		plaintexts[issued.ID] = issued.Key
+
+		err = keys.CreateItem(context.Background(), issued.APIKeyInfo)
+		if err != nil {
+			t.Fatalf("failed to store key: %s", err)
+		}
+	}
+
+	resolver := NewResolver(injector)
+
+	principal, err := resolver.Resolve(context.Background(), plaintexts["active"])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if principal.Subject != "owner" || principal.Method != auth.MethodAPIKey || !principal.HasScope("albums:write") {
+		t.Fatalf("unexpected principal %+v", principal)
+	}
+
+	for _, key := range []string{plaintexts["revoked"], plaintexts["expired"], Prefix + "guessed"} {
+		_, err := resolver.Resolve(context.Background(), key)
+		if err == nil || errors.Is(err, auth.ErrUnavailable) {
+			t.Fatalf("expected an invalid key error, got %v", err)
+		}
+	}
+
+	keys.Fail(errors.New("connection refused"))
+
+	_, err = resolver.Resolve(context.Background(), plaintexts["active"])
+	if !errors.Is(err, auth.ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestResolveRecordsUsage(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+
+	keys := storetest.NewMemoryStore[models.APIKeyInfo]()
+	injector := inject.New(nil,
+		inject.WithAPIKeyStore(keys),
+		inject.WithClock(clock.Fixed(now)),
+	)
+
+	issued, _ := Issue(injector, "job", "owner", nil, nil)
+	_ = keys.CreateItem(context.Background(), issued.APIKeyInfo)
+
+	for index := 0; index < 3; index++ {
+		_, err := NewResolver(injector).Resolve(context.Background(), issued.Key)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	if calls := keys.Calls("update"); calls != 1 {
+		t.Fatalf("expected usage to be recorded once per minute, got %d updates", calls)
+	}
+
+	stored := keys.Items()[0]
+	if stored.LastUsedAt == nil || !stored.LastUsedAt.Equal(now) {
+		t.Fatalf("expected last usage at %s, got %v", now, stored.LastUsedAt)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	key, hash, hint, err := Generate()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(key, Prefix) || !strings.HasSuffix(key, hint) {
+		t.Fatalf("unexpected key %q with hint %q", key, hint)
+	}
+
+	if hash != Hash(key) || strings.Contains(hash, key) {
+		t.Fatalf("unexpected hash %q", hash)
+	}
+}
@@ -0,0 +1,364 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gostream-official/albums/pkg/env"
+)
+
+// Description:
+//
+//	The configuration of this service.
+//	Populated from default values, an optional configuration file,
+//	environment variables and secret files (see env.Load).
+type Config struct {
+
+	// The port the service listens on.
+	// Only used if no listen addresses are configured.
+	Port uint16 `env:"PORT" default:"9871" yaml:"port" toml:"port"`
+
+	// The addresses the service listens on, e.g. 127.0.0.1:9871, unix:///run/albums.sock or systemd://.
+	ListenAddresses []string `env:"LISTEN_ADDRESSES" yaml:"listenAddresses" toml:"listenAddresses"`
+
+	// The logging configuration.
+	Logging LoggingConfig `yaml:"logging" toml:"logging"`
+
+	// The HTTP server configuration.
+	Server ServerConfig `yaml:"server" toml:"server"`
+
+	// The MongoDB configuration.
+	Mongo MongoConfig `yaml:"mongo" toml:"mongo"`
+
+	// The compression configuration.
+	Compression CompressionConfig `yaml:"compression" toml:"compression"`
+
+	// The CORS configuration.
+	CORS CORSConfig `yaml:"cors" toml:"cors"`
+
+	// The authentication configuration.
+	Auth AuthConfig `yaml:"auth" toml:"auth"`
+
+	// The rate limiting configuration.
+	RateLimit RateLimitConfig `yaml:"rateLimit" toml:"rateLimit"`
+
+	// The metrics configuration.
+	Metrics MetricsConfig `yaml:"metrics" toml:"metrics"`
+
+	// The OpenAPI configuration.
+	OpenAPI OpenAPIConfig `yaml:"openapi" toml:"openapi"`
+
+	// The tracing configuration.
+	Tracing TracingConfig `yaml:"tracing" toml:"tracing"`
+}
+
+// Description:
+//
+//	The logging configuration of this service.
+type LoggingConfig struct {
+
+	// The minimum level of entries to log: trace, debug, info, warn or error.
+	Level string `env:"LOG_LEVEL" default:"info" yaml:"level" toml:"level"`
+
+	// The log format: console or json.
+	Format string `env:"LOG_FORMAT" default:"console" yaml:"format" toml:"format"`
+
+	// Per-package level overrides, e.g. router=debug,request=warn.
+	Levels []string `env:"LOG_LEVELS" yaml:"levels" toml:"levels"`
+
+	// Additional headers redacted in request logs.
+	RedactHeaders []string `env:"LOG_REDACT_HEADERS" yaml:"redactHeaders" toml:"redactHeaders"`
+
+	// Additional query parameters redacted in request logs.
+	RedactQueryParameters []string `env:"LOG_REDACT_QUERY_PARAMETERS" yaml:"redactQueryParameters" toml:"redactQueryParameters"`
+
+	// Additional JSON body fields redacted in request logs, e.g. password or credentials.token.
+	RedactBodyFields []string `env:"LOG_REDACT_BODY_FIELDS" yaml:"redactBodyFields" toml:"redactBodyFields"`
+
+	// The maximum length of request bodies in request logs.
+	MaxBodyLength int `env:"LOG_MAX_BODY_LENGTH" default:"1024" yaml:"maxBodyLength" toml:"maxBodyLength"`
+
+	// The access log format: json, common, combined or off.
+	AccessLogFormat string `env:"ACCESS_LOG_FORMAT" default:"json" yaml:"accessLogFormat" toml:"accessLogFormat"`
+}
+
+// Description:
+//
+//	The HTTP server configuration of this service.
+type ServerConfig struct {
+
+	// The networks (CIDR) or IP addresses of trusted reverse proxies.
+	// Only for these, the client IP is taken from the X-Forwarded-For and X-Real-IP headers.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" yaml:"trustedProxies" toml:"trustedProxies"`
+
+	// The maximum duration for reading an entire request, including the body.
+	ReadTimeout time.Duration `env:"HTTP_READ_TIMEOUT" default:"15s" yaml:"readTimeout" toml:"readTimeout"`
+
+	// The maximum duration for reading the request headers.
+	ReadHeaderTimeout time.Duration `env:"HTTP_READ_HEADER_TIMEOUT" default:"5s" yaml:"readHeaderTimeout" toml:"readHeaderTimeout"`
+
+	// The maximum duration before timing out writes of a response.
+	WriteTimeout time.Duration `env:"HTTP_WRITE_TIMEOUT" default:"30s" yaml:"writeTimeout" toml:"writeTimeout"`
+
+	// The maximum duration to wait for the next request on keep-alive connections.
+	IdleTimeout time.Duration `env:"HTTP_IDLE_TIMEOUT" default:"120s" yaml:"idleTimeout" toml:"idleTimeout"`
+
+	// The maximum size of request bodies in bytes. Larger bodies are rejected with 413.
+	MaxBodySize int64 `env:"HTTP_MAX_BODY_SIZE" default:"1048576" yaml:"maxBodySize" toml:"maxBodySize"`
+
+	// The maximum duration for draining in-flight requests and running shutdown hooks.
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"20s" yaml:"shutdownTimeout" toml:"shutdownTimeout"`
+
+	// The duration between receiving a shutdown signal and draining connections.
+	// Readiness fails meanwhile, so that load balancers stop routing traffic to this instance.
+	DrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY" default:"0s" yaml:"drainDelay" toml:"drainDelay"`
+
+	// The maximum duration of a single health check, e.g. the database ping.
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" default:"2s" yaml:"healthCheckTimeout" toml:"healthCheckTimeout"`
+
+	// The address of the admin listener serving operational endpoints, e.g. 127.0.0.1:9872.
+	// If empty, operational endpoints are served by the service listeners.
+	AdminAddress string `env:"ADMIN_ADDRESS" yaml:"adminAddress" toml:"adminAddress"`
+
+	// The TLS configuration.
+	TLS TLSConfig `yaml:"tls" toml:"tls"`
+}
+
+// Description:
+//
+//	The TLS configuration of this service.
+//	TLS is enabled if a certificate file is configured.
+type TLSConfig struct {
+
+	// The path of the PEM encoded server certificate (chain).
+	CertFile string `env:"TLS_CERT_FILE" yaml:"certFile" toml:"certFile"`
+
+	// The path of the PEM encoded server private key.
+	KeyFile string `env:"TLS_KEY_FILE" yaml:"keyFile" toml:"keyFile"`
+
+	// The path of the PEM encoded certificate authorities used to verify client certificates.
+	// Enables mutual TLS if set.
+	ClientCAFile string `env:"TLS_CLIENT_CA_FILE" yaml:"clientCaFile" toml:"clientCaFile"`
+
+	// The interval in which the certificate files are checked for changes.
+	ReloadInterval time.Duration `env:"TLS_RELOAD_INTERVAL" default:"30s" yaml:"reloadInterval" toml:"reloadInterval"`
+}
+
+// Description:
+//
+//	The MongoDB configuration of this service.
+type MongoConfig struct {
+
+	// The MongoDB username.
+	Username string `env:"MONGO_USERNAME" required:"true" yaml:"username" toml:"username"`
+
+	// The MongoDB password.
+	Password string `env:"MONGO_PASSWORD" required:"true" secret:"true" yaml:"password" toml:"password"`
+
+	// The MongoDB host, including the port.
+	Host string `env:"MONGO_HOST" default:"127.0.0.1:27017" yaml:"host" toml:"host"`
+}
+
+// Description:
+//
+//	The compression configuration of this service.
+type CompressionConfig struct {
+
+	// Whether responses are compressed.
+	Enabled bool `env:"COMPRESSION_ENABLED" default:"true" yaml:"enabled" toml:"enabled"`
+
+	// The response encodings, in order of preference: zstd, gzip and deflate.
+	Encodings []string `env:"COMPRESSION_ENCODINGS" default:"zstd,gzip,deflate" yaml:"encodings" toml:"encodings"`
+
+	// The minimum size of a response body in bytes to be compressed.
+	MinSize int `env:"COMPRESSION_MIN_SIZE" default:"1024" yaml:"minSize" toml:"minSize"`
+
+	// The maximum size of a decompressed request body in bytes.
+	MaxDecompressedSize int64 `env:"MAX_DECOMPRESSED_BODY_SIZE" default:"10485760" yaml:"maxDecompressedSize" toml:"maxDecompressedSize"`
+}
+
+// Description:
+//
+//	The CORS configuration of this service.
+//	CORS is enabled if at least one origin is allowed.
+type CORSConfig struct {
+
+	// The allowed origins, e.g. https://editor.example.com or https://*.example.com.
+	AllowedOrigins []string `env:"CORS_ALLOWED_ORIGINS" yaml:"allowedOrigins" toml:"allowedOrigins"`
+
+	// The allowed methods. If empty, the methods registered for the requested path are allowed.
+	AllowedMethods []string `env:"CORS_ALLOWED_METHODS" yaml:"allowedMethods" toml:"allowedMethods"`
+
+	// The allowed request headers, or * for any header.
+	AllowedHeaders []string `env:"CORS_ALLOWED_HEADERS" default:"Accept,Authorization,Content-Type,Content-Encoding,X-Request-ID" yaml:"allowedHeaders" toml:"allowedHeaders"`
+
+	// The response headers exposed to browsers.
+	ExposedHeaders []string `env:"CORS_EXPOSED_HEADERS" default:"ETag,Location,X-Request-ID" yaml:"exposedHeaders" toml:"exposedHeaders"`
+
+	// Whether cross-origin requests may include credentials.
+	AllowCredentials bool `env:"CORS_ALLOW_CREDENTIALS" default:"false" yaml:"allowCredentials" toml:"allowCredentials"`
+
+	// The duration browsers may cache preflight responses.
+	MaxAge time.Duration `env:"CORS_MAX_AGE" default:"10m" yaml:"maxAge" toml:"maxAge"`
+}
+
+// Description:
+//
+//	The authentication configuration of this service.
+//	JWTs are verified with the keys of the JWKS file, the JWKS URL and the HMAC secret.
+//	Machine clients may authenticate with API keys instead.
+type AuthConfig struct {
+
+	// Whether writes require an authenticated principal.
+	Enabled bool `env:"AUTH_ENABLED" default:"true" yaml:"enabled" toml:"enabled"`
+
+	// The path of a local JWKS file.
+	JWKSFile string `env:"AUTH_JWKS_FILE" yaml:"jwksFile" toml:"jwksFile"`
+
+	// The URL of a JWKS document, e.g. of an identity provider.
+	JWKSURL string `env:"AUTH_JWKS_URL" yaml:"jwksUrl" toml:"jwksUrl"`
+
+	// The duration keys fetched from the JWKS URL are cached.
+	JWKSCacheTTL time.Duration `env:"AUTH_JWKS_CACHE_TTL" default:"10m" yaml:"jwksCacheTtl" toml:"jwksCacheTtl"`
+
+	// The secret of HS256 signed tokens.
+	HMACSecret string `env:"AUTH_HMAC_SECRET" secret:"true" yaml:"hmacSecret" toml:"hmacSecret"`
+
+	// The expected token issuer. Not checked if empty.
+	Issuer string `env:"AUTH_ISSUER" yaml:"issuer" toml:"issuer"`
+
+	// The expected token audience. Not checked if empty.
+	Audience string `env:"AUTH_AUDIENCE" yaml:"audience" toml:"audience"`
+
+	// The tolerated clock skew for the token expiry.
+	Leeway time.Duration `env:"AUTH_LEEWAY" default:"30s" yaml:"leeway" toml:"leeway"`
+
+	// The scope required to create, update and delete albums.
+	WriteScope string `env:"AUTH_WRITE_SCOPE" default:"albums:write" yaml:"writeScope" toml:"writeScope"`
+
+	// The header carrying the API keys of machine clients.
+	APIKeyHeader string `env:"AUTH_API_KEY_HEADER" default:"X-API-Key" yaml:"apiKeyHeader" toml:"apiKeyHeader"`
+
+	// The role required to manage API keys, which may also modify every album.
+	AdminRole string `env:"AUTH_ADMIN_ROLE" default:"admin" yaml:"adminRole" toml:"adminRole"`
+
+	// The role of callers, which may only see albums they or their label own.
+	RestrictedRole string `env:"AUTH_RESTRICTED_ROLE" default:"restricted" yaml:"restrictedRole" toml:"restrictedRole"`
+
+	// The token claim holding the label of a principal.
+	LabelClaim string `env:"AUTH_LABEL_CLAIM" default:"label" yaml:"labelClaim" toml:"labelClaim"`
+}
+
+// Description:
+//
+//	The rate limiting configuration of this service.
+//	Clients are identified by authenticated subject or API key, falling back to their IP.
+type RateLimitConfig struct {
+
+	// Whether requests to the album routes are rate limited.
+	Enabled bool `env:"RATE_LIMIT_ENABLED" default:"true" yaml:"enabled" toml:"enabled"`
+
+	// The number of read requests per period and client.
+	ReadRequests int `env:"RATE_LIMIT_READ_REQUESTS" default:"600" yaml:"readRequests" toml:"readRequests"`
+
+	// The number of write requests per period and client.
+	WriteRequests int `env:"RATE_LIMIT_WRITE_REQUESTS" default:"60" yaml:"writeRequests" toml:"writeRequests"`
+
+	// The period in which the budgets are refilled.
+	Period time.Duration `env:"RATE_LIMIT_PERIOD" default:"1m" yaml:"period" toml:"period"`
+
+	// The header carrying the API key of a client.
+	KeyHeader string `env:"RATE_LIMIT_KEY_HEADER" default:"X-API-Key" yaml:"keyHeader" toml:"keyHeader"`
+
+	// The number of requests per period and client IP, limited before authentication.
+	IPRequests int `env:"RATE_LIMIT_IP_REQUESTS" default:"1200" yaml:"ipRequests" toml:"ipRequests"`
+}
+
+// Description:
+//
+//	The metrics configuration of this service.
+type MetricsConfig struct {
+
+	// Whether metrics are exposed in the Prometheus text format.
+	Enabled bool `env:"METRICS_ENABLED" default:"true" yaml:"enabled" toml:"enabled"`
+
+	// The path metrics are exposed on.
+	Path string `env:"METRICS_PATH" default:"/metrics" yaml:"path" toml:"path"`
+}
+
+// Description:
+//
+//	The OpenAPI configuration of this service.
+type OpenAPIConfig struct {
+
+	// Whether the OpenAPI specification and its documentation page are served.
+	Enabled bool `env:"OPENAPI_ENABLED" default:"true" yaml:"enabled" toml:"enabled"`
+
+	// The path the OpenAPI specification is served on.
+	Path string `env:"OPENAPI_PATH" default:"/openapi.json" yaml:"path" toml:"path"`
+
+	// The path the documentation page is served on.
+	DocsPath string `env:"OPENAPI_DOCS_PATH" default:"/docs" yaml:"docsPath" toml:"docsPath"`
+}
+
+// Description:
+//
+//	The tracing configuration of this service.
+type TracingConfig struct {
+
+	// The span exporter: none, stdout or otlp.
+	Exporter string `env:"TRACING_EXPORTER" default:"none" yaml:"exporter" toml:"exporter"`
+
+	// The service name attached to all spans.
+	ServiceName string `env:"TRACING_SERVICE_NAME" default:"albums" yaml:"serviceName" toml:"serviceName"`
+
+	// The OTLP/HTTP collector endpoint, host and port.
+	Endpoint string `env:"TRACING_OTLP_ENDPOINT" default:"localhost:4318" yaml:"endpoint" toml:"endpoint"`
+
+	// Whether the OTLP collector is reached via plain HTTP.
+	Insecure bool `env:"TRACING_OTLP_INSECURE" default:"false" yaml:"insecure" toml:"insecure"`
+
+	// The ratio of sampled root spans, between 0 and 1.
+	SampleRatio float64 `env:"TRACING_SAMPLE_RATIO" default:"1" yaml:"sampleRatio" toml:"sampleRatio"`
+}
+
+// Description:
+//
+//	Loads the service configuration.
+//	The path of the optional configuration file is read from the CONFIG_FILE environment variable.
+//
+// Returns:
+//
+//	The loaded configuration, or an error if loading fails.
+func Load() (*Config, error) {
+	config := &Config{}
+
+	options := env.LoadOptions{
+		FilePath: env.GetEnvironmentVariableWithFallback("CONFIG_FILE", ""),
+	}
+
+	err := env.Load(config, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Description:
+//
+//	Gets the default configuration, ignoring configuration files and the environment.
+//	Used by tools which inspect the service without running it, e.g. the OpenAPI generator.
+//
+// Returns:
+//
+//	The default configuration, or an error if a default value is invalid.
+func Defaults() (*Config, error) {
+	config := &Config{}
+
+	err := env.Defaults(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -1,22 +1,24 @@
 package createalbum
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/gostream-official/albums/impl/inject"
 	"github.com/gostream-official/albums/impl/models"
+	"github.com/gostream-official/albums/impl/policy"
 	"github.com/gostream-official/albums/pkg/api"
 	"github.com/gostream-official/albums/pkg/arrays"
-	"github.com/gostream-official/albums/pkg/marshal"
-	"github.com/gostream-official/albums/pkg/parallel"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/router"
 	"github.com/gostream-official/albums/pkg/store"
 	"github.com/gostream-official/albums/pkg/store/query"
-	"github.com/revx-official/output/log"
+	"github.com/gostream-official/albums/pkg/tracing"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Description:
@@ -25,13 +27,16 @@ import (
 type CreateAlbumRequestBody struct {
 
 	// The title of the album.
-	Title string `json:"title"`
+	Title string `json:"title" xml:"title"`
 
 	// The tracks contained in the album.
-	TrackIDs []string `json:"trackIds"`
+	TrackIDs []string `json:"trackIds" xml:"trackIds"`
 
 	// Some album statistics.
-	Stats CreateAlbumStatsRequestBody `json:"stats"`
+	Stats CreateAlbumStatsRequestBody `json:"stats" xml:"stats"`
+
+	// The label releasing the album. Defaults to the label of the caller.
+	Label string `json:"label,omitempty" xml:"label,omitempty"`
 }
 
 // Description:
@@ -40,7 +45,7 @@ type CreateAlbumRequestBody struct {
 type CreateAlbumStatsRequestBody struct {
 
 	// The popularity factor.
-	Popularity float32 `json:"popularity"`
+	Popularity float32 `json:"popularity" xml:"popularity"`
 }
 
 // Description:
@@ -49,7 +54,7 @@ type CreateAlbumStatsRequestBody struct {
 type CreateAlbumErrorResponseBody struct {
 
 	// The error message.
-	Message string `json:"message"`
+	Message string `json:"message" xml:"message"`
 }
 
 // Description:
@@ -58,32 +63,10 @@ type CreateAlbumErrorResponseBody struct {
 type CreateAlbumValidationError struct {
 
 	// The JSON field which is referenced by the error message.
-	FieldRef string `json:"ref"`
+	FieldRef string `json:"ref" xml:"ref"`
 
 	// The error message.
-	ErrorMessage string `json:"error"`
-}
-
-// Description:
-//
-//	Attempts to cast the input object to the endpoint injector.
-//	If this cast fails, we cannot proceed to process this request.
-//
-// Parameters:
-//
-//	object 	The injector object.
-//
-// Returns:
-//
-//	The injector if the cast is successful, an error otherwise.
-func GetSafeInjector(object interface{}) (*inject.Injector, error) {
-	injector, ok := object.(inject.Injector)
-
-	if !ok {
-		return nil, fmt.Errorf("createalbum: failed to deduce injector")
-	}
-
-	return &injector, nil
+	ErrorMessage string `json:"error" xml:"error"`
 }
 
 // Description:
@@ -100,9 +83,7 @@ func GetSafeInjector(object interface{}) (*inject.Injector, error) {
 func ExtractRequestBody(request *api.APIRequest) (*CreateAlbumRequestBody, error) {
 	body := &CreateAlbumRequestBody{}
 
-	bytes := []byte(request.Body)
-	err := json.Unmarshal(bytes, body)
-
+	err := request.Decode(body)
 	if err != nil {
 		return nil, err
 	}
@@ -168,6 +149,7 @@ func ValidateAlbumStatsRequestBody(request *CreateAlbumStatsRequestBody) *Create
 //
 // Parameters:
 //
+//	ctx 	The request context.
 //	store 	The mongo store to search.
 //	trackID The artist id to search.
 //
@@ -175,7 +157,7 @@ func ValidateAlbumStatsRequestBody(request *CreateAlbumStatsRequestBody) *Create
 //
 //	An error, if the artist could not be found or an error,
 //	if the database request failed, nothing if successful.
-func CheckIfTrackExists(store *store.MongoStore[models.TrackInfo], trackID string) error {
+func CheckIfTrackExists(ctx context.Context, store store.Store[models.TrackInfo], trackID string) error {
 	filter := query.Filter{
 		Root: query.FilterOperatorEq{
 			Key:   "_id",
@@ -184,7 +166,7 @@ func CheckIfTrackExists(store *store.MongoStore[models.TrackInfo], trackID strin
 		Limit: 1,
 	}
 
-	items, err := store.FindItems(&filter)
+	items, err := store.FindItems(ctx, &filter)
 	if err != nil {
 		return err
 	}
@@ -196,35 +178,43 @@ func CheckIfTrackExists(store *store.MongoStore[models.TrackInfo], trackID strin
 	return nil
 }
 
+// Description:
+//
+//	The documentation of the route, used to generate the OpenAPI specification.
+var Doc = router.RouteDoc{
+	OperationID: "createalbum",
+	Summary:     "Create an album",
+	Description: "The album is owned by the caller. Only administrators may create albums for another label.",
+	Tags:        []string{"albums"},
+	Request:     CreateAlbumRequestBody{},
+	Responses: []router.ResponseDoc{
+		{StatusCode: http.StatusOK, Description: "The created album.", Body: models.AlbumInfo{}},
+		{StatusCode: http.StatusBadRequest, Description: "Invalid request body.", Body: CreateAlbumValidationError{}},
+		{StatusCode: http.StatusForbidden, Description: "Missing scope, or the caller may not assign the label.", Body: api.ErrorResponseBody{}},
+	},
+}
+
 // Description:
 //
 //	The router handler for track creation.
 //
 // Parameters:
 //
-//	request The incoming request.
-//	object 	The injector. Contains injected dependencies.
+//	request 	The incoming request.
+//	injector 	The injector. Contains injected dependencies.
 //
 // Returns:
 //
 //	An API response object.
-func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
-	context := parallel.NewContext()
-
-	log.Infof("[%s] %s: %s", context.ID, request.Method, request.Path)
-	log.Tracef("[%s] request: %s", context.ID, marshal.Quick(request))
-
-	injector, err := GetSafeInjector(object)
-	if err != nil {
-		log.Warnf("[%s] failed to get endpoint injector: %s", context.ID, err)
-		return &api.APIResponse{
-			StatusCode: http.StatusInternalServerError,
-		}
-	}
+func Handler(request *api.APIRequest, injector *inject.Injector) *api.APIResponse {
+	request.Logger.Infof("%s: %s", request.Method, request.Path)
 
+	_, decodeSpan := tracing.Start(request.Context, "createalbum.decode")
 	requestBody, err := ExtractRequestBody(request)
+	tracing.End(decodeSpan, err)
+
 	if err != nil {
-		log.Warnf("[%s] failed to extract request body: %s", context.ID, err)
+		request.Logger.Warnf("failed to extract request body: %s", err)
 		return &api.APIResponse{
 			StatusCode: http.StatusBadRequest,
 			Body: CreateAlbumErrorResponseBody{
@@ -235,20 +225,39 @@ func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
 
 	validationError := ValidateRequestBody(requestBody)
 	if validationError != nil {
-		log.Warnf("[%s] failed request body validation: %s", context.ID, validationError.ErrorMessage)
+		request.Logger.Warnf("failed request body validation: %s", validationError.ErrorMessage)
 		return &api.APIResponse{
 			StatusCode: http.StatusBadRequest,
 			Body:       validationError,
 		}
 	}
 
-	trackStore := store.NewMongoStore[models.TrackInfo](injector.MongoInstance, "gostream", "tracks")
-	albumStore := store.NewMongoStore[models.AlbumInfo](injector.MongoInstance, "gostream", "albums")
+	label := strings.TrimSpace(requestBody.Label)
+	if label == "" {
+		label = injector.Policy.Label(request.Principal)
+	}
+
+	err = injector.Policy.CanAssign(request.Principal, label)
+	if err != nil {
+		request.Logger.Warnf("denied album creation: %s", err)
+		return policy.Forbidden(request, err)
+	}
+
+	owner := ""
+	if request.Principal != nil {
+		owner = request.Principal.Subject
+	}
+
+	trackStore := injector.Tracks
+	albumStore := injector.Albums
+
+	tracksContext, tracksSpan := tracing.Start(request.Context, "createalbum.checkTracks", attribute.Int("album.tracks", len(requestBody.TrackIDs)))
 
 	for _, trackID := range requestBody.TrackIDs {
-		err = CheckIfTrackExists(trackStore, trackID)
+		err = CheckIfTrackExists(tracksContext, trackStore, trackID)
 		if err != nil {
-			log.Warnf("[%s] track does not exist: %s", context.ID, err)
+			tracing.End(tracksSpan, err)
+			request.Logger.Warnf("track does not exist: %s", err)
 			return &api.APIResponse{
 				StatusCode: http.StatusBadRequest,
 				Body: CreateAlbumErrorResponseBody{
@@ -258,26 +267,37 @@ func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
 		}
 	}
 
+	tracing.End(tracksSpan, nil)
+
 	albumInfo := models.AlbumInfo{
-		ID:       uuid.New().String(),
+		ID:       injector.IDs.NewID(),
 		Title:    requestBody.Title,
 		TrackIDs: requestBody.TrackIDs,
 		Stats: models.AlbumStats{
 			Popularity: requestBody.Stats.Popularity,
 		},
+		Owner: owner,
+		Label: label,
 	}
 
-	log.Tracef("[%s] attempting to create database item ...", context.ID)
-	err = albumStore.CreateItem(albumInfo)
+	logger := request.Logger.With(logging.F("albumId", albumInfo.ID))
+
+	logger.Tracef("attempting to create database item ...")
+	err = albumStore.CreateItem(request.Context, albumInfo)
 
 	if err != nil {
-		log.Errorf("[%s] failed to create database item: %s", context.ID, err)
+		logger.Errorf("failed to create database item: %s", err)
 		return &api.APIResponse{
 			StatusCode: http.StatusInternalServerError,
 		}
 	}
 
-	log.Tracef("[%s] successfully completed request", context.ID)
+	err = injector.Publisher.Publish(request.Context, models.AlbumCreatedTopic, albumInfo)
+	if err != nil {
+		logger.Warnf("failed to publish album creation: %s", err)
+	}
+
+	logger.Tracef("successfully completed request")
 	return &api.APIResponse{
 		StatusCode: http.StatusOK,
 		Body:       albumInfo,
@@ -0,0 +1,155 @@
+package createalbum
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/impl/models"
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/ids"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/store/storetest"
+)
+
+// Description:
+//
+//	A published event.
+type event struct {
+
+	// The topic of the event.
+	topic string
+
+	// The event payload.
+	payload interface{}
+}
+
+// Description:
+//
+//	A publisher recording the published events.
+type recordingPublisher struct {
+
+	// Guards the events.
+	mutex sync.Mutex
+
+	// The published events.
+	events []event
+
+	// The error returned by Publish, if set.
+	err error
+}
+
+// Description:
+//
+//	Records the event.
+//
+// Parameters:
+//
+//	ctx 	The request context.
+//	topic 	The topic of the event.
+//	payload The event payload.
+//
+// Returns:
+//
+//	The configured error.
+func (publisher *recordingPublisher) Publish(ctx context.Context, topic string, payload interface{}) error {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	publisher.events = append(publisher.events, event{topic: topic, payload: payload})
+	return publisher.err
+}
+
+// Description:
+//
+//	Creates a request creating an album with the given JSON body.
+//
+// Parameters:
+//
+//	body The request body.
+//
+// Returns:
+//
+//	The request.
+func newRequest(body string) *api.APIRequest {
+	return &api.APIRequest{
+		Method:  http.MethodPost,
+		Path:    "/albums",
+		Body:    body,
+		Context: context.Background(),
+		Logger:  logging.Named("test"),
+	}
+}
+
+func TestHandlerPublishesCreatedAlbum(t *testing.T) {
+	albums := storetest.NewMemoryStore[models.AlbumInfo]()
+	tracks := storetest.NewMemoryStore(models.TrackInfo{ID: "6a7c1a3e-2d3b-4c1f-9a52-3f0b8e1d2c4a"})
+	publisher := &recordingPublisher{}
+
+	injector := inject.New(nil,
+		inject.WithAlbumStore(albums),
+		inject.WithTrackStore(tracks),
+		inject.WithIDGenerator(ids.Sequence("album")),
+		inject.WithPublisher(publisher),
+	)
+
+	response := Handler(newRequest(`{"title":"Album","trackIds":["6a7c1a3e-2d3b-4c1f-9a52-3f0b8e1d2c4a"]}`), injector)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", response.StatusCode)
+	}
+
+	stored := albums.Items()
+	if len(stored) != 1 || stored[0].ID != "album" {
+		t.Fatalf("expected the album to be stored with the generated id, got %+v", stored)
+	}
+
+	if len(publisher.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(publisher.events))
+	}
+
+	published := publisher.events[0]
+	if published.topic != models.AlbumCreatedTopic {
+		t.Fatalf("expected topic %s, got %s", models.AlbumCreatedTopic, published.topic)
+	}
+
+	if album, ok := published.payload.(models.AlbumInfo); !ok || album.ID != "album" {
+		t.Fatalf("expected the created album as payload, got %+v", published.payload)
+	}
+}
+
+func TestHandlerIgnoresPublishFailures(t *testing.T) {
+	publisher := &recordingPublisher{err: errors.New("broker unavailable")}
+
+	injector := inject.New(nil,
+		inject.WithAlbumStore(storetest.NewMemoryStore[models.AlbumInfo]()),
+		inject.WithTrackStore(storetest.NewMemoryStore[models.TrackInfo]()),
+		inject.WithPublisher(publisher),
+	)
+
+	response := Handler(newRequest(`{"title":"Album"}`), injector)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 despite the failing publisher, got %d", response.StatusCode)
+	}
+}
+
+func TestHandlerDoesNotPublishInvalidAlbums(t *testing.T) {
+	publisher := &recordingPublisher{}
+
+	injector := inject.New(nil,
+		inject.WithAlbumStore(storetest.NewMemoryStore[models.AlbumInfo]()),
+		inject.WithTrackStore(storetest.NewMemoryStore[models.TrackInfo]()),
+		inject.WithPublisher(publisher),
+	)
+
+	response := Handler(newRequest(`{"title":" "}`), injector)
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", response.StatusCode)
+	}
+
+	if len(publisher.events) != 0 {
+		t.Fatalf("expected no events, got %d", len(publisher.events))
+	}
+}
@@ -0,0 +1,197 @@
+package createapikey
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gostream-official/albums/impl/apikeys"
+	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/router"
+)
+
+// Description:
+//
+//	The request body for the create API key endpoint.
+type CreateAPIKeyRequestBody struct {
+
+	// The display name of the key.
+	Name string `json:"name" xml:"name"`
+
+	// The subject the key acts on behalf of. Defaults to the subject of the caller.
+	Owner string `json:"owner" xml:"owner"`
+
+	// The scopes granted to the key.
+	Scopes []string `json:"scopes" xml:"scopes"`
+
+	// The expiry time of the key. The key does not expire if omitted.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" xml:"expiresAt,omitempty"`
+}
+
+// Description:
+//
+//	Describes a validation error.
+type CreateAPIKeyValidationError struct {
+
+	// The JSON field which is referenced by the error message.
+	FieldRef string `json:"ref" xml:"ref"`
+
+	// The error message.
+	ErrorMessage string `json:"error" xml:"error"`
+}
+
+// Description:
+//
+//	Unmarshals the request body for this endpoint.
+//
+// Parameters:
+//
+//	request The original request.
+//
+// Returns:
+//
+//	The unmarshalled request body, or an error when unmarshalling fails.
+func ExtractRequestBody(request *api.APIRequest) (*CreateAPIKeyRequestBody, error) {
+	body := &CreateAPIKeyRequestBody{}
+
+	err := request.Decode(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// Description:
+//
+//	Validates the request body for this endpoint.
+//
+// Parameters:
+//
+//	request The request body.
+//	now 	The current time.
+//
+// Returns:
+//
+//	An error if the validation fails.
+func ValidateRequestBody(request *CreateAPIKeyRequestBody, now time.Time) *CreateAPIKeyValidationError {
+	if len(strings.TrimSpace(request.Name)) == 0 {
+		return &CreateAPIKeyValidationError{
+			FieldRef:     "name",
+			ErrorMessage: "value must not be empty",
+		}
+	}
+
+	for _, scope := range request.Scopes {
+		if len(strings.TrimSpace(scope)) == 0 || strings.ContainsAny(scope, " \t") {
+			return &CreateAPIKeyValidationError{
+				FieldRef:     "scopes",
+				ErrorMessage: "array value must be a non-empty scope without whitespace",
+			}
+		}
+	}
+
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(now) {
+		return &CreateAPIKeyValidationError{
+			FieldRef:     "expiresAt",
+			ErrorMessage: "value must be in the future",
+		}
+	}
+
+	return nil
+}
+
+// Description:
+//
+//	The documentation of the route, used to generate the OpenAPI specification.
+var Doc = router.RouteDoc{
+	OperationID: "createapikey",
+	Summary:     "Create an API key",
+	Description: "Requires the admin role. The plaintext key is only contained in this response.",
+	Tags:        []string{"apikeys"},
+	Request:     CreateAPIKeyRequestBody{},
+	Responses: []router.ResponseDoc{
+		{StatusCode: http.StatusCreated, Description: "The created key.", Body: apikeys.Issued{}},
+		{StatusCode: http.StatusBadRequest, Description: "Invalid request body.", Body: CreateAPIKeyValidationError{}},
+		{StatusCode: http.StatusForbidden, Description: "Missing admin role.", Body: api.ErrorResponseBody{}},
+	},
+}
+
+// Description:
+//
+//	The router handler for API key creation.
+//	The plaintext key is only contained in this response.
+//
+// Parameters:
+//
+//	request 	The incoming request.
+//	injector 	The injector. Contains injected dependencies.
+//
+// Returns:
+//
+//	An API response object.
+func Handler(request *api.APIRequest, injector *inject.Injector) *api.APIResponse {
+	request.Logger.Infof("%s: %s", request.Method, request.Path)
+
+	requestBody, err := ExtractRequestBody(request)
+	if err != nil {
+		request.Logger.Warnf("failed to extract request body: %s", err)
+		return &api.APIResponse{
+			StatusCode: http.StatusBadRequest,
+			Body: api.ErrorResponseBody{
+				Message:   "invalid request body",
+				RequestID: request.RequestID,
+			},
+		}
+	}
+
+	validationError := ValidateRequestBody(requestBody, injector.Clock.Now())
+	if validationError != nil {
+		request.Logger.Warnf("failed request body validation: %s", validationError.ErrorMessage)
+		return &api.APIResponse{
+			StatusCode: http.StatusBadRequest,
+			Body:       validationError,
+		}
+	}
+
+	owner := strings.TrimSpace(requestBody.Owner)
+	if owner == "" && request.Principal != nil {
+		owner = request.Principal.Subject
+	}
+
+	scopes := requestBody.Scopes
+	if scopes == nil {
+		scopes = make([]string, 0)
+	}
+
+	issued, err := apikeys.Issue(injector, strings.TrimSpace(requestBody.Name), owner, scopes, requestBody.ExpiresAt)
+	if err != nil {
+		request.Logger.Errorf("failed to generate api key: %s", err)
+		return &api.APIResponse{
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	logger := request.Logger.With(logging.F("keyId", issued.ID))
+
+	logger.Tracef("attempting to create database item ...")
+	err = injector.APIKeys.CreateItem(request.Context, issued.APIKeyInfo)
+
+	if err != nil {
+		logger.Errorf("failed to create database item: %s", err)
+		return &api.APIResponse{
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	logger.Infof("created api key for owner %s", issued.Owner)
+	return &api.APIResponse{
+		StatusCode: http.StatusCreated,
+		Headers: map[string]string{
+			"Cache-Control": "no-store",
+		},
+		Body: issued,
+	}
+}
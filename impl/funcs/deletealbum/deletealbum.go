@@ -1,38 +1,70 @@
 package deletealbum
 
 import (
-	"fmt"
+	"context"
 	"net/http"
 
 	"github.com/gostream-official/albums/impl/inject"
 	"github.com/gostream-official/albums/impl/models"
+	"github.com/gostream-official/albums/impl/policy"
 	"github.com/gostream-official/albums/pkg/api"
-	"github.com/gostream-official/albums/pkg/marshal"
-	"github.com/gostream-official/albums/pkg/parallel"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/router"
 	"github.com/gostream-official/albums/pkg/store"
-	"github.com/revx-official/output/log"
+	"github.com/gostream-official/albums/pkg/store/query"
 )
 
 // Description:
 //
-//	Attempts to cast the input object to the endpoint injector.
-//	If this cast fails, we cannot proceed to process this request.
+//	Searches an album with the given id in the database.
 //
 // Parameters:
 //
-//	object 	The injector object.
+//	ctx 	The request context.
+//	store 	The store to search through.
+//	id 		The id to search for.
 //
 // Returns:
 //
-//	The injector if the cast is successful, an error otherwise.
-func GetSafeInjector(object interface{}) (*inject.Injector, error) {
-	injector, ok := object.(inject.Injector)
+//	The first matched album, or nil if no album matches.
+//	An error if the query fails.
+func FindAlbumByID(ctx context.Context, store store.Store[models.AlbumInfo], id string) (*models.AlbumInfo, error) {
+	filter := query.Filter{
+		Root: query.FilterOperatorEq{
+			Key:   "_id",
+			Value: id,
+		},
+		Limit: 1,
+	}
+
+	items, err := store.FindItems(ctx, &filter)
+	if err != nil {
+		return nil, err
+	}
 
-	if !ok {
-		return nil, fmt.Errorf("deletealbum: failed to deduce injector")
+	if len(items) == 0 {
+		return nil, nil
 	}
 
-	return &injector, nil
+	return &items[0], nil
+}
+
+// Description:
+//
+//	The documentation of the route, used to generate the OpenAPI specification.
+var Doc = router.RouteDoc{
+	OperationID: "deletealbum",
+	Summary:     "Delete an album",
+	Description: "Albums may be deleted by their owner, principals of their label and administrators.",
+	Tags:        []string{"albums"},
+	Path: []router.ParameterDoc{
+		{Name: "id", Description: "The id of the album.", Format: "uuid"},
+	},
+	Responses: []router.ResponseDoc{
+		{StatusCode: http.StatusAccepted, Description: "The album was deleted."},
+		{StatusCode: http.StatusNoContent, Description: "The album does not exist."},
+		{StatusCode: http.StatusForbidden, Description: "Missing scope, or the album is owned by another principal.", Body: api.ErrorResponseBody{}},
+	},
 }
 
 // Description:
@@ -41,33 +73,45 @@ func GetSafeInjector(object interface{}) (*inject.Injector, error) {
 //
 // Parameters:
 //
-//	request The incoming request.
-//	object 	The injector. Contains injected dependencies.
+//	request 	The incoming request.
+//	injector 	The injector. Contains injected dependencies.
 //
 // Returns:
 //
 //	An API response object.
-func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
-	context := parallel.NewContext()
+func Handler(request *api.APIRequest, injector *inject.Injector) *api.APIResponse {
+	logger := request.Logger.With(logging.F("albumId", request.PathParameters["id"]))
+
+	logger.Infof("%s: %s", request.Method, request.Path)
+
+	idToDelete := request.PathParameters["id"]
 
-	log.Infof("[%s] %s: %s", context.ID, request.Method, request.Path)
-	log.Tracef("[%s] request: %s", context.ID, marshal.Quick(request))
+	store := injector.Albums
 
-	injector, err := GetSafeInjector(object)
+	albumInfo, err := FindAlbumByID(request.Context, store, idToDelete)
 	if err != nil {
-		log.Errorf("[%s] failed to get endpoint injector: %s", context.ID, err)
+		logger.Errorf("failed to retrieve database item: %s", err)
 		return &api.APIResponse{
 			StatusCode: http.StatusInternalServerError,
 		}
 	}
 
-	idToDelete := request.PathParameters["id"]
+	if albumInfo == nil {
+		return &api.APIResponse{
+			StatusCode: http.StatusNoContent,
+		}
+	}
 
-	store := store.NewMongoStore[models.TrackInfo](injector.MongoInstance, "gostream", "albums")
-	count, err := store.DeleteItem(idToDelete)
+	err = injector.Policy.CanModify(request.Principal, albumInfo)
+	if err != nil {
+		logger.Warnf("denied album deletion: %s", err)
+		return policy.Forbidden(request, err)
+	}
+
+	count, err := store.DeleteItem(request.Context, idToDelete)
 
 	if err != nil {
-		log.Errorf("[%s] failed to delete database items: %s", context.ID, err)
+		logger.Errorf("failed to delete database items: %s", err)
 		return &api.APIResponse{
 			StatusCode: http.StatusInternalServerError,
 		}
@@ -79,6 +123,11 @@ func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
 		}
 	}
 
+	err = injector.Publisher.Publish(request.Context, models.AlbumDeletedTopic, *albumInfo)
+	if err != nil {
+		logger.Warnf("failed to publish album deletion: %s", err)
+	}
+
 	return &api.APIResponse{
 		StatusCode: http.StatusAccepted,
 	}
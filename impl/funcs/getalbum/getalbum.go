@@ -1,39 +1,30 @@
 package getalbum
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/gostream-official/albums/impl/inject"
 	"github.com/gostream-official/albums/impl/models"
 	"github.com/gostream-official/albums/pkg/api"
-	"github.com/gostream-official/albums/pkg/marshal"
-	"github.com/gostream-official/albums/pkg/parallel"
-	"github.com/gostream-official/albums/pkg/store"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/router"
 	"github.com/gostream-official/albums/pkg/store/query"
-	"github.com/revx-official/output/log"
 )
 
 // Description:
 //
-//	Attempts to cast the input object to the endpoint injector.
-//	If this cast fails, we cannot proceed to process this request.
-//
-// Parameters:
-//
-//	object 	The injector object.
-//
-// Returns:
-//
-//	The injector if the cast is successful, an error otherwise.
-func GetSafeInjector(object interface{}) (*inject.Injector, error) {
-	injector, ok := object.(inject.Injector)
-
-	if !ok {
-		return nil, fmt.Errorf("getalbum: failed to deduce injector")
-	}
-
-	return &injector, nil
+//	The documentation of the route, used to generate the OpenAPI specification.
+var Doc = router.RouteDoc{
+	OperationID: "getalbum",
+	Summary:     "Get an album",
+	Tags:        []string{"albums"},
+	Path: []router.ParameterDoc{
+		{Name: "id", Description: "The id of the album.", Format: "uuid"},
+	},
+	Responses: []router.ResponseDoc{
+		{StatusCode: http.StatusOK, Description: "The album.", Body: models.AlbumInfo{}},
+		{StatusCode: http.StatusNotFound, Description: "The album does not exist or is not visible to the caller."},
+	},
 }
 
 // Description:
@@ -42,40 +33,32 @@ func GetSafeInjector(object interface{}) (*inject.Injector, error) {
 //
 // Parameters:
 //
-//	request The incoming request.
-//	object 	The injector. Contains injected dependencies.
+//	request 	The incoming request.
+//	injector 	The injector. Contains injected dependencies.
 //
 // Returns:
 //
 //	An API response object.
-func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
-	context := parallel.NewContext()
-
-	log.Infof("[%s] %s: %s", context.ID, request.Method, request.Path)
-	log.Tracef("[%s] request: %s", context.ID, marshal.Quick(request))
+func Handler(request *api.APIRequest, injector *inject.Injector) *api.APIResponse {
+	logger := request.Logger.With(logging.F("albumId", request.PathParameters["id"]))
 
-	injector, err := GetSafeInjector(object)
-	if err != nil {
-		log.Errorf("[%s] failed to get endpoint injector: %s", context.ID, err)
-		return &api.APIResponse{
-			StatusCode: http.StatusInternalServerError,
-		}
-	}
+	logger.Infof("%s: %s", request.Method, request.Path)
 
-	store := store.NewMongoStore[models.AlbumInfo](injector.MongoInstance, "gostream", "albums")
+	store := injector.Albums
 
+	// Albums invisible to restricted callers are not found.
 	filter := query.Filter{
-		Root: query.FilterOperatorEq{
+		Root: injector.Policy.Scope(request.Principal, query.FilterOperatorEq{
 			Key:   "_id",
 			Value: request.PathParameters["id"],
-		},
+		}),
 		Limit: 10,
 	}
 
-	items, err := store.FindItems(&filter)
+	items, err := store.FindItems(request.Context, &filter)
 
 	if err != nil {
-		log.Errorf("[%s] failed to retrieve database items: %s", context.ID, err)
+		logger.Errorf("failed to retrieve database items: %s", err)
 		return &api.APIResponse{
 			StatusCode: http.StatusInternalServerError,
 		}
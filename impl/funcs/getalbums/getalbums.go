@@ -1,45 +1,19 @@
 package getalbums
 
 import (
-	"fmt"
 	"net/http"
-	"strconv"
 
 	"github.com/gostream-official/albums/impl/inject"
 	"github.com/gostream-official/albums/impl/models"
 	"github.com/gostream-official/albums/pkg/api"
-	"github.com/gostream-official/albums/pkg/marshal"
-	"github.com/gostream-official/albums/pkg/parallel"
-	"github.com/gostream-official/albums/pkg/store"
+	"github.com/gostream-official/albums/pkg/router"
 	"github.com/gostream-official/albums/pkg/store/query"
-	"github.com/revx-official/output/log"
 )
 
-// Description:
-//
-//	Attempts to cast the input object to the endpoint injector.
-//	If this cast fails, we cannot proceed to process this request.
-//
-// Parameters:
-//
-//	object 	The injector object.
-//
-// Returns:
-//
-//	The injector if the cast is successful, an error otherwise.
-func GetSafeInjector(object interface{}) (*inject.Injector, error) {
-	injector, ok := object.(inject.Injector)
-
-	if !ok {
-		return nil, fmt.Errorf("getalbums: failed to deduce injector")
-	}
-
-	return &injector, nil
-}
-
 // Description:
 //
 //	Creates a query filter from the incoming API request.
+//	An invalid limit is ignored.
 //
 // Parameters:
 //
@@ -53,18 +27,11 @@ func CreateFilterFromQueryParameters(request *api.APIRequest) query.Filter {
 		And: make([]query.IQuery, 0),
 	}
 
-	var realLimit int
-	var realLimitErr error
-
-	limit, limitOk := request.QueryParameters["limit"]
-	if limitOk {
-		realLimit, realLimitErr = strconv.Atoi(limit)
-	}
-
 	resultFilter := query.Filter{}
 
-	if limitOk && realLimitErr == nil {
-		resultFilter.Limit = uint32(realLimit)
+	limit, err := request.QueryInt("limit", 0)
+	if err == nil && limit > 0 {
+		resultFilter.Limit = uint32(limit)
 	}
 
 	if len(andFilter.And) > 0 {
@@ -74,39 +41,47 @@ func CreateFilterFromQueryParameters(request *api.APIRequest) query.Filter {
 	return resultFilter
 }
 
+// Description:
+//
+//	The documentation of the route, used to generate the OpenAPI specification.
+var Doc = router.RouteDoc{
+	OperationID: "getalbums",
+	Summary:     "List albums",
+	Description: "Restricted callers only see the albums they or their label own.",
+	Tags:        []string{"albums"},
+	Query: []router.ParameterDoc{
+		{Name: "limit", Description: "The maximum number of albums. An invalid limit is ignored.", Type: 0},
+	},
+	Responses: []router.ResponseDoc{
+		{StatusCode: http.StatusOK, Description: "The albums.", Body: []models.AlbumInfo{}},
+	},
+}
+
 // Description:
 //
 //	The router handler for: Get Track By ID
+//	Restricted callers only see the albums they or their label own.
 //
 // Parameters:
 //
-//	request The incoming request.
-//	object 	The injector. Contains injected dependencies.
+//	request 	The incoming request.
+//	injector 	The injector. Contains injected dependencies.
 //
 // Returns:
 //
 //	An API response object.
-func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
-	context := parallel.NewContext()
-
-	log.Infof("[%s] %s: %s", context.ID, request.Method, request.Path)
-	log.Tracef("[%s] request: %s", context.ID, marshal.Quick(request))
+func Handler(request *api.APIRequest, injector *inject.Injector) *api.APIResponse {
+	request.Logger.Infof("%s: %s", request.Method, request.Path)
 
-	injector, err := GetSafeInjector(object)
-	if err != nil {
-		log.Errorf("[%s] failed to get endpoint injector: %s", context.ID, err)
-		return &api.APIResponse{
-			StatusCode: http.StatusInternalServerError,
-		}
-	}
-
-	store := store.NewMongoStore[models.AlbumInfo](injector.MongoInstance, "gostream", "albums")
+	store := injector.Albums
 	filter := CreateFilterFromQueryParameters(request)
 
-	items, err := store.FindItems(&filter)
+	filter.Root = injector.Policy.Scope(request.Principal, filter.Root)
+
+	items, err := store.FindItems(request.Context, &filter)
 
 	if err != nil {
-		log.Errorf("[%s] failed to retrieve database items: %s", context.ID, err)
+		request.Logger.Errorf("failed to retrieve database items: %s", err)
 		return &api.APIResponse{
 			StatusCode: http.StatusInternalServerError,
 		}
@@ -1,59 +1,53 @@
 package getalbumtracks
 
 import (
-	"fmt"
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/gostream-official/albums/impl/inject"
 	"github.com/gostream-official/albums/impl/models"
 	"github.com/gostream-official/albums/pkg/api"
-	"github.com/gostream-official/albums/pkg/marshal"
-	"github.com/gostream-official/albums/pkg/parallel"
+	"github.com/gostream-official/albums/pkg/cache"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/router"
 	"github.com/gostream-official/albums/pkg/store"
 	"github.com/gostream-official/albums/pkg/store/query"
-	"github.com/revx-official/output/log"
 )
 
-// Description:
-//
-//	Attempts to cast the input object to the endpoint injector.
-//	If this cast fails, we cannot proceed to process this request.
-//
-// Parameters:
-//
-//	object 	The injector object.
-//
-// Returns:
-//
-//	The injector if the cast is successful, an error otherwise.
-func GetSafeInjector(object interface{}) (*inject.Injector, error) {
-	injector, ok := object.(inject.Injector)
+const (
 
-	if !ok {
-		return nil, fmt.Errorf("getalbumtracks: failed to deduce injector")
-	}
-
-	return &injector, nil
-}
+	// The time tracks are cached for. Tracks are owned by the tracks service and rarely change.
+	trackCacheTTL = time.Minute
+)
 
 // Description:
 //
 //	Finds all tracks contained in an album.
+//	Tracks are served from the cache where possible, only missing tracks are queried and then cached.
 //
 // Parameters:
 //
-//	store The track store.
-//	album The album to search.
+//	ctx 	The request context.
+//	store 	The track store.
+//	cache 	The cache holding recently queried tracks.
+//	album 	The album to search.
 //
 // Returns:
 //
-//	All tracks contained in the given album.
+//	All tracks contained in the given album, in the order of the album.
 //	An error if the database query fails.
-func FindTracksForAlbum(store *store.MongoStore[models.TrackInfo], album *models.AlbumInfo) ([]models.TrackInfo, error) {
-	filter := query.Filter{}
+func FindTracksForAlbum(ctx context.Context, store store.Store[models.TrackInfo], cache cache.Cache, album *models.AlbumInfo) ([]models.TrackInfo, error) {
+	found := make(map[string]models.TrackInfo)
 
 	filters := make([]query.IQuery, 0)
 	for _, trackID := range album.TrackIDs {
+		cached, ok := cache.Get(trackCacheKey(trackID))
+		if ok {
+			found[trackID] = cached.(models.TrackInfo)
+			continue
+		}
+
 		eq := query.FilterOperatorEq{
 			Key:   "_id",
 			Value: trackID,
@@ -62,61 +56,99 @@ func FindTracksForAlbum(store *store.MongoStore[models.TrackInfo], album *models
 		filters = append(filters, eq)
 	}
 
-	or := query.FilterOperatorOr{
-		Or: filters,
-	}
+	if len(filters) > 0 {
+		filter := query.Filter{
+			Root: query.FilterOperatorOr{
+				Or: filters,
+			},
+		}
 
-	filter.Root = or
+		tracks, err := store.FindItems(ctx, &filter)
+		if err != nil {
+			return nil, err
+		}
 
-	tracks, err := store.FindItems(&filter)
-	if err != nil {
-		return nil, err
+		for _, track := range tracks {
+			cache.Set(trackCacheKey(track.ID), track, trackCacheTTL)
+			found[track.ID] = track
+		}
+	}
+
+	tracks := make([]models.TrackInfo, 0, len(found))
+	for _, trackID := range album.TrackIDs {
+		track, ok := found[trackID]
+		if ok {
+			tracks = append(tracks, track)
+		}
 	}
 
 	return tracks, nil
 }
 
+// Description:
+//
+//	Creates the cache key of a track.
+//
+// Parameters:
+//
+//	trackID The id of the track.
+//
+// Returns:
+//
+//	The cache key.
+func trackCacheKey(trackID string) string {
+	return "tracks:" + trackID
+}
+
+// Description:
+//
+//	The documentation of the route, used to generate the OpenAPI specification.
+var Doc = router.RouteDoc{
+	OperationID: "getalbumtracks",
+	Summary:     "List the tracks of an album",
+	Tags:        []string{"albums"},
+	Path: []router.ParameterDoc{
+		{Name: "id", Description: "The id of the album.", Format: "uuid"},
+	},
+	Responses: []router.ResponseDoc{
+		{StatusCode: http.StatusOK, Description: "The tracks of the album.", Body: []models.TrackInfo{}},
+		{StatusCode: http.StatusNotFound, Description: "The album does not exist or is not visible to the caller."},
+	},
+}
+
 // Description:
 //
 //	The router handler for getting all tracks in an album.
 //
 // Parameters:
 //
-//	request The incoming request.
-//	object 	The injector. Contains injected dependencies.
+//	request 	The incoming request.
+//	injector 	The injector. Contains injected dependencies.
 //
 // Returns:
 //
 //	An API response object.
-func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
-	context := parallel.NewContext()
-
-	log.Infof("[%s] %s: %s", context.ID, request.Method, request.Path)
-	log.Tracef("[%s] request: %s", context.ID, marshal.Quick(request))
+func Handler(request *api.APIRequest, injector *inject.Injector) *api.APIResponse {
+	logger := request.Logger.With(logging.F("albumId", request.PathParameters["id"]))
 
-	injector, err := GetSafeInjector(object)
-	if err != nil {
-		log.Errorf("[%s] failed to get endpoint injector: %s", context.ID, err)
-		return &api.APIResponse{
-			StatusCode: http.StatusInternalServerError,
-		}
-	}
+	logger.Infof("%s: %s", request.Method, request.Path)
 
-	albumStore := store.NewMongoStore[models.AlbumInfo](injector.MongoInstance, "gostream", "albums")
-	trackStore := store.NewMongoStore[models.TrackInfo](injector.MongoInstance, "gostream", "tracks")
+	albumStore := injector.Albums
+	trackStore := injector.Tracks
 
+	// Albums invisible to restricted callers are not found.
 	filter := query.Filter{
-		Root: query.FilterOperatorEq{
+		Root: injector.Policy.Scope(request.Principal, query.FilterOperatorEq{
 			Key:   "_id",
 			Value: request.PathParameters["id"],
-		},
+		}),
 		Limit: 10,
 	}
 
-	items, err := albumStore.FindItems(&filter)
+	items, err := albumStore.FindItems(request.Context, &filter)
 
 	if err != nil {
-		log.Errorf("[%s] failed to retrieve database items: %s", context.ID, err)
+		logger.Errorf("failed to retrieve database items: %s", err)
 		return &api.APIResponse{
 			StatusCode: http.StatusInternalServerError,
 		}
@@ -130,16 +162,16 @@ func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
 
 	resultItem := items[0]
 	if len(resultItem.TrackIDs) == 0 {
-		log.Warnf("[%s] album does not contain tracks", context.ID)
+		logger.Warnf("album does not contain tracks")
 		return &api.APIResponse{
 			StatusCode: http.StatusOK,
 			Body:       []models.TrackInfo{},
 		}
 	}
 
-	tracks, err := FindTracksForAlbum(trackStore, &resultItem)
+	tracks, err := FindTracksForAlbum(request.Context, trackStore, injector.Cache, &resultItem)
 	if err != nil {
-		log.Errorf("[%s] failed to find album tracks: %s", context.ID, err)
+		logger.Errorf("failed to find album tracks: %s", err)
 		return &api.APIResponse{
 			StatusCode: http.StatusInternalServerError,
 		}
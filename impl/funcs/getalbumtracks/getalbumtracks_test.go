@@ -0,0 +1,102 @@
+package getalbumtracks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/impl/models"
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/cache"
+	"github.com/gostream-official/albums/pkg/clock"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/store/storetest"
+)
+
+// Description:
+//
+//	Creates a request for the tracks of the given album.
+//
+// Parameters:
+//
+//	albumID The id of the album.
+//
+// Returns:
+//
+//	The request.
+func newRequest(albumID string) *api.APIRequest {
+	return &api.APIRequest{
+		Method:         http.MethodGet,
+		Path:           "/albums/" + albumID + "/tracks",
+		PathParameters: map[string]string{"id": albumID},
+		Context:        context.Background(),
+		Logger:         logging.Named("test"),
+	}
+}
+
+func TestHandlerServesCachedTracks(t *testing.T) {
+	albums := storetest.NewMemoryStore(models.AlbumInfo{ID: "album", TrackIDs: []string{"b", "a"}})
+	tracks := storetest.NewMemoryStore(models.TrackInfo{ID: "a", Title: "A"}, models.TrackInfo{ID: "b", Title: "B"})
+
+	injector := inject.New(nil,
+		inject.WithAlbumStore(albums),
+		inject.WithTrackStore(tracks),
+		inject.WithCache(cache.NewMemoryCache(clock.Fixed(time.Unix(0, 0)))),
+	)
+
+	response := Handler(newRequest("album"), injector)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", response.StatusCode)
+	}
+
+	body := response.Body.([]models.TrackInfo)
+	if len(body) != 2 || body[0].ID != "b" || body[1].ID != "a" {
+		t.Fatalf("expected tracks in album order, got %+v", body)
+	}
+
+	// The tracks are cached, so a failing track store does not affect the second request.
+	tracks.Fail(errors.New("unavailable"))
+
+	response = Handler(newRequest("album"), injector)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 from the cache, got %d", response.StatusCode)
+	}
+
+	if calls := tracks.Calls("find"); calls != 1 {
+		t.Fatalf("expected 1 track query, got %d", calls)
+	}
+}
+
+func TestFindTracksForAlbumQueriesMissingTracks(t *testing.T) {
+	tracks := storetest.NewMemoryStore(models.TrackInfo{ID: "a"}, models.TrackInfo{ID: "b"})
+	trackCache := cache.NewMemoryCache(clock.Fixed(time.Unix(0, 0)))
+
+	_, err := FindTracksForAlbum(context.Background(), tracks, trackCache, &models.AlbumInfo{TrackIDs: []string{"a"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	found, err := FindTracksForAlbum(context.Background(), tracks, trackCache, &models.AlbumInfo{TrackIDs: []string{"a", "b", "missing"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(found) != 2 {
+		t.Fatalf("expected 2 tracks, got %d", len(found))
+	}
+
+	if _, ok := trackCache.Get(trackCacheKey("b")); !ok {
+		t.Fatalf("expected track b to be cached")
+	}
+
+	if _, ok := trackCache.Get(trackCacheKey("missing")); ok {
+		t.Fatalf("expected missing track not to be cached")
+	}
+
+	if calls := tracks.Calls("find"); calls != 2 {
+		t.Fatalf("expected 2 track queries, got %d", calls)
+	}
+}
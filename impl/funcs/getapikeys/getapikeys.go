@@ -0,0 +1,104 @@
+package getapikeys
+
+import (
+	"net/http"
+
+	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/impl/models"
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/router"
+	"github.com/gostream-official/albums/pkg/store/query"
+)
+
+// Description:
+//
+//	Creates a query filter from the incoming API request.
+//	Supports the limit and owner parameters.
+//
+// Parameters:
+//
+//	request The incoming API request.
+//
+// Returns:
+//
+//	The created query filter, or a parameter error if a query parameter is invalid.
+func CreateFilterFromQueryParameters(request *api.APIRequest) (query.Filter, error) {
+	resultFilter := query.Filter{}
+
+	limit, err := request.QueryInt("limit", 0)
+	if err != nil {
+		return resultFilter, err
+	}
+
+	if limit > 0 {
+		resultFilter.Limit = uint32(limit)
+	}
+
+	if owner, ok := request.Query("owner"); ok && owner != "" {
+		resultFilter.Root = query.FilterOperatorEq{
+			Key:   "owner",
+			Value: owner,
+		}
+	}
+
+	return resultFilter, nil
+}
+
+// Description:
+//
+//	The documentation of the route, used to generate the OpenAPI specification.
+var Doc = router.RouteDoc{
+	OperationID: "getapikeys",
+	Summary:     "List API keys",
+	Description: "Requires the admin role.",
+	Tags:        []string{"apikeys"},
+	Query: []router.ParameterDoc{
+		{Name: "limit", Description: "The maximum number of keys.", Type: 0},
+		{Name: "owner", Description: "Matches keys of the given owner."},
+	},
+	Responses: []router.ResponseDoc{
+		{StatusCode: http.StatusOK, Description: "The keys, without the keys themselves.", Body: []models.APIKeyInfo{}},
+		{StatusCode: http.StatusBadRequest, Description: "Invalid query parameter.", Body: api.ParameterError{}},
+		{StatusCode: http.StatusForbidden, Description: "Missing admin role.", Body: api.ErrorResponseBody{}},
+	},
+}
+
+// Description:
+//
+//	The router handler for listing API keys.
+//	Keys are listed without their hashes.
+//
+// Parameters:
+//
+//	request 	The incoming request.
+//	injector 	The injector. Contains injected dependencies.
+//
+// Returns:
+//
+//	An API response object.
+func Handler(request *api.APIRequest, injector *inject.Injector) *api.APIResponse {
+	request.Logger.Infof("%s: %s", request.Method, request.Path)
+
+	filter, err := CreateFilterFromQueryParameters(request)
+	if err != nil {
+		request.Logger.Warnf("failed query parameter validation: %s", err)
+		return &api.APIResponse{
+			StatusCode: http.StatusBadRequest,
+			Body:       err,
+		}
+	}
+
+	items, err := injector.APIKeys.FindItems(request.Context, &filter)
+
+	if err != nil {
+		request.Logger.Errorf("failed to retrieve database items: %s", err)
+		return &api.APIResponse{
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return &api.APIResponse{
+		StatusCode: http.StatusOK,
+		Body:       items,
+	}
+}
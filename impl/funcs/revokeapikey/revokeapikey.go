@@ -0,0 +1,110 @@
+package revokeapikey
+
+import (
+	"net/http"
+
+	"github.com/gostream-official/albums/impl/apikeys"
+	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/router"
+	"github.com/gostream-official/albums/pkg/store/query"
+)
+
+// Description:
+//
+//	The documentation of the route, used to generate the OpenAPI specification.
+var Doc = router.RouteDoc{
+	OperationID: "revokeapikey",
+	Summary:     "Revoke an API key",
+	Description: "Requires the admin role. Revoked keys are rejected immediately.",
+	Tags:        []string{"apikeys"},
+	Path: []router.ParameterDoc{
+		{Name: "id", Description: "The id of the key.", Format: "uuid"},
+	},
+	Responses: []router.ResponseDoc{
+		{StatusCode: http.StatusNoContent, Description: "The key is revoked."},
+		{StatusCode: http.StatusBadRequest, Description: "Invalid key id.", Body: api.ParameterError{}},
+		{StatusCode: http.StatusForbidden, Description: "Missing admin role.", Body: api.ErrorResponseBody{}},
+		{StatusCode: http.StatusNotFound, Description: "The key does not exist."},
+	},
+}
+
+// Description:
+//
+//	The router handler for API key revocation.
+//	Revoked keys are kept, so that their usage remains auditable, but are rejected immediately.
+//	Revoking a revoked key has no effect.
+//
+// Parameters:
+//
+//	request 	The incoming request.
+//	injector 	The injector. Contains injected dependencies.
+//
+// Returns:
+//
+//	An API response object.
+func Handler(request *api.APIRequest, injector *inject.Injector) *api.APIResponse {
+	logger := request.Logger.With(logging.F("keyId", request.PathParameters["id"]))
+
+	logger.Infof("%s: %s", request.Method, request.Path)
+
+	id, err := request.PathUUID("id")
+	if err != nil {
+		logger.Warnf("failed path parameter validation: %s", err)
+		return &api.APIResponse{
+			StatusCode: http.StatusBadRequest,
+			Body:       err,
+		}
+	}
+
+	info, err := apikeys.FindByID(request.Context, injector, id)
+	if err != nil {
+		logger.Errorf("failed to retrieve database item: %s", err)
+		return &api.APIResponse{
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	if info == nil {
+		return &api.APIResponse{
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	if info.RevokedAt != nil {
+		return &api.APIResponse{
+			StatusCode: http.StatusNoContent,
+		}
+	}
+
+	updateFilter := query.Filter{
+		Root: query.FilterOperatorEq{
+			Key:   "_id",
+			Value: id,
+		},
+	}
+
+	updateOperator := query.Update{
+		Root: query.UpdateOperatorSet{
+			Set: map[string]interface{}{
+				"revokedAt": injector.Clock.Now().UTC(),
+			},
+		},
+	}
+
+	logger.Tracef("attempting to update database item ...")
+	_, err = injector.APIKeys.UpdateItem(request.Context, &updateFilter, &updateOperator)
+
+	if err != nil {
+		logger.Errorf("failed to update database item: %s", err)
+		return &api.APIResponse{
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	logger.Infof("revoked api key of owner %s", info.Owner)
+	return &api.APIResponse{
+		StatusCode: http.StatusNoContent,
+	}
+}
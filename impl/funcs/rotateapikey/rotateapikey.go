@@ -0,0 +1,149 @@
+package rotateapikey
+
+import (
+	"net/http"
+
+	"github.com/gostream-official/albums/impl/apikeys"
+	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/router"
+	"github.com/gostream-official/albums/pkg/store/query"
+)
+
+// Description:
+//
+//	The documentation of the route, used to generate the OpenAPI specification.
+var Doc = router.RouteDoc{
+	OperationID: "rotateapikey",
+	Summary:     "Rotate an API key",
+	Description: "Requires the admin role. The previous key is invalid immediately, the new plaintext key is only contained in this response.",
+	Tags:        []string{"apikeys"},
+	Path: []router.ParameterDoc{
+		{Name: "id", Description: "The id of the key.", Format: "uuid"},
+	},
+	Responses: []router.ResponseDoc{
+		{StatusCode: http.StatusOK, Description: "The rotated key.", Body: apikeys.Issued{}},
+		{StatusCode: http.StatusBadRequest, Description: "Invalid key id.", Body: api.ParameterError{}},
+		{StatusCode: http.StatusForbidden, Description: "Missing admin role.", Body: api.ErrorResponseBody{}},
+		{StatusCode: http.StatusNotFound, Description: "The key does not exist."},
+		{StatusCode: http.StatusConflict, Description: "The key is revoked or was modified concurrently.", Body: api.ErrorResponseBody{}},
+	},
+}
+
+// Description:
+//
+//	The router handler for API key rotation.
+//	Replaces the key, keeping its id, name, owner, scopes and expiry.
+//	The previous key is invalid immediately, the new plaintext key is only contained in this response.
+//
+// Parameters:
+//
+//	request 	The incoming request.
+//	injector 	The injector. Contains injected dependencies.
+//
+// Returns:
+//
+//	An API response object.
+func Handler(request *api.APIRequest, injector *inject.Injector) *api.APIResponse {
+	logger := request.Logger.With(logging.F("keyId", request.PathParameters["id"]))
+
+	logger.Infof("%s: %s", request.Method, request.Path)
+
+	id, err := request.PathUUID("id")
+	if err != nil {
+		logger.Warnf("failed path parameter validation: %s", err)
+		return &api.APIResponse{
+			StatusCode: http.StatusBadRequest,
+			Body:       err,
+		}
+	}
+
+	info, err := apikeys.FindByID(request.Context, injector, id)
+	if err != nil {
+		logger.Errorf("failed to retrieve database item: %s", err)
+		return &api.APIResponse{
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	if info == nil {
+		return &api.APIResponse{
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	if info.RevokedAt != nil {
+		return &api.APIResponse{
+			StatusCode: http.StatusConflict,
+			Body: api.ErrorResponseBody{
+				Message:   "api key is revoked",
+				RequestID: request.RequestID,
+			},
+		}
+	}
+
+	issued, err := apikeys.Issue(injector, info.Name, info.Owner, info.Scopes, info.ExpiresAt)
+	if err != nil {
+		logger.Errorf("failed to generate api key: %s", err)
+		return &api.APIResponse{
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	issued.ID = info.ID
+
+	updateFilter := query.Filter{
+		Root: query.FilterOperatorAnd{
+			And: []query.IQuery{
+				query.FilterOperatorEq{Key: "_id", Value: id},
+				query.FilterOperatorEq{Key: "hash", Value: info.Hash},
+
+				// Matches keys without a revocation time, so that concurrently revoked keys stay revoked.
+				query.FilterOperatorEq{Key: "revokedAt", Value: nil},
+			},
+		},
+	}
+
+	updateOperator := query.Update{
+		Root: query.UpdateOperatorSet{
+			Set: map[string]interface{}{
+				"hash":       issued.Hash,
+				"hint":       issued.Hint,
+				"createdAt":  issued.CreatedAt,
+				"lastUsedAt": nil,
+			},
+		},
+	}
+
+	logger.Tracef("attempting to update database item ...")
+	count, err := injector.APIKeys.UpdateItem(request.Context, &updateFilter, &updateOperator)
+
+	if err != nil {
+		logger.Errorf("failed to update database item: %s", err)
+		return &api.APIResponse{
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	// The key was rotated or revoked concurrently.
+	if count == 0 {
+		logger.Warnf("zero modified items")
+		return &api.APIResponse{
+			StatusCode: http.StatusConflict,
+			Body: api.ErrorResponseBody{
+				Message:   "api key was modified concurrently",
+				RequestID: request.RequestID,
+			},
+		}
+	}
+
+	logger.Infof("rotated api key")
+	return &api.APIResponse{
+		StatusCode: http.StatusOK,
+		Headers: map[string]string{
+			"Cache-Control": "no-store",
+		},
+		Body: issued,
+	}
+}
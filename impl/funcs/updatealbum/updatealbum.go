@@ -1,22 +1,24 @@
 package updatealbum
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/gostream-official/albums/impl/inject"
 	"github.com/gostream-official/albums/impl/models"
+	"github.com/gostream-official/albums/impl/policy"
 	"github.com/gostream-official/albums/pkg/api"
 	"github.com/gostream-official/albums/pkg/arrays"
-	"github.com/gostream-official/albums/pkg/marshal"
-	"github.com/gostream-official/albums/pkg/parallel"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/router"
 	"github.com/gostream-official/albums/pkg/store"
 	"github.com/gostream-official/albums/pkg/store/query"
-	"github.com/revx-official/output/log"
+	"github.com/gostream-official/albums/pkg/tracing"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Description:
@@ -25,13 +27,13 @@ import (
 type UpdateAlbumRequestBody struct {
 
 	// The title of the album.
-	Title string `json:"title,omitempty"`
+	Title string `json:"title,omitempty" xml:"title,omitempty"`
 
 	// The tracks contained in the album.
-	TrackIDs []string `json:"trackIds,omitempty"`
+	TrackIDs []string `json:"trackIds,omitempty" xml:"trackIds,omitempty"`
 
 	// Some album statistics.
-	Stats UpdateAlbumStatsRequestBody `json:"stats,omitempty"`
+	Stats UpdateAlbumStatsRequestBody `json:"stats,omitempty" xml:"stats,omitempty"`
 }
 
 // Description:
@@ -40,7 +42,7 @@ type UpdateAlbumRequestBody struct {
 type UpdateAlbumStatsRequestBody struct {
 
 	// The popularity factor.
-	Popularity float32 `json:"popularity,omitempty"`
+	Popularity float32 `json:"popularity,omitempty" xml:"popularity,omitempty"`
 }
 
 // Description:
@@ -49,7 +51,7 @@ type UpdateAlbumStatsRequestBody struct {
 type UpdateAlbumErrorResponseBody struct {
 
 	// The error message.
-	Message string `json:"message"`
+	Message string `json:"message" xml:"message"`
 }
 
 // Description:
@@ -58,10 +60,10 @@ type UpdateAlbumErrorResponseBody struct {
 type UpdateAlbumValidationError struct {
 
 	// The JSON field which is referenced by the error message.
-	FieldRef string `json:"ref"`
+	FieldRef string `json:"ref" xml:"ref"`
 
 	// The error message.
-	ErrorMessage string `json:"error"`
+	ErrorMessage string `json:"error" xml:"error"`
 }
 
 // Description:
@@ -70,32 +72,10 @@ type UpdateAlbumValidationError struct {
 type UpdateAlbumPathValidationError struct {
 
 	// The JSON field which is referenced by the error message.
-	PathRef string `json:"pathRef"`
+	PathRef string `json:"pathRef" xml:"pathRef"`
 
 	// The error message.
-	ErrorMessage string `json:"error"`
-}
-
-// Description:
-//
-//	Attempts to cast the input object to the endpoint injector.
-//	If this cast fails, we cannot proceed to process this request.
-//
-// Parameters:
-//
-//	object 	The injector object.
-//
-// Returns:
-//
-//	The injector if the cast is successful, an error otherwise.
-func GetSafeInjector(object interface{}) (*inject.Injector, error) {
-	injector, ok := object.(inject.Injector)
-
-	if !ok {
-		return nil, fmt.Errorf("updatealbum: failed to deduce injector")
-	}
-
-	return &injector, nil
+	ErrorMessage string `json:"error" xml:"error"`
 }
 
 // Description:
@@ -112,9 +92,7 @@ func GetSafeInjector(object interface{}) (*inject.Injector, error) {
 func ExtractRequestBody(request *api.APIRequest) (*UpdateAlbumRequestBody, error) {
 	body := &UpdateAlbumRequestBody{}
 
-	bytes := []byte(request.Body)
-	err := json.Unmarshal(bytes, body)
-
+	err := request.Decode(body)
 	if err != nil {
 		return nil, err
 	}
@@ -212,6 +190,7 @@ func ValidateAlbumStatsRequestBody(request *UpdateAlbumStatsRequestBody) *Update
 //
 // Parameters:
 //
+//	ctx 	The request context.
 //	store 	The store to search through.
 //	id 		The id to search for.
 //
@@ -219,7 +198,7 @@ func ValidateAlbumStatsRequestBody(request *UpdateAlbumStatsRequestBody) *Update
 //
 //	The first matched album.
 //	An error if the query fails.
-func FindAlbumByID(store *store.MongoStore[models.AlbumInfo], id string) (*models.AlbumInfo, error) {
+func FindAlbumByID(ctx context.Context, store store.Store[models.AlbumInfo], id string) (*models.AlbumInfo, error) {
 	filter := query.Filter{
 		Root: query.FilterOperatorEq{
 			Key:   "_id",
@@ -228,7 +207,7 @@ func FindAlbumByID(store *store.MongoStore[models.AlbumInfo], id string) (*model
 		Limit: 1,
 	}
 
-	items, err := store.FindItems(&filter)
+	items, err := store.FindItems(ctx, &filter)
 	if err != nil {
 		return nil, err
 	}
@@ -246,6 +225,7 @@ func FindAlbumByID(store *store.MongoStore[models.AlbumInfo], id string) (*model
 //
 // Parameters:
 //
+//	ctx 	The request context.
 //	store 	The mongo store to search.
 //	trackID The artist id to search.
 //
@@ -253,7 +233,7 @@ func FindAlbumByID(store *store.MongoStore[models.AlbumInfo], id string) (*model
 //
 //	An error, if the artist could not be found or an error,
 //	if the database request failed, nothing if successful.
-func CheckIfTrackExists(store *store.MongoStore[models.TrackInfo], trackID string) error {
+func CheckIfTrackExists(ctx context.Context, store store.Store[models.TrackInfo], trackID string) error {
 	filter := query.Filter{
 		Root: query.FilterOperatorEq{
 			Key:   "_id",
@@ -262,7 +242,7 @@ func CheckIfTrackExists(store *store.MongoStore[models.TrackInfo], trackID strin
 		Limit: 1,
 	}
 
-	items, err := store.FindItems(&filter)
+	items, err := store.FindItems(ctx, &filter)
 	if err != nil {
 		return err
 	}
@@ -274,55 +254,75 @@ func CheckIfTrackExists(store *store.MongoStore[models.TrackInfo], trackID strin
 	return nil
 }
 
+// Description:
+//
+//	The documentation of the route, used to generate the OpenAPI specification.
+var Doc = router.RouteDoc{
+	OperationID: "updatealbum",
+	Summary:     "Update an album",
+	Description: "Albums may be updated by their owner, principals of their label and administrators.",
+	Tags:        []string{"albums"},
+	Request:     UpdateAlbumRequestBody{},
+	Path: []router.ParameterDoc{
+		{Name: "id", Description: "The id of the album.", Format: "uuid"},
+	},
+	Responses: []router.ResponseDoc{
+		{StatusCode: http.StatusNoContent, Description: "The album was updated."},
+		{StatusCode: http.StatusBadRequest, Description: "Invalid request body.", Body: UpdateAlbumValidationError{}},
+		{StatusCode: http.StatusForbidden, Description: "Missing scope, or the album is owned by another principal.", Body: api.ErrorResponseBody{}},
+		{StatusCode: http.StatusNotFound, Description: "The album does not exist."},
+	},
+}
+
 // Description:
 //
 //	The router handler for track creation.
 //
 // Parameters:
 //
-//	request The incoming request.
-//	object 	The injector. Contains injected dependencies.
+//	request 	The incoming request.
+//	injector 	The injector. Contains injected dependencies.
 //
 // Returns:
 //
 //	An API response object.
-func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
-	context := parallel.NewContext()
-
-	log.Infof("[%s] %s: %s", context.ID, request.Method, request.Path)
-	log.Tracef("[%s] request: %s", context.ID, marshal.Quick(request))
+func Handler(request *api.APIRequest, injector *inject.Injector) *api.APIResponse {
+	logger := request.Logger.With(logging.F("albumId", request.PathParameters["id"]))
 
-	injector, err := GetSafeInjector(object)
-	if err != nil {
-		log.Warnf("[%s] failed to get endpoint injector: %s", context.ID, err)
-		return &api.APIResponse{
-			StatusCode: http.StatusInternalServerError,
-		}
-	}
+	logger.Infof("%s: %s", request.Method, request.Path)
 
 	id, validationErr := GetAndValidateID(request)
 	if validationErr != nil {
-		log.Warnf("[%s] failed path parameter validation: %s", context.ID, validationErr.ErrorMessage)
+		logger.Warnf("failed path parameter validation: %s", validationErr.ErrorMessage)
 		return &api.APIResponse{
 			StatusCode: http.StatusBadRequest,
 			Body:       validationErr,
 		}
 	}
 
-	trackStore := store.NewMongoStore[models.TrackInfo](injector.MongoInstance, "gostream", "tracks")
-	albumStore := store.NewMongoStore[models.AlbumInfo](injector.MongoInstance, "gostream", "albums")
+	trackStore := injector.Tracks
+	albumStore := injector.Albums
 
-	albumInfo, err := FindAlbumByID(albumStore, id)
+	albumInfo, err := FindAlbumByID(request.Context, albumStore, id)
 	if err != nil {
-		log.Warnf("[%s] could not find album: %s", context.ID, err)
+		logger.Warnf("could not find album: %s", err)
 		return &api.APIResponse{
 			StatusCode: http.StatusNotFound,
 		}
 	}
 
+	err = injector.Policy.CanModify(request.Principal, albumInfo)
+	if err != nil {
+		logger.Warnf("denied album update: %s", err)
+		return policy.Forbidden(request, err)
+	}
+
+	_, decodeSpan := tracing.Start(request.Context, "updatealbum.decode")
 	requestBody, err := ExtractRequestBody(request)
+	tracing.End(decodeSpan, err)
+
 	if err != nil {
-		log.Warnf("[%s] failed to extract request body: %s", context.ID, err)
+		logger.Warnf("failed to extract request body: %s", err)
 		return &api.APIResponse{
 			StatusCode: http.StatusBadRequest,
 			Body: UpdateAlbumErrorResponseBody{
@@ -333,7 +333,7 @@ func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
 
 	validationError := ValidateRequestBody(requestBody)
 	if validationError != nil {
-		log.Warnf("[%s] failed request body validation: %s", context.ID, validationError.ErrorMessage)
+		logger.Warnf("failed request body validation: %s", validationError.ErrorMessage)
 		return &api.APIResponse{
 			StatusCode: http.StatusBadRequest,
 			Body:       validationError,
@@ -341,10 +341,13 @@ func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
 	}
 
 	if len(requestBody.TrackIDs) > 0 {
+		tracksContext, tracksSpan := tracing.Start(request.Context, "updatealbum.checkTracks", attribute.Int("album.tracks", len(requestBody.TrackIDs)))
+
 		for _, trackIDs := range requestBody.TrackIDs {
-			err = CheckIfTrackExists(trackStore, trackIDs)
+			err = CheckIfTrackExists(tracksContext, trackStore, trackIDs)
 			if err != nil {
-				log.Warnf("[%s] track does not exist: %s", context.ID, err)
+				tracing.End(tracksSpan, err)
+				logger.Warnf("track does not exist: %s", err)
 				return &api.APIResponse{
 					StatusCode: http.StatusBadRequest,
 					Body: UpdateAlbumErrorResponseBody{
@@ -353,6 +356,8 @@ func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
 				}
 			}
 		}
+
+		tracing.End(tracksSpan, nil)
 	}
 
 	if requestBody.Title != "" {
@@ -384,24 +389,29 @@ func Handler(request *api.APIRequest, object interface{}) *api.APIResponse {
 		},
 	}
 
-	log.Tracef("[%s] attempting to update database item ...", context.ID)
-	count, err := albumStore.UpdateItem(&updateFilter, &updateOperator)
+	logger.Tracef("attempting to update database item ...")
+	count, err := albumStore.UpdateItem(request.Context, &updateFilter, &updateOperator)
 
 	if err != nil {
-		log.Errorf("[%s] failed to update database item: %s", context.ID, err)
+		logger.Errorf("failed to update database item: %s", err)
 		return &api.APIResponse{
 			StatusCode: http.StatusInternalServerError,
 		}
 	}
 
 	if count == 0 {
-		log.Warnf("[%s] zero modified items", context.ID)
+		logger.Warnf("zero modified items")
 		return &api.APIResponse{
 			StatusCode: http.StatusNoContent,
 		}
 	}
 
-	log.Tracef("[%s] successfully completed request", context.ID)
+	err = injector.Publisher.Publish(request.Context, models.AlbumUpdatedTopic, *albumInfo)
+	if err != nil {
+		logger.Warnf("failed to publish album update: %s", err)
+	}
+
+	logger.Tracef("successfully completed request")
 	return &api.APIResponse{
 		StatusCode: http.StatusNoContent,
 	}
@@ -1,13 +1,239 @@
 package inject
 
-import "github.com/gostream-official/albums/pkg/store"
+import (
+	"github.com/gostream-official/albums/impl/models"
+	"github.com/gostream-official/albums/impl/policy"
+	"github.com/gostream-official/albums/pkg/cache"
+	"github.com/gostream-official/albums/pkg/clock"
+	"github.com/gostream-official/albums/pkg/events"
+	"github.com/gostream-official/albums/pkg/ids"
+	"github.com/gostream-official/albums/pkg/store"
+)
+
+const (
+
+	// The database holding the documents of this service.
+	Database = "gostream"
+
+	// The collection holding the hashed API keys.
+	APIKeysCollection = "apikeys"
+)
 
 // Description:
 //
 //	The injector object for this service.
 //	This object is used for endpoint dependency injection.
+//	Every dependency can be replaced via options, e.g. with fakes in tests.
 type Injector struct {
 
 	// The MongoDB store instance.
 	MongoInstance *store.MongoInstance
+
+	// The album store.
+	Albums store.Store[models.AlbumInfo]
+
+	// The track store.
+	Tracks store.Store[models.TrackInfo]
+
+	// The API key store.
+	APIKeys store.Store[models.APIKeyInfo]
+
+	// The cache shared by all handlers.
+	Cache cache.Cache
+
+	// The clock.
+	Clock clock.Clock
+
+	// The generator of document ids.
+	IDs ids.Generator
+
+	// The publisher of domain events.
+	Publisher events.Publisher
+
+	// The authorization policy for albums.
+	Policy *policy.Policy
+}
+
+// Description:
+//
+//	Function definition for injector options.
+//	Options replace single dependencies of the injector.
+type Option = func(injector *Injector)
+
+// Description:
+//
+//	Creates the injector of this service.
+//	Stores are backed by the given mongo instance, the remaining dependencies use the defaults:
+//	an in-memory cache, the system clock, UUIDs, a publisher writing events to the log
+//	and an album policy, which is not enforced.
+//
+// Parameters:
+//
+//	instance 	The mongo instance. May be nil if all stores are replaced via options.
+//	options 	Options replacing single dependencies.
+//
+// Returns:
+//
+//	The created injector.
+func New(instance *store.MongoInstance, options ...Option) *Injector {
+	injector := &Injector{
+		MongoInstance: instance,
+		Clock:         clock.System(),
+		IDs:           ids.UUID(),
+		Publisher:     events.LogPublisher(),
+		Policy:        policy.New(policy.Config{}),
+	}
+
+	if instance != nil {
+		injector.Albums = store.NewMongoStore[models.AlbumInfo](instance, Database, "albums")
+		injector.Tracks = store.NewMongoStore[models.TrackInfo](instance, Database, "tracks")
+		injector.APIKeys = store.NewMongoStore[models.APIKeyInfo](instance, Database, APIKeysCollection)
+	}
+
+	for _, option := range options {
+		option(injector)
+	}
+
+	if injector.Cache == nil {
+		injector.Cache = cache.NewMemoryCache(injector.Clock)
+	}
+
+	return injector
+}
+
+// Description:
+//
+//	Replaces the album store.
+//
+// Parameters:
+//
+//	albums The album store.
+//
+// Returns:
+//
+//	The injector option.
+func WithAlbumStore(albums store.Store[models.AlbumInfo]) Option {
+	return func(injector *Injector) {
+		injector.Albums = albums
+	}
+}
+
+// Description:
+//
+//	Replaces the track store.
+//
+// Parameters:
+//
+//	tracks The track store.
+//
+// Returns:
+//
+//	The injector option.
+func WithTrackStore(tracks store.Store[models.TrackInfo]) Option {
+	return func(injector *Injector) {
+		injector.Tracks = tracks
+	}
+}
+
+// Description:
+//
+//	Replaces the API key store.
+//
+// Parameters:
+//
+//	apiKeys The API key store.
+//
+// Returns:
+//
+//	The injector option.
+func WithAPIKeyStore(apiKeys store.Store[models.APIKeyInfo]) Option {
+	return func(injector *Injector) {
+		injector.APIKeys = apiKeys
+	}
+}
+
+// Description:
+//
+//	Replaces the cache.
+//
+// Parameters:
+//
+//	cache The cache.
+//
+// Returns:
+//
+//	The injector option.
+func WithCache(cache cache.Cache) Option {
+	return func(injector *Injector) {
+		injector.Cache = cache
+	}
+}
+
+// Description:
+//
+//	Replaces the clock.
+//	The default cache uses the replaced clock as well.
+//
+// Parameters:
+//
+//	clock The clock.
+//
+// Returns:
+//
+//	The injector option.
+func WithClock(clock clock.Clock) Option {
+	return func(injector *Injector) {
+		injector.Clock = clock
+	}
+}
+
+// Description:
+//
+//	Replaces the generator of document ids.
+//
+// Parameters:
+//
+//	generator The id generator.
+//
+// Returns:
+//
+//	The injector option.
+func WithIDGenerator(generator ids.Generator) Option {
+	return func(injector *Injector) {
+		injector.IDs = generator
+	}
+}
+
+// Description:
+//
+//	Replaces the publisher of domain events.
+//
+// Parameters:
+//
+//	publisher The event publisher.
+//
+// Returns:
+//
+//	The injector option.
+func WithPublisher(publisher events.Publisher) Option {
+	return func(injector *Injector) {
+		injector.Publisher = publisher
+	}
+}
+
+// Description:
+//
+//	Replaces the authorization policy for albums.
+//
+// Parameters:
+//
+//	policy The album policy.
+//
+// Returns:
+//
+//	The injector option.
+func WithPolicy(policy *policy.Policy) Option {
+	return func(injector *Injector) {
+		injector.Policy = policy
+	}
 }
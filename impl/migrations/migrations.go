@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/pkg/store"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Description:
+//
+//	All migrations of the service database, applied on startup.
+//	New migrations are appended with the next version.
+var All = []store.Migration{
+	{
+		Version:     1,
+		Description: "create unique index on api key hashes",
+		Apply:       createAPIKeyHashIndex,
+	},
+	{
+		Version:     2,
+		Description: "create indexes on album owners and labels",
+		Apply:       createAlbumOwnershipIndexes,
+	},
+}
+
+// Description:
+//
+//	Creates a unique index on the hashes of API keys, which are looked up on every authenticated request.
+//
+// Parameters:
+//
+//	ctx 		The context.
+//	database 	The service database.
+//
+// Returns:
+//
+//	An error if the index cannot be created.
+func createAPIKeyHashIndex(ctx context.Context, database *mongo.Database) error {
+	_, err := database.Collection(inject.APIKeysCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "hash", Value: 1}},
+		Options: options.Index().SetName("hash").SetUnique(true),
+	})
+
+	return err
+}
+
+// Description:
+//
+//	Creates indexes on the owners and labels of albums, which restrict the albums visible to restricted callers.
+//
+// Parameters:
+//
+//	ctx 		The context.
+//	database 	The service database.
+//
+// Returns:
+//
+//	An error if the indexes cannot be created.
+func createAlbumOwnershipIndexes(ctx context.Context, database *mongo.Database) error {
+	_, err := database.Collection("albums").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "owner", Value: 1}},
+			Options: options.Index().SetName("owner"),
+		},
+		{
+			Keys:    bson.D{{Key: "label", Value: 1}},
+			Options: options.Index().SetName("label"),
+		},
+	})
+
+	return err
+}
@@ -7,16 +7,22 @@ package models
 type AlbumInfo struct {
 
 	// The id of the album (primary key).
-	ID string `json:"id" bson:"_id"`
+	ID string `json:"id" xml:"id" bson:"_id"`
 
 	// The title of the album.
-	Title string `json:"title" bson:"title"`
+	Title string `json:"title" xml:"title" bson:"title"`
 
 	// The tracks contained in the album.
-	TrackIDs []string `json:"trackIds" bson:"trackIds"`
+	TrackIDs []string `json:"trackIds" xml:"trackIds" bson:"trackIds"`
 
 	// Some album statistics.
-	Stats AlbumStats `json:"stats" bson:"stats"`
+	Stats AlbumStats `json:"stats" xml:"stats" bson:"stats"`
+
+	// The subject of the principal, which created the album.
+	Owner string `json:"owner,omitempty" xml:"owner,omitempty" bson:"owner,omitempty"`
+
+	// The label releasing the album.
+	Label string `json:"label,omitempty" xml:"label,omitempty" bson:"label,omitempty"`
 }
 
 // Description:
@@ -25,5 +31,5 @@ type AlbumInfo struct {
 type AlbumStats struct {
 
 	// The popularity factor.
-	Popularity float32 `json:"popularity" bson:"popularity,truncate"`
+	Popularity float32 `json:"popularity" xml:"popularity" bson:"popularity,truncate"`
 }
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Description:
+//
+//	The data model definition for an API key of a machine client.
+//	This is a direct reference to the database data model.
+type APIKeyInfo struct {
+
+	// The id of the key (primary key).
+	ID string `json:"id" bson:"_id" xml:"id"`
+
+	// The display name of the key, e.g. the name of the ingestion job.
+	Name string `json:"name" bson:"name" xml:"name"`
+
+	// The subject the key acts on behalf of.
+	Owner string `json:"owner" bson:"owner" xml:"owner"`
+
+	// The scopes granted to the key.
+	Scopes []string `json:"scopes" bson:"scopes" xml:"scopes"`
+
+	// The SHA-256 hash of the key. The key itself is never stored.
+	Hash string `json:"-" bson:"hash" xml:"-"`
+
+	// The last characters of the key, to tell keys apart.
+	Hint string `json:"hint" bson:"hint" xml:"hint"`
+
+	// The creation time of the key.
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt" xml:"createdAt"`
+
+	// The expiry time of the key, or nil if the key does not expire.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty" xml:"expiresAt,omitempty"`
+
+	// The time the key was last used, or nil if it was never used.
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" bson:"lastUsedAt,omitempty" xml:"lastUsedAt,omitempty"`
+
+	// The time the key was revoked, or nil if it is active.
+	RevokedAt *time.Time `json:"revokedAt,omitempty" bson:"revokedAt,omitempty" xml:"revokedAt,omitempty"`
+}
@@ -0,0 +1,13 @@
+package models
+
+const (
+
+	// The topic of the event published after an album was created. The payload is the album.
+	AlbumCreatedTopic = "albums.created"
+
+	// The topic of the event published after an album was updated. The payload is the updated album.
+	AlbumUpdatedTopic = "albums.updated"
+
+	// The topic of the event published after an album was deleted. The payload is the deleted album.
+	AlbumDeletedTopic = "albums.deleted"
+)
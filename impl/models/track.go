@@ -9,28 +9,28 @@ import "time"
 type TrackInfo struct {
 
 	// The id of the track (primary key).
-	ID string `json:"id" bson:"_id"`
+	ID string `json:"id" xml:"id" bson:"_id"`
 
 	// The id of the track artist.
-	ArtistID string `json:"artistId" bson:"artistId"`
+	ArtistID string `json:"artistId" xml:"artistId" bson:"artistId"`
 
 	// Additional ids of featuring artists.
-	FeaturedArtistIDs []string `json:"featuredArtistIds" bson:"featuredArtistIds"`
+	FeaturedArtistIDs []string `json:"featuredArtistIds" xml:"featuredArtistIds" bson:"featuredArtistIds"`
 
 	// The title of the track.
-	Title string `json:"title" bson:"title"`
+	Title string `json:"title" xml:"title" bson:"title"`
 
 	// The label that published the track.
-	Label string `json:"label" bson:"label"`
+	Label string `json:"label" xml:"label" bson:"label"`
 
 	// The release date of the track.
-	ReleaseDate time.Time `json:"releaseDate" bson:"releaseDate"`
+	ReleaseDate time.Time `json:"releaseDate" xml:"releaseDate" bson:"releaseDate"`
 
 	// Some track statistics.
-	TrackStats TrackStats `json:"trackStats" bson:"trackStats"`
+	TrackStats TrackStats `json:"trackStats" xml:"trackStats" bson:"trackStats"`
 
 	// Some audio features of the track.
-	AudioFeatures AudioFeatures `json:"audioFeatures" bson:"audioFeatures"`
+	AudioFeatures AudioFeatures `json:"audioFeatures" xml:"audioFeatures" bson:"audioFeatures"`
 }
 
 // Description:
@@ -39,10 +39,10 @@ type TrackInfo struct {
 type TrackStats struct {
 
 	// The amount of streams of the track.
-	Streams uint32 `json:"streams" bson:"streams"`
+	Streams uint32 `json:"streams" xml:"streams" bson:"streams"`
 
 	// The amount of likes of the track.
-	Likes uint32 `json:"likes" bson:"likes"`
+	Likes uint32 `json:"likes" xml:"likes" bson:"likes"`
 }
 
 // Descriptions:
@@ -51,34 +51,34 @@ type TrackStats struct {
 type AudioFeatures struct {
 
 	// The key of the track.
-	Key string `json:"key" bson:"key"`
+	Key string `json:"key" xml:"key" bson:"key"`
 
 	// The tempo of the track.
-	Tempo float32 `json:"tempo" bson:"tempo"`
+	Tempo float32 `json:"tempo" xml:"tempo" bson:"tempo"`
 
 	// The duration of the track.
-	Duration float32 `json:"duration" bson:"duration"`
+	Duration float32 `json:"duration" xml:"duration" bson:"duration"`
 
 	// The energy level of the track.
-	Energy float32 `json:"energy" bson:"energy"`
+	Energy float32 `json:"energy" xml:"energy" bson:"energy"`
 
 	// The danceability level of the track.
-	Danceability float32 `json:"danceability" bson:"danceability"`
+	Danceability float32 `json:"danceability" xml:"danceability" bson:"danceability"`
 
 	// The accousticness level of the track.
-	Accousticness float32 `json:"accousticness" bson:"accousticness"`
+	Accousticness float32 `json:"accousticness" xml:"accousticness" bson:"accousticness"`
 
 	// The instrumentalness level of the track.
-	Instrumentalness float32 `json:"instrumentalness" bson:"instrumentalness"`
+	Instrumentalness float32 `json:"instrumentalness" xml:"instrumentalness" bson:"instrumentalness"`
 
 	// The liveness level of the track.
-	Liveness float32 `json:"liveness" bson:"liveness"`
+	Liveness float32 `json:"liveness" xml:"liveness" bson:"liveness"`
 
 	// The loudness of the track (in LUFS).
-	Loudness float32 `json:"loudness" bson:"loudness"`
+	Loudness float32 `json:"loudness" xml:"loudness" bson:"loudness"`
 
 	// The time signature of the track.
-	TimeSignature int `json:"timeSignature" bson:"timeSignature"`
+	TimeSignature int `json:"timeSignature" xml:"timeSignature" bson:"timeSignature"`
 }
 
 const (
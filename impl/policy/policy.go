@@ -0,0 +1,231 @@
+package policy
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gostream-official/albums/impl/models"
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/store/query"
+)
+
+// Description:
+//
+//	The configuration of the album authorization policy.
+type Config struct {
+
+	// Whether the policy is enforced. If not, every caller may access every album.
+	Enforced bool
+
+	// The role allowed to access and modify every album.
+	AdminRole string
+
+	// The role of callers, which may only see albums they or their label own.
+	RestrictedRole string
+
+	// The claim holding the label of a principal.
+	LabelClaim string
+}
+
+// Description:
+//
+//	The authorization policy for albums.
+//	Albums may be modified by administrators, their owner and principals of their label.
+type Policy struct {
+
+	// The policy configuration.
+	config Config
+}
+
+// Description:
+//
+//	A denied authorization, answered with 403.
+type Denial struct {
+
+	// The reason of the denial.
+	Reason string
+}
+
+// Description:
+//
+//	Creates an album authorization policy.
+//
+// Parameters:
+//
+//	config The policy configuration.
+//
+// Returns:
+//
+//	The created policy.
+func New(config Config) *Policy {
+	return &Policy{config: config}
+}
+
+// Description:
+//
+//	Gets the reason of the denial.
+//
+// Returns:
+//
+//	The reason.
+func (denial *Denial) Error() string {
+	return denial.Reason
+}
+
+// Description:
+//
+//	Gets the label of a principal.
+//
+// Parameters:
+//
+//	principal The principal, or nil.
+//
+// Returns:
+//
+//	The label, or an empty string if the principal belongs to no label.
+func (policy *Policy) Label(principal *api.Principal) string {
+	if principal == nil || policy.config.LabelClaim == "" {
+		return ""
+	}
+
+	label, _ := principal.Claims[policy.config.LabelClaim].(string)
+	return label
+}
+
+// Description:
+//
+//	Checks whether a principal may assign a new album to the given label.
+//	Administrators may assign any label, other principals only their own.
+//
+// Parameters:
+//
+//	principal 	The principal, or nil for anonymous callers.
+//	label 		The label of the album, or an empty string.
+//
+// Returns:
+//
+//	A *Denial if the assignment is not allowed.
+func (policy *Policy) CanAssign(principal *api.Principal, label string) error {
+	if !policy.config.Enforced || label == "" || policy.isAdmin(principal) {
+		return nil
+	}
+
+	if label != policy.Label(principal) {
+		return &Denial{Reason: fmt.Sprintf("not allowed to assign albums to label %s", label)}
+	}
+
+	return nil
+}
+
+// Description:
+//
+//	Checks whether a principal may modify or delete an album.
+//
+// Parameters:
+//
+//	principal 	The principal, or nil for anonymous callers.
+//	album 		The album.
+//
+// Returns:
+//
+//	A *Denial if the modification is not allowed.
+func (policy *Policy) CanModify(principal *api.Principal, album *models.AlbumInfo) error {
+	if !policy.config.Enforced || policy.isAdmin(principal) {
+		return nil
+	}
+
+	if principal == nil {
+		return &Denial{Reason: "authentication required"}
+	}
+
+	if album.Owner != "" && album.Owner == principal.Subject {
+		return nil
+	}
+
+	label := policy.Label(principal)
+	if album.Label != "" && album.Label == label {
+		return nil
+	}
+
+	return &Denial{Reason: "album is owned by another principal"}
+}
+
+// Description:
+//
+//	Restricts a query to the albums visible to a principal.
+//	Restricted principals only see albums they or their label own, everybody else sees all albums.
+//
+// Parameters:
+//
+//	principal 	The principal, or nil for anonymous callers.
+//	root 		The root filter of the query, or nil.
+//
+// Returns:
+//
+//	The restricted root filter.
+func (policy *Policy) Scope(principal *api.Principal, root query.IQuery) query.IQuery {
+	if !policy.config.Enforced || principal == nil || !principal.HasRole(policy.config.RestrictedRole) || policy.isAdmin(principal) {
+		return root
+	}
+
+	visible := query.FilterOperatorOr{
+		Or: []query.IQuery{
+			query.FilterOperatorEq{
+				Key:   "owner",
+				Value: principal.Subject,
+			},
+		},
+	}
+
+	if label := policy.Label(principal); label != "" {
+		visible.Or = append(visible.Or, query.FilterOperatorEq{
+			Key:   "label",
+			Value: label,
+		})
+	}
+
+	if root == nil {
+		return visible
+	}
+
+	return query.FilterOperatorAnd{
+		And: []query.IQuery{root, visible},
+	}
+}
+
+// Description:
+//
+//	Creates the response for a denied authorization.
+//
+// Parameters:
+//
+//	request The denied request.
+//	err 	The denial.
+//
+// Returns:
+//
+//	The 403 response.
+func Forbidden(request *api.APIRequest, err error) *api.APIResponse {
+	return &api.APIResponse{
+		StatusCode: http.StatusForbidden,
+		Body: api.ErrorResponseBody{
+			Message:   err.Error(),
+			RequestID: request.RequestID,
+		},
+	}
+}
+
+// Description:
+//
+//	Checks whether a principal is an administrator.
+//
+// Parameters:
+//
+//	principal The principal, or nil.
+//
+// Returns:
+//
+//	Whether the principal has the admin role.
+func (policy *Policy) isAdmin(principal *api.Principal) bool {
+	return principal != nil && policy.config.AdminRole != "" && principal.HasRole(policy.config.AdminRole)
+}
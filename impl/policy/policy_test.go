@@ -0,0 +1,199 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gostream-official/albums/impl/models"
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/store/query"
+	"github.com/gostream-official/albums/pkg/store/storetest"
+)
+
+// Description:
+//
+//	The configuration of the enforced test policy.
+var enforced = Config{
+	Enforced:       true,
+	AdminRole:      "admin",
+	RestrictedRole: "label",
+	LabelClaim:     "label",
+}
+
+// Description:
+//
+//	Creates a principal.
+//
+// Parameters:
+//
+//	subject The subject.
+//	label 	The label of the principal, or an empty string.
+//	roles 	The roles of the principal.
+//
+// Returns:
+//
+//	The principal.
+func principal(subject string, label string, roles ...string) *api.Principal {
+	claims := map[string]interface{}{}
+	if label != "" {
+		claims["label"] = label
+	}
+
+	return &api.Principal{Subject: subject, Roles: roles, Claims: claims}
+}
+
+// Description:
+//
+//	Finds the ids of the albums matching a root filter.
+//
+// Parameters:
+//
+//	t 		The test.
+//	root 	The root filter, or nil.
+//
+// Returns:
+//
+//	The sorted ids of the matching albums.
+func find(t *testing.T, root query.IQuery) []string {
+	albums := storetest.NewMemoryStore(
+		models.AlbumInfo{ID: "own", Title: "a", Owner: "alice"},
+		models.AlbumInfo{ID: "label", Title: "a", Owner: "carol", Label: "north"},
+		models.AlbumInfo{ID: "other", Title: "b", Owner: "bob", Label: "south"},
+		models.AlbumInfo{ID: "unowned", Title: "a"},
+	)
+
+	items, err := albums.FindItems(context.Background(), &query.Filter{Root: root})
+	if err != nil {
+		t.Fatalf("failed to find albums: %s", err)
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+func TestLabel(t *testing.T) {
+	policy := New(enforced)
+
+	if label := policy.Label(principal("alice", "north")); label != "north" {
+		t.Fatalf("expected label north, got %q", label)
+	}
+
+	if label := policy.Label(nil); label != "" {
+		t.Fatalf("expected no label for anonymous callers, got %q", label)
+	}
+
+	if label := New(Config{Enforced: true}).Label(principal("alice", "north")); label != "" {
+		t.Fatalf("expected no label without a label claim, got %q", label)
+	}
+}
+
+func TestCanAssign(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    Config
+		principal *api.Principal
+		label     string
+		allowed   bool
+	}{
+		{"unenforced", Config{}, principal("alice", "north"), "south", true},
+		{"no label", enforced, nil, "", true},
+		{"own label", enforced, principal("alice", "north"), "north", true},
+		{"other label", enforced, principal("alice", "north"), "south", false},
+		{"anonymous", enforced, nil, "north", false},
+		{"admin", enforced, principal("root", "", "admin"), "south", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := New(test.config).CanAssign(test.principal, test.label)
+			if (err == nil) != test.allowed {
+				t.Fatalf("expected allowed %t, got %v", test.allowed, err)
+			}
+		})
+	}
+}
+
+func TestCanModify(t *testing.T) {
+	album := &models.AlbumInfo{ID: "1", Owner: "carol", Label: "north"}
+	unowned := &models.AlbumInfo{ID: "2"}
+
+	tests := []struct {
+		name      string
+		config    Config
+		principal *api.Principal
+		album     *models.AlbumInfo
+		allowed   bool
+	}{
+		{"unenforced", Config{}, nil, album, true},
+		{"anonymous", enforced, nil, album, false},
+		{"owner", enforced, principal("carol", ""), album, true},
+		{"same label", enforced, principal("alice", "north"), album, true},
+		{"other label", enforced, principal("bob", "south"), album, false},
+		{"unowned", enforced, principal("alice", ""), unowned, false},
+		{"admin", enforced, principal("root", "", "admin"), album, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := New(test.config).CanModify(test.principal, test.album)
+			if (err == nil) != test.allowed {
+				t.Fatalf("expected allowed %t, got %v", test.allowed, err)
+			}
+
+			var denial *Denial
+			if err != nil && !errors.As(err, &denial) {
+				t.Fatalf("expected a *Denial, got %T", err)
+			}
+		})
+	}
+}
+
+func TestScope(t *testing.T) {
+	title := query.FilterOperatorEq{Key: "title", Value: "a"}
+
+	tests := []struct {
+		name      string
+		config    Config
+		principal *api.Principal
+		root      query.IQuery
+		expected  []string
+	}{
+		{"unenforced", Config{RestrictedRole: "label"}, principal("alice", "north", "label"), nil, []string{"label", "other", "own", "unowned"}},
+		{"anonymous", enforced, nil, nil, []string{"label", "other", "own", "unowned"}},
+		{"unrestricted", enforced, principal("alice", "north"), nil, []string{"label", "other", "own", "unowned"}},
+		{"restricted", enforced, principal("alice", "north", "label"), nil, []string{"label", "own"}},
+		{"restricted without label", enforced, principal("alice", "", "label"), nil, []string{"own"}},
+		{"restricted with filter", enforced, principal("bob", "north", "label"), title, []string{"label"}},
+		{"admin", enforced, principal("root", "", "label", "admin"), title, []string{"label", "own", "unowned"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ids := find(t, New(test.config).Scope(test.principal, test.root))
+			if strings.Join(ids, ",") != strings.Join(test.expected, ",") {
+				t.Fatalf("expected albums %v, got %v", test.expected, ids)
+			}
+		})
+	}
+}
+
+func TestForbidden(t *testing.T) {
+	response := Forbidden(&api.APIRequest{RequestID: "request"}, &Denial{Reason: "denied"})
+	if response.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d", response.StatusCode)
+	}
+
+	body, ok := response.Body.(api.ErrorResponseBody)
+	if !ok || body.Message != "denied" || body.RequestID != "request" {
+		t.Fatalf("unexpected body %+v", response.Body)
+	}
+}
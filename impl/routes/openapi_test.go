@@ -0,0 +1,107 @@
+package routes
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gostream-official/albums/impl/config"
+	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/pkg/auth"
+	"github.com/gostream-official/albums/pkg/openapi"
+	"github.com/gostream-official/albums/pkg/router"
+)
+
+// Description:
+//
+//	Registers the routes of the service with the default configuration, like the OpenAPI generator does.
+//
+// Parameters:
+//
+//	t The test.
+//
+// Returns:
+//
+//	The router and the service configuration.
+func registerDefaults(t *testing.T) (router.Router, *config.Config) {
+	serviceConfig, err := config.Defaults()
+	if err != nil {
+		t.Fatalf("failed to load default configuration: %s", err)
+	}
+
+	engine := router.New(router.DefaultConfig())
+	err = Register(engine, auth.NewGuard("albums"), inject.New(nil), serviceConfig)
+	if err != nil {
+		t.Fatalf("failed to register routes: %s", err)
+	}
+
+	return engine, serviceConfig
+}
+
+func TestDocumentMatchesCheckedInSpecification(t *testing.T) {
+	engine, serviceConfig := registerDefaults(t)
+
+	generated, err := openapi.Encode(Document(engine, serviceConfig))
+	if err != nil {
+		t.Fatalf("failed to encode specification: %s", err)
+	}
+
+	existing, err := os.ReadFile("../../docs/openapi.json")
+	if err != nil {
+		t.Fatalf("failed to read specification: %s", err)
+	}
+
+	if !bytes.Equal(existing, generated) {
+		t.Fatalf("docs/openapi.json is outdated, regenerate it with: go run ./cmd/openapi")
+	}
+}
+
+func TestDocsAreServedOffline(t *testing.T) {
+	engine, serviceConfig := registerDefaults(t)
+
+	err := RegisterOpenAPI(engine, serviceConfig)
+	if err != nil {
+		t.Fatalf("failed to register openapi routes: %s", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		engine.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return recorder
+	}
+
+	page := get(serviceConfig.OpenAPI.DocsPath)
+	if page.Code != http.StatusOK {
+		t.Fatalf("expected the docs page, got status %d", page.Code)
+	}
+
+	if strings.Contains(page.Body.String(), "http://") || strings.Contains(page.Body.String(), "https://") {
+		t.Fatalf("expected the docs page to load no external resources, got %s", page.Body.String())
+	}
+
+	references := regexp.MustCompile(`(?:src|href)="([^"]+)"`).FindAllStringSubmatch(page.Body.String(), -1)
+	if len(references) != 2 {
+		t.Fatalf("expected a script and a stylesheet, got %v", references)
+	}
+
+	for _, reference := range references {
+		asset := get(reference[1])
+
+		if asset.Code != http.StatusOK || asset.Body.Len() == 0 {
+			t.Fatalf("expected asset %s to be served, got status %d", reference[1], asset.Code)
+		}
+
+		if strings.Contains(asset.Body.String(), "https://") {
+			t.Fatalf("expected asset %s to load no external resources", reference[1])
+		}
+	}
+
+	if missing := get(serviceConfig.OpenAPI.DocsPath + "/assets/docs.html"); missing.Code != http.StatusNotFound {
+		t.Fatalf("expected only scripts and stylesheets to be served, got status %d", missing.Code)
+	}
+}
@@ -0,0 +1,202 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gostream-official/albums/impl/config"
+	"github.com/gostream-official/albums/impl/funcs/createalbum"
+	"github.com/gostream-official/albums/impl/funcs/createapikey"
+	"github.com/gostream-official/albums/impl/funcs/deletealbum"
+	"github.com/gostream-official/albums/impl/funcs/getalbum"
+	"github.com/gostream-official/albums/impl/funcs/getalbums"
+	"github.com/gostream-official/albums/impl/funcs/getalbumtracks"
+	"github.com/gostream-official/albums/impl/funcs/getapikeys"
+	"github.com/gostream-official/albums/impl/funcs/revokeapikey"
+	"github.com/gostream-official/albums/impl/funcs/rotateapikey"
+	"github.com/gostream-official/albums/impl/funcs/updatealbum"
+	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/pkg/auth"
+	"github.com/gostream-official/albums/pkg/marshal"
+	"github.com/gostream-official/albums/pkg/openapi"
+	"github.com/gostream-official/albums/pkg/ratelimit"
+	"github.com/gostream-official/albums/pkg/router"
+)
+
+const (
+
+	// The title of the API.
+	Title = "Albums API"
+
+	// The version of the API.
+	APIVersion = "1.0.0"
+)
+
+// Description:
+//
+//	Registers the API routes of this service.
+//	Shared by the service and the OpenAPI generator, so that the checked-in specification matches the service.
+//
+// Parameters:
+//
+//	engine 		The router.
+//	guard 		The authentication guard.
+//	injector 	The injector passed to the handlers.
+//	config 		The service configuration.
+//
+// Returns:
+//
+//	An error if the rate limits are invalid.
+func Register(engine router.Router, guard *auth.Guard, injector *inject.Injector, config *config.Config) error {
+	albums := engine.Group("/albums")
+	keys := engine.Group("/apikeys")
+
+	// Requests are limited per client IP before authentication as well, so that requests
+	// with invalid credentials are limited, before they cost a lookup of the credentials.
+	if config.RateLimit.Enabled {
+		ipLimit := ratelimit.Limit{
+			Requests: config.RateLimit.IPRequests,
+			Period:   config.RateLimit.Period,
+		}
+
+		ipLimiter, err := ratelimit.Middleware(ratelimit.NewMemoryBackend(injector.Clock), ratelimit.Policy{
+			Read:  ipLimit,
+			Write: ipLimit,
+			Key:   ratelimit.ByClientIP(),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		albums.Use(ipLimiter)
+		keys.Use(ipLimiter)
+	}
+
+	albums.Use(guard.Authenticate())
+
+	if config.RateLimit.Enabled {
+		limiter, err := ratelimit.Middleware(ratelimit.NewMemoryBackend(injector.Clock), ratelimit.Policy{
+			Read: ratelimit.Limit{
+				Requests: config.RateLimit.ReadRequests,
+				Period:   config.RateLimit.Period,
+			},
+			Write: ratelimit.Limit{
+				Requests: config.RateLimit.WriteRequests,
+				Period:   config.RateLimit.Period,
+			},
+			Key: ratelimit.FirstOf(
+				ratelimit.BySubject(),
+				ratelimit.ByHeader(config.RateLimit.KeyHeader),
+				ratelimit.ByClientIP(),
+			),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		albums.Use(limiter)
+	}
+
+	router.HandleWith(albums, "GET", "/", getalbums.Handler, injector).Document(getalbums.Doc)
+	router.HandleWith(albums, "GET", "/:id", getalbum.Handler, injector).Document(getalbum.Doc)
+	router.HandleWith(albums, "GET", "/:id/tracks", getalbumtracks.Handler, injector).Document(getalbumtracks.Doc)
+
+	writes := []*router.Route{
+		router.HandleWith(albums, "POST", "/", createalbum.Handler, injector).Document(createalbum.Doc),
+		router.HandleWith(albums, "PUT", "/:id", updatealbum.Handler, injector).Document(updatealbum.Doc),
+		router.HandleWith(albums, "DELETE", "/:id", deletealbum.Handler, injector).Document(deletealbum.Doc),
+	}
+
+	if config.Auth.Enabled {
+		for _, route := range writes {
+			route.Use(guard.RequireScopes(config.Auth.WriteScope)).Secured(config.Auth.WriteScope)
+		}
+	}
+
+	// API keys are managed by administrators only, independent of whether writes require authentication.
+	keys.Use(guard.Authenticate(), guard.RequireRoles(config.Auth.AdminRole))
+
+	router.HandleWith(keys, "GET", "/", getapikeys.Handler, injector).Document(getapikeys.Doc).Secured()
+	router.HandleWith(keys, "POST", "/", createapikey.Handler, injector).Document(createapikey.Doc).Secured()
+	router.HandleWith(keys, "POST", "/:id/rotate", rotateapikey.Handler, injector).Document(rotateapikey.Doc).Secured()
+	router.HandleWith(keys, "DELETE", "/:id", revokeapikey.Handler, injector).Document(revokeapikey.Doc).Secured()
+
+	return nil
+}
+
+// Description:
+//
+//	Serves the OpenAPI specification of all routes registered so far, and its documentation page with its assets.
+//	All routes are hidden from the specification.
+//
+// Parameters:
+//
+//	engine 	The router.
+//	config 	The service configuration.
+//
+// Returns:
+//
+//	An error if the specification cannot be encoded.
+func RegisterOpenAPI(engine router.Router, config *config.Config) error {
+	specHandler, err := openapi.Handler(Document(engine, config))
+	if err != nil {
+		return err
+	}
+
+	assetsPath := strings.TrimSuffix(config.OpenAPI.DocsPath, "/") + "/assets"
+
+	docsHandler, err := openapi.DocsHandler(Title, config.OpenAPI.Path, assetsPath)
+	if err != nil {
+		return err
+	}
+
+	engine.Handle(http.MethodGet, config.OpenAPI.Path, specHandler).Hidden()
+	engine.Handle(http.MethodGet, config.OpenAPI.DocsPath, docsHandler).Hidden()
+	engine.Handle(http.MethodGet, assetsPath+"/:name", openapi.AssetsHandler()).Hidden()
+
+	return nil
+}
+
+// Description:
+//
+//	Generates the OpenAPI specification of the registered routes.
+//
+// Parameters:
+//
+//	engine 	The router.
+//	config 	The service configuration.
+//
+// Returns:
+//
+//	The OpenAPI document.
+func Document(engine router.Router, config *config.Config) *openapi.Document {
+	mediaTypes := make([]string, 0)
+	for _, codec := range marshal.DefaultCodecs().All() {
+		mediaTypes = append(mediaTypes, codec.MediaTypes()[0])
+	}
+
+	return openapi.Generate(openapi.Config{
+		Info: openapi.Info{
+			Title:       Title,
+			Description: "Manages the albums of the gostream catalogue.",
+			Version:     APIVersion,
+		},
+		MediaTypes: mediaTypes,
+		SecuritySchemes: map[string]openapi.SecurityScheme{
+			"bearer": {
+				Type:         "http",
+				Scheme:       "bearer",
+				BearerFormat: "JWT",
+				Description:  "A JWT granting scopes via its scope or scp claim, and roles via its roles claim.",
+			},
+			"apiKey": {
+				Type:        "apiKey",
+				Name:        config.Auth.APIKeyHeader,
+				In:          "header",
+				Description: "An API key, acting on behalf of its owner with the scopes granted on creation.",
+			},
+		},
+	}, engine.Routes())
+}
@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gostream-official/albums/impl/apikeys"
+	"github.com/gostream-official/albums/impl/config"
+	"github.com/gostream-official/albums/impl/inject"
+	"github.com/gostream-official/albums/impl/models"
+	"github.com/gostream-official/albums/pkg/auth"
+	"github.com/gostream-official/albums/pkg/router"
+	"github.com/gostream-official/albums/pkg/store/storetest"
+)
+
+func TestInvalidCredentialsAreRateLimited(t *testing.T) {
+	serviceConfig, err := config.Defaults()
+	if err != nil {
+		t.Fatalf("failed to load default configuration: %s", err)
+	}
+
+	serviceConfig.RateLimit.IPRequests = 3
+
+	keys := storetest.NewMemoryStore[models.APIKeyInfo]()
+	injector := inject.New(nil,
+		inject.WithAlbumStore(storetest.NewMemoryStore[models.AlbumInfo]()),
+		inject.WithAPIKeyStore(keys),
+	)
+
+	guard := auth.NewGuard("albums", auth.APIKeyAuthenticator(serviceConfig.Auth.APIKeyHeader, apikeys.NewResolver(injector)))
+
+	engine := router.New(router.DefaultConfig())
+	err = Register(engine, guard, injector, serviceConfig)
+	if err != nil {
+		t.Fatalf("failed to register routes: %s", err)
+	}
+
+	statusCodes := make([]int, 0)
+	for index := 0; index < 5; index++ {
+		request := httptest.NewRequest(http.MethodGet, "/albums", nil)
+		request.RemoteAddr = "192.0.2.1:1234"
+		request.Header.Set(serviceConfig.Auth.APIKeyHeader, "alb_guessed")
+
+		recorder := httptest.NewRecorder()
+		engine.Handler().ServeHTTP(recorder, request)
+
+		statusCodes = append(statusCodes, recorder.Code)
+	}
+
+	expected := []int{401, 401, 401, 429, 429}
+	for index := range expected {
+		if statusCodes[index] != expected[index] {
+			t.Fatalf("expected status codes %v, got %v", expected, statusCodes)
+		}
+	}
+
+	// Limited requests never reach the API key lookup.
+	if calls := keys.Calls("find"); calls != 3 {
+		t.Fatalf("expected 3 key lookups, got %d", calls)
+	}
+
+	// Other clients are not affected.
+	request := httptest.NewRequest(http.MethodGet, "/albums", nil)
+	request.RemoteAddr = "192.0.2.2:1234"
+
+	recorder := httptest.NewRecorder()
+	engine.Handler().ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for another client, got %d", recorder.Code)
+	}
+}
+
+func TestInvalidRateLimitsAreRejected(t *testing.T) {
+	serviceConfig, err := config.Defaults()
+	if err != nil {
+		t.Fatalf("failed to load default configuration: %s", err)
+	}
+
+	serviceConfig.RateLimit.Period = 0
+
+	err = Register(router.New(router.DefaultConfig()), auth.NewGuard("albums"), inject.New(nil), serviceConfig)
+	if err == nil {
+		t.Fatal("expected a zero rate limit period to be rejected")
+	}
+}
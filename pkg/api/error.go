@@ -0,0 +1,21 @@
+package api
+
+import "errors"
+
+// Description:
+//
+//	The error returned when reading a request body exceeding the body limit of its route.
+var ErrBodyTooLarge = errors.New("api: request body too large")
+
+// Description:
+//
+//	The generic error response body.
+//	Used for errors which are not produced by a handler, e.g. recovered panics.
+type ErrorResponseBody struct {
+
+	// The error message.
+	Message string `json:"message" xml:"message"`
+
+	// The id of the failed request, used to correlate logs.
+	RequestID string `json:"requestId,omitempty" xml:"requestId,omitempty"`
+}
@@ -0,0 +1,91 @@
+package api
+
+// Description:
+//
+//	The identity of a client, verified via its TLS client certificate.
+type ClientIdentity struct {
+
+	// The common name of the certificate subject.
+	CommonName string `json:"commonName"`
+
+	// The organizations of the certificate subject.
+	Organizations []string `json:"organizations"`
+
+	// The DNS subject alternative names.
+	DNSNames []string `json:"dnsNames"`
+
+	// The URI subject alternative names, e.g. SPIFFE IDs.
+	URIs []string `json:"uris"`
+
+	// The serial number of the certificate.
+	SerialNumber string `json:"serialNumber"`
+
+	// The hex encoded SHA-256 fingerprint of the certificate.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Description:
+//
+//	An authenticated caller, e.g. the subject of a verified JWT.
+type Principal struct {
+
+	// The subject, i.e. the unique id of the caller.
+	Subject string `json:"subject"`
+
+	// The issuer of the credentials.
+	Issuer string `json:"issuer,omitempty"`
+
+	// The authentication method, e.g. jwt.
+	Method string `json:"method"`
+
+	// The granted scopes, e.g. albums:write.
+	Scopes []string `json:"scopes,omitempty"`
+
+	// The granted roles, e.g. admin.
+	Roles []string `json:"roles,omitempty"`
+
+	// All claims of the credentials.
+	Claims map[string]interface{} `json:"-"`
+}
+
+// Description:
+//
+//	Checks whether the principal was granted the given scope.
+//
+// Parameters:
+//
+//	scope The scope, e.g. albums:write.
+//
+// Returns:
+//
+//	Whether the scope was granted.
+func (principal *Principal) HasScope(scope string) bool {
+	for _, granted := range principal.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Description:
+//
+//	Checks whether the principal was granted the given role.
+//
+// Parameters:
+//
+//	role The role, e.g. admin.
+//
+// Returns:
+//
+//	Whether the role was granted.
+func (principal *Principal) HasRole(role string) bool {
+	for _, granted := range principal.Roles {
+		if granted == role {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,328 @@
+package api
+
+import (
+	"fmt"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+
+	// The location of path parameters.
+	ParameterLocationPath = "path"
+
+	// The location of query parameters.
+	ParameterLocationQuery = "query"
+
+	// The location of headers.
+	ParameterLocationHeader = "header"
+)
+
+// Description:
+//
+//	A validation error of a path parameter, query parameter or header.
+//	Can be used as response body.
+type ParameterError struct {
+
+	// The location of the parameter: path, query or header.
+	Location string `json:"location" xml:"location"`
+
+	// The name of the parameter.
+	Name string `json:"name" xml:"name"`
+
+	// The invalid value.
+	Value string `json:"value" xml:"value"`
+
+	// The error message.
+	Message string `json:"error" xml:"error"`
+}
+
+// Description:
+//
+//	Formats the parameter error.
+//
+// Returns:
+//
+//	The error message.
+func (err *ParameterError) Error() string {
+	return fmt.Sprintf("invalid %s parameter %s: %s", err.Location, err.Name, err.Message)
+}
+
+// Description:
+//
+//	Gets all values of a header. The lookup is case-insensitive.
+//
+// Parameters:
+//
+//	name The name of the header.
+//
+// Returns:
+//
+//	All values in order, or nil if the header is not set.
+func (request *APIRequest) HeaderValues(name string) []string {
+	if request.MultiValueHeaders != nil {
+		values, ok := request.MultiValueHeaders[textproto.CanonicalMIMEHeaderKey(name)]
+		if ok {
+			return values
+		}
+
+		for key, values := range request.MultiValueHeaders {
+			if strings.EqualFold(key, name) {
+				return values
+			}
+		}
+
+		return nil
+	}
+
+	// Requests created without multi-valued headers, e.g. in tests.
+	for key, value := range request.Headers {
+		if strings.EqualFold(key, name) {
+			return []string{value}
+		}
+	}
+
+	return nil
+}
+
+// Description:
+//
+//	Gets the first value of a header. The lookup is case-insensitive.
+//
+// Parameters:
+//
+//	name The name of the header.
+//
+// Returns:
+//
+//	The first value, or an empty string if the header is not set.
+func (request *APIRequest) Header(name string) string {
+	values := request.HeaderValues(name)
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// Description:
+//
+//	Gets all values of a query parameter, e.g. [a b] for ?trackId=a&trackId=b.
+//
+// Parameters:
+//
+//	name The name of the query parameter.
+//
+// Returns:
+//
+//	All values in order, or nil if the parameter is not set.
+func (request *APIRequest) QueryValues(name string) []string {
+	if request.MultiValueQueryParameters != nil {
+		return request.MultiValueQueryParameters[name]
+	}
+
+	// Requests created without multi-valued query parameters, e.g. in tests.
+	value, ok := request.QueryParameters[name]
+	if !ok {
+		return nil
+	}
+
+	return []string{value}
+}
+
+// Description:
+//
+//	Gets the first value of a query parameter.
+//
+// Parameters:
+//
+//	name The name of the query parameter.
+//
+// Returns:
+//
+//	The first value, and whether the parameter is set.
+func (request *APIRequest) Query(name string) (string, bool) {
+	values := request.QueryValues(name)
+
+	if len(values) == 0 {
+		return "", false
+	}
+
+	return values[0], true
+}
+
+// Description:
+//
+//	Parses a query parameter as integer.
+//
+// Parameters:
+//
+//	name 		The name of the query parameter.
+//	fallback 	The value returned if the parameter is not set.
+//
+// Returns:
+//
+//	The parsed value, or a *ParameterError if the value is not an integer.
+func (request *APIRequest) QueryInt(name string, fallback int) (int, error) {
+	value, ok := request.Query(name)
+	if !ok {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fallback, newParameterError(ParameterLocationQuery, name, value, "value must be an integer")
+	}
+
+	return parsed, nil
+}
+
+// Description:
+//
+//	Parses a query parameter as boolean, e.g. true, false, 1 or 0.
+//
+// Parameters:
+//
+//	name 		The name of the query parameter.
+//	fallback 	The value returned if the parameter is not set.
+//
+// Returns:
+//
+//	The parsed value, or a *ParameterError if the value is not a boolean.
+func (request *APIRequest) QueryBool(name string, fallback bool) (bool, error) {
+	value, ok := request.Query(name)
+	if !ok {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback, newParameterError(ParameterLocationQuery, name, value, "value must be a boolean")
+	}
+
+	return parsed, nil
+}
+
+// Description:
+//
+//	Parses a query parameter as RFC 3339 timestamp, e.g. 2023-06-01T12:00:00Z.
+//
+// Parameters:
+//
+//	name 		The name of the query parameter.
+//	fallback 	The value returned if the parameter is not set.
+//
+// Returns:
+//
+//	The parsed value, or a *ParameterError if the value is not a timestamp.
+func (request *APIRequest) QueryTime(name string, fallback time.Time) (time.Time, error) {
+	value, ok := request.Query(name)
+	if !ok {
+		return fallback, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return fallback, newParameterError(ParameterLocationQuery, name, value, "value must be an RFC 3339 timestamp")
+	}
+
+	return parsed, nil
+}
+
+// Description:
+//
+//	Validates all values of a query parameter as UUIDs.
+//
+// Parameters:
+//
+//	name The name of the query parameter.
+//
+// Returns:
+//
+//	All values in order, or a *ParameterError for the first value which is not a UUID.
+func (request *APIRequest) QueryUUIDs(name string) ([]string, error) {
+	values := request.QueryValues(name)
+
+	for _, value := range values {
+		if _, err := uuid.Parse(value); err != nil {
+			return nil, newParameterError(ParameterLocationQuery, name, value, "value must be a valid uuid")
+		}
+	}
+
+	return values, nil
+}
+
+// Description:
+//
+//	Validates a path parameter as UUID.
+//
+// Parameters:
+//
+//	name The name of the path parameter.
+//
+// Returns:
+//
+//	The value, or a *ParameterError if the value is not a UUID.
+func (request *APIRequest) PathUUID(name string) (string, error) {
+	value := request.PathParameters[name]
+
+	if _, err := uuid.Parse(value); err != nil {
+		return "", newParameterError(ParameterLocationPath, name, value, "value must be a valid uuid")
+	}
+
+	return value, nil
+}
+
+// Description:
+//
+//	Parses a header as integer.
+//
+// Parameters:
+//
+//	name 		The name of the header.
+//	fallback 	The value returned if the header is not set.
+//
+// Returns:
+//
+//	The parsed value, or a *ParameterError if the value is not an integer.
+func (request *APIRequest) HeaderInt(name string, fallback int) (int, error) {
+	value := request.Header(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return fallback, newParameterError(ParameterLocationHeader, name, value, "value must be an integer")
+	}
+
+	return parsed, nil
+}
+
+// Description:
+//
+//	Creates a parameter error.
+//
+// Parameters:
+//
+//	location 	The location of the parameter.
+//	name 		The name of the parameter.
+//	value 		The invalid value.
+//	message 	The error message.
+//
+// Returns:
+//
+//	The created parameter error.
+func newParameterError(location string, name string, value string, message string) *ParameterError {
+	return &ParameterError{
+		Location: location,
+		Name:     name,
+		Value:    value,
+		Message:  message,
+	}
+}
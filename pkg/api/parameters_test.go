@@ -0,0 +1,220 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+
+	// A valid uuid.
+	testUUID = "0b6d8a1c-6f2e-4f5a-9d43-2c1e8f7a9b10"
+
+	// Another valid uuid.
+	otherTestUUID = "5f0c2a9e-3b7d-4e1a-8c6f-9d2b4a7e1c03"
+)
+
+// Description:
+//
+//	Creates a request with the given path parameters, query parameters and headers,
+//	holding every value as the router would.
+//
+// Parameters:
+//
+//	path 	The path parameters.
+//	query 	The query parameters.
+//	headers The headers, keyed by their canonical names.
+//
+// Returns:
+//
+//	The created request.
+func newParameterRequest(path map[string]string, query map[string][]string, headers map[string][]string) *APIRequest {
+	return &APIRequest{
+		PathParameters:            path,
+		MultiValueQueryParameters: query,
+		MultiValueHeaders:         headers,
+	}
+}
+
+// Description:
+//
+//	Checks that an error is a parameter error of the given location and name.
+//
+// Parameters:
+//
+//	t 			The test.
+//	err 		The error.
+//	location 	The expected location.
+//	name 		The expected parameter name.
+func expectParameterError(t *testing.T, err error, location string, name string) {
+	t.Helper()
+
+	var parameterErr *ParameterError
+	if !errors.As(err, &parameterErr) {
+		t.Fatalf("expected a parameter error, got %v", err)
+	}
+
+	if parameterErr.Location != location || parameterErr.Name != name {
+		t.Errorf("expected an error of %s parameter %s, got %s parameter %s", location, name, parameterErr.Location, parameterErr.Name)
+	}
+}
+
+func TestHeaderAccessors(t *testing.T) {
+	request := newParameterRequest(nil, nil, map[string][]string{
+		"Accept":      {"application/json", "application/xml"},
+		"X-Page-Size": {" 25 "},
+		"X-Offset":    {"ten"},
+	})
+
+	if values := request.HeaderValues("accept"); len(values) != 2 || values[1] != "application/xml" {
+		t.Errorf("expected both accept values, got %v", values)
+	}
+
+	if value := request.Header("ACCEPT"); value != "application/json" {
+		t.Errorf("expected the first accept value, got %q", value)
+	}
+
+	if value := request.Header("X-Missing"); value != "" {
+		t.Errorf("expected no value, got %q", value)
+	}
+
+	if size, err := request.HeaderInt("x-page-size", 10); err != nil || size != 25 {
+		t.Errorf("expected page size 25, got %d, %v", size, err)
+	}
+
+	if size, err := request.HeaderInt("X-Missing", 10); err != nil || size != 10 {
+		t.Errorf("expected the fallback 10, got %d, %v", size, err)
+	}
+
+	offset, err := request.HeaderInt("X-Offset", 0)
+	expectParameterError(t, err, ParameterLocationHeader, "X-Offset")
+
+	if offset != 0 {
+		t.Errorf("expected the fallback 0 for a malformed header, got %d", offset)
+	}
+}
+
+func TestHeadersWithoutMultipleValues(t *testing.T) {
+	request := &APIRequest{Headers: map[string]string{"Content-Type": "application/json"}}
+
+	if value := request.Header("content-type"); value != "application/json" {
+		t.Errorf("expected the content type, got %q", value)
+	}
+}
+
+func TestQueryAccessors(t *testing.T) {
+	start := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	request := newParameterRequest(nil, map[string][]string{
+		"limit":   {"20", "30"},
+		"public":  {"1"},
+		"since":   {"2023-06-01T12:00:00Z"},
+		"trackId": {testUUID, otherTestUUID},
+	}, nil)
+
+	if value, ok := request.Query("limit"); !ok || value != "20" {
+		t.Errorf("expected the first limit, got %q, %t", value, ok)
+	}
+
+	if _, ok := request.Query("offset"); ok {
+		t.Error("expected offset to be unset")
+	}
+
+	if limit, err := request.QueryInt("limit", 10); err != nil || limit != 20 {
+		t.Errorf("expected limit 20, got %d, %v", limit, err)
+	}
+
+	if offset, err := request.QueryInt("offset", 5); err != nil || offset != 5 {
+		t.Errorf("expected the fallback 5, got %d, %v", offset, err)
+	}
+
+	if public, err := request.QueryBool("public", false); err != nil || !public {
+		t.Errorf("expected public, got %t, %v", public, err)
+	}
+
+	if since, err := request.QueryTime("since", time.Time{}); err != nil || !since.Equal(start) {
+		t.Errorf("expected %s, got %s, %v", start, since, err)
+	}
+
+	if ids, err := request.QueryUUIDs("trackId"); err != nil || len(ids) != 2 || ids[1] != otherTestUUID {
+		t.Errorf("expected both track ids, got %v, %v", ids, err)
+	}
+
+	if ids, err := request.QueryUUIDs("albumId"); err != nil || len(ids) != 0 {
+		t.Errorf("expected no album ids, got %v, %v", ids, err)
+	}
+}
+
+func TestMalformedQueryParameters(t *testing.T) {
+	request := newParameterRequest(nil, map[string][]string{
+		"limit":   {"twenty"},
+		"public":  {"maybe"},
+		"since":   {"2023-06-01"},
+		"trackId": {testUUID, "track-2"},
+	}, nil)
+
+	tests := []struct {
+		name   string
+		access func() error
+	}{
+		{name: "limit", access: func() error {
+			_, err := request.QueryInt("limit", 10)
+			return err
+		}},
+		{name: "public", access: func() error {
+			_, err := request.QueryBool("public", false)
+			return err
+		}},
+		{name: "since", access: func() error {
+			_, err := request.QueryTime("since", time.Time{})
+			return err
+		}},
+		{name: "trackId", access: func() error {
+			_, err := request.QueryUUIDs("trackId")
+			return err
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expectParameterError(t, test.access(), ParameterLocationQuery, test.name)
+		})
+	}
+}
+
+func TestQueryWithoutMultipleValues(t *testing.T) {
+	request := &APIRequest{QueryParameters: map[string]string{"limit": "20"}}
+
+	if limit, err := request.QueryInt("limit", 10); err != nil || limit != 20 {
+		t.Errorf("expected limit 20, got %d, %v", limit, err)
+	}
+}
+
+func TestPathUUID(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  map[string]string
+		valid bool
+	}{
+		{name: "valid", path: map[string]string{"id": testUUID}, valid: true},
+		{name: "missing", path: map[string]string{}},
+		{name: "empty", path: map[string]string{"id": ""}},
+		{name: "malformed", path: map[string]string{"id": "album-1"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, err := newParameterRequest(test.path, nil, nil).PathUUID("id")
+
+			if !test.valid {
+				expectParameterError(t, err, ParameterLocationPath, "id")
+				return
+			}
+
+			if err != nil || id != testUUID {
+				t.Errorf("expected id %s, got %q, %v", testUUID, id, err)
+			}
+		})
+	}
+}
@@ -1,5 +1,15 @@
 package api
 
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/marshal"
+)
+
 // Description:
 //
 //	The representation of a HTTP request.
@@ -16,14 +26,115 @@ type APIRequest struct {
 	Method string
 
 	// The request headers
+	// Repeated headers are joined with commas, see MultiValueHeaders for the single values.
 	Headers map[string]string `json:"headers"`
 
+	// The request headers, keyed by their canonical names, holding every value in order.
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+
 	// A key-value mapping of path parameters.
 	PathParameters map[string]string `json:"pathParameters"`
 
 	// A key-value mapping of query parameters.
+	// Repeated parameters are joined with commas, see MultiValueQueryParameters for the single values.
 	QueryParameters map[string]string `json:"queryParameters"`
 
+	// The query parameters, holding every value in order.
+	MultiValueQueryParameters map[string][]string `json:"multiValueQueryParameters"`
+
 	// The request body.
+	// Empty for routes streaming their body, see BodyReader.
 	Body string `json:"body"`
+
+	// The request body of routes streaming their body, or nil.
+	// Reading beyond the body limit of the route fails with ErrBodyTooLarge.
+	BodyReader io.Reader `json:"-"`
+
+	// The client identity verified via mutual TLS, or nil.
+	Client *ClientIdentity `json:"client,omitempty"`
+
+	// The authenticated caller, or nil for anonymous requests.
+	Principal *Principal `json:"principal,omitempty"`
+
+	// The id of the request, taken from the X-Request-ID header or generated.
+	RequestID string `json:"requestId"`
+
+	// The W3C trace context of the request, or nil.
+	Trace *TraceContext `json:"trace,omitempty"`
+
+	// The request context. Canceled when the client disconnects.
+	// Holds the request id, see parallel.IDFromContext.
+	Context context.Context `json:"-"`
+
+	// The client IP, honouring the headers of trusted proxies.
+	ClientIP string `json:"clientIp"`
+
+	// The request scoped logger.
+	// Attaches the request id, method and route to all entries.
+	Logger *logging.Logger `json:"-"`
+
+	// The codec matching the Content-Type header of the request, used by Decode.
+	Codec marshal.Codec `json:"-"`
+}
+
+// Description:
+//
+//	Decodes the request body into the given target, using the codec matching the Content-Type header.
+//	Falls back to JSON if the request has no codec.
+//	Streamed bodies are read entirely, see DecodeEach for incremental decoding.
+//
+// Parameters:
+//
+//	target A pointer to the target object.
+//
+// Returns:
+//
+//	An error if the body is empty or decoding fails.
+func (request *APIRequest) Decode(target interface{}) error {
+	data := []byte(request.Body)
+
+	if request.BodyReader != nil {
+		read, err := io.ReadAll(request.BodyReader)
+		if err != nil {
+			return err
+		}
+
+		data = read
+	}
+
+	if len(data) == 0 {
+		return fmt.Errorf("api: request body is empty")
+	}
+
+	return request.codec().Unmarshal(data, target)
+}
+
+// Description:
+//
+//	Gets the request body as a reader, regardless of whether the route streams its body.
+//
+// Returns:
+//
+//	The request body.
+func (request *APIRequest) Stream() io.Reader {
+	if request.BodyReader != nil {
+		return request.BodyReader
+	}
+
+	return strings.NewReader(request.Body)
+}
+
+// Description:
+//
+//	Gets the codec of the request, falling back to JSON.
+//
+// Returns:
+//
+//	The codec.
+func (request *APIRequest) codec() marshal.Codec {
+	if request.Codec == nil {
+		return marshal.JSONCodec{}
+	}
+
+	return request.Codec
 }
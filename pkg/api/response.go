@@ -1,5 +1,10 @@
 package api
 
+import (
+	"io"
+	"net/http"
+)
+
 // Description:
 //
 //	A representation of a HTTP response.
@@ -12,5 +17,157 @@ type APIResponse struct {
 	Headers map[string]string `json:"headers"`
 
 	// The response body, represented as an object.
+	// Encoded as JSON, unless it is a *RawBody, *StreamBody or *FileBody.
 	Body interface{} `json:"body"`
 }
+
+// Description:
+//
+//	A response body which is written as is, instead of being encoded as JSON.
+//	Used for non-JSON payloads, e.g. plain text.
+type RawBody struct {
+
+	// The content type of the body, e.g. text/plain.
+	ContentType string
+
+	// The body bytes.
+	Data []byte
+}
+
+// Description:
+//
+//	A response body which is streamed from a reader, e.g. a large export.
+//	The reader is closed after the response was written, if it implements io.Closer.
+type StreamBody struct {
+
+	// The content type of the body, e.g. text/csv.
+	ContentType string
+
+	// The reader providing the body.
+	Reader io.Reader
+
+	// The length of the body in bytes, or -1 if unknown.
+	ContentLength int64
+}
+
+// Description:
+//
+//	A response body which is served from a file.
+//	Supports range requests and conditional requests via If-Modified-Since.
+type FileBody struct {
+
+	// The path of the file.
+	Path string
+
+	// The file name offered for download. If set, the file is served as attachment.
+	Name string
+
+	// The content type of the file. Derived from the file extension if empty.
+	ContentType string
+}
+
+// Description:
+//
+//	Creates a response with a raw body.
+//
+// Parameters:
+//
+//	statusCode 	The response status code.
+//	contentType The content type of the body.
+//	data 		The body bytes.
+//
+// Returns:
+//
+//	The created response.
+func Raw(statusCode int, contentType string, data []byte) *APIResponse {
+	return &APIResponse{
+		StatusCode: statusCode,
+		Body: &RawBody{
+			ContentType: contentType,
+			Data:        data,
+		},
+	}
+}
+
+// Description:
+//
+//	Creates a response with a plain text body.
+//
+// Parameters:
+//
+//	statusCode 	The response status code.
+//	text 		The body text.
+//
+// Returns:
+//
+//	The created response.
+func Text(statusCode int, text string) *APIResponse {
+	return Raw(statusCode, "text/plain; charset=utf-8", []byte(text))
+}
+
+// Description:
+//
+//	Creates a response whose body is streamed from the given reader.
+//
+// Parameters:
+//
+//	statusCode 		The response status code.
+//	contentType 	The content type of the body.
+//	reader 			The reader providing the body. Closed after writing, if it implements io.Closer.
+//	contentLength 	The length of the body in bytes, or -1 if unknown.
+//
+// Returns:
+//
+//	The created response.
+func Stream(statusCode int, contentType string, reader io.Reader, contentLength int64) *APIResponse {
+	return &APIResponse{
+		StatusCode: statusCode,
+		Body: &StreamBody{
+			ContentType:   contentType,
+			Reader:        reader,
+			ContentLength: contentLength,
+		},
+	}
+}
+
+// Description:
+//
+//	Creates a response serving the given file.
+//	The status code is determined when serving, e.g. 206 for range requests.
+//
+// Parameters:
+//
+//	path The path of the file.
+//
+// Returns:
+//
+//	The created response.
+func File(path string) *APIResponse {
+	return &APIResponse{
+		StatusCode: http.StatusOK,
+		Body: &FileBody{
+			Path: path,
+		},
+	}
+}
+
+// Description:
+//
+//	Creates a redirect response.
+//
+// Parameters:
+//
+//	statusCode 	The redirect status code, e.g. 302 or 308.
+//	location 	The redirect target.
+//
+// Returns:
+//
+//	The created response.
+func Redirect(statusCode int, location string) *APIResponse {
+	return &APIResponse{
+		StatusCode: statusCode,
+		Headers: map[string]string{
+			"Location": location,
+		},
+	}
+}
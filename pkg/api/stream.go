@@ -0,0 +1,126 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gostream-official/albums/pkg/marshal"
+)
+
+// Description:
+//
+//	Decodes a list of items from the request body, passing every item to the given function.
+//	JSON bodies, either a single array or newline delimited objects, are decoded incrementally,
+//	so that large imports are never held in memory as a whole.
+//	Bodies of other codecs are decoded entirely before the items are passed on.
+//
+// Example:
+//
+//	err := api.DecodeEach(request, func(album *models.AlbumInfo) error {
+//		return store.CreateItem(request.Context, *album)
+//	})
+//
+// Parameters:
+//
+//	request The request.
+//	handle 	The function receiving each item. Stops decoding if it returns an error.
+//
+// Type Parameters:
+//
+//	T The type of the items.
+//
+// Returns:
+//
+//	An error if decoding fails or the function returns an error.
+func DecodeEach[T any](request *APIRequest, handle func(item *T) error) error {
+	if _, ok := request.codec().(marshal.JSONCodec); !ok {
+		items := make([]T, 0)
+
+		err := request.Decode(&items)
+		if err != nil {
+			return err
+		}
+
+		for index := range items {
+			err = handle(&items[index])
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	reader := bufio.NewReader(request.Stream())
+
+	array, err := startsWithArray(reader)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(reader)
+
+	if array {
+		// Consumes the opening bracket.
+		_, err = decoder.Token()
+		if err != nil {
+			return err
+		}
+	}
+
+	for !array || decoder.More() {
+		item := new(T)
+
+		// Newline delimited objects end with the body.
+		err = decoder.Decode(item)
+		if err == io.EOF && !array {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = handle(item)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Consumes the closing bracket.
+	_, err = decoder.Token()
+	return err
+}
+
+// Description:
+//
+//	Checks whether a JSON body starts with an array, skipping leading whitespace.
+//
+// Parameters:
+//
+//	reader The buffered body.
+//
+// Returns:
+//
+//	Whether the body is an array, or an error if the body is empty or cannot be read.
+func startsWithArray(reader *bufio.Reader) (bool, error) {
+	for {
+		next, err := reader.Peek(1)
+		if err == io.EOF {
+			return false, fmt.Errorf("api: request body is empty")
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		switch next[0] {
+		case ' ', '\t', '\r', '\n':
+			reader.Discard(1)
+		default:
+			return next[0] == '[', nil
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Description:
+//
+//	An item decoded from a streamed test body.
+type streamItem struct {
+
+	// The name of the item.
+	Name string `json:"name"`
+}
+
+func TestDecodeEach(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		names []string
+		fails bool
+	}{
+		{name: "array", body: `[{"name":"a"}, {"name":"b"}]`, names: []string{"a", "b"}},
+		{name: "empty array", body: ` []`, names: []string{}},
+		{name: "newline delimited", body: "{\"name\":\"a\"}\n{\"name\":\"b\"}\n", names: []string{"a", "b"}},
+		{name: "leading whitespace", body: "\n\t [{\"name\":\"a\"}]", names: []string{"a"}},
+		{name: "empty body", body: "  ", fails: true},
+		{name: "malformed item", body: `[{"name":"a"}, {"name":]`, names: []string{"a"}, fails: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := &APIRequest{BodyReader: strings.NewReader(test.body)}
+			names := make([]string, 0)
+
+			err := DecodeEach(request, func(item *streamItem) error {
+				names = append(names, item.Name)
+				return nil
+			})
+
+			if test.fails != (err != nil) {
+				t.Fatalf("expected failure %t, got %v", test.fails, err)
+			}
+
+			if strings.Join(names, ",") != strings.Join(test.names, ",") {
+				t.Errorf("expected items %v, got %v", test.names, names)
+			}
+		})
+	}
+}
+
+func TestDecodeEachIsIncremental(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	items := make(chan string)
+	done := make(chan error)
+
+	go func() {
+		done <- DecodeEach(&APIRequest{BodyReader: reader}, func(item *streamItem) error {
+			items <- item.Name
+			return nil
+		})
+	}()
+
+	// The second item is only sent once the first one was handled.
+	go writer.Write([]byte(`[{"name":"a"}`))
+
+	select {
+	case name := <-items:
+		if name != "a" {
+			t.Fatalf("expected item a, got %s", name)
+		}
+	case <-time.After(5 * time.Second):
+		writer.Close()
+		t.Fatal("the first item was not handled before the body was complete")
+	}
+
+	go func() {
+		writer.Write([]byte(`, {"name":"b"}]`))
+		writer.Close()
+	}()
+
+	if name := <-items; name != "b" {
+		t.Errorf("expected item b, got %s", name)
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+}
@@ -0,0 +1,17 @@
+package api
+
+// Description:
+//
+//	The W3C trace context of a request, parsed from the traceparent header.
+//	See https://www.w3.org/TR/trace-context/
+type TraceContext struct {
+
+	// The trace id, 32 hex characters.
+	TraceID string `json:"traceId"`
+
+	// The id of the parent span, 16 hex characters.
+	ParentID string `json:"parentId"`
+
+	// The trace flags, 2 hex characters.
+	Flags string `json:"flags"`
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/gostream-official/albums/pkg/api"
+)
+
+const (
+
+	// The authentication method of principals authenticated via API key.
+	MethodAPIKey = "apikey"
+)
+
+// Description:
+//
+//	Resolves API keys to their principals, e.g. via a database of hashed keys.
+type APIKeyResolver interface {
+
+	// Description:
+	//
+	//	Resolves an API key.
+	//
+	// Parameters:
+	//
+	//	ctx The request context.
+	//	key The plaintext API key.
+	//
+	// Returns:
+	//
+	//	The principal of the key, or an error if the key is unknown, expired or revoked.
+	Resolve(ctx context.Context, key string) (*api.Principal, error)
+}
+
+// Description:
+//
+//	Authenticates requests with an API key sent in a header.
+type apiKeyAuthenticator struct {
+
+	// The header carrying the API key.
+	header string
+
+	// The resolver of API keys.
+	resolver APIKeyResolver
+}
+
+// Description:
+//
+//	Creates an authenticator for API keys sent in the given header.
+//
+// Parameters:
+//
+//	header 		The header carrying the API key, e.g. X-API-Key.
+//	resolver 	The resolver of API keys.
+//
+// Returns:
+//
+//	The created authenticator.
+func APIKeyAuthenticator(header string, resolver APIKeyResolver) Authenticator {
+	return &apiKeyAuthenticator{
+		header:   header,
+		resolver: resolver,
+	}
+}
+
+// Description:
+//
+//	Gets the authentication scheme.
+//
+// Returns:
+//
+//	ApiKey.
+func (authenticator *apiKeyAuthenticator) Scheme() string {
+	return "ApiKey"
+}
+
+// Description:
+//
+//	Authenticates a request via its API key.
+//
+// Parameters:
+//
+//	request The request.
+//
+// Returns:
+//
+//	The principal, or nil if the request carries no API key.
+//	An error if the key is invalid.
+func (authenticator *apiKeyAuthenticator) Authenticate(request *api.APIRequest) (*api.Principal, error) {
+	key := request.Header(authenticator.header)
+	if key == "" {
+		return nil, nil
+	}
+
+	return authenticator.resolver.Resolve(request.Context, key)
+}
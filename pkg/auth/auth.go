@@ -0,0 +1,296 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/router"
+)
+
+// Description:
+//
+//	The logger of this package.
+var logger = logging.Named("auth")
+
+// Description:
+//
+//	Signals that credentials cannot be verified, e.g. as a key set cannot be fetched.
+//	Authenticators wrap it, so that such failures are not mistaken for invalid credentials.
+var ErrUnavailable = errors.New("auth: credentials cannot be verified")
+
+// Description:
+//
+//	Authenticates requests via one kind of credentials.
+type Authenticator interface {
+
+	// Description:
+	//
+	//	Gets the authentication scheme, used in WWW-Authenticate challenges, e.g. Bearer.
+	//
+	// Returns:
+	//
+	//	The authentication scheme.
+	Scheme() string
+
+	// Description:
+	//
+	//	Authenticates a request.
+	//
+	// Parameters:
+	//
+	//	request The request.
+	//
+	// Returns:
+	//
+	//	The principal, or nil if the request carries no credentials of this kind.
+	//	An error if the credentials are invalid, wrapping ErrUnavailable if they cannot be verified.
+	Authenticate(request *api.APIRequest) (*api.Principal, error)
+}
+
+// Description:
+//
+//	Authenticates requests and enforces per-route requirements.
+type Guard struct {
+
+	// The realm of WWW-Authenticate challenges.
+	realm string
+
+	// The authenticators, in order of precedence.
+	authenticators []Authenticator
+}
+
+// Description:
+//
+//	Authenticates requests with a bearer JWT.
+type bearerAuthenticator struct {
+
+	// The token verifier.
+	verifier *Verifier
+}
+
+// Description:
+//
+//	Creates a guard.
+//
+// Parameters:
+//
+//	realm 			The realm of WWW-Authenticate challenges, e.g. albums.
+//	authenticators 	The authenticators, in order of precedence.
+//
+// Returns:
+//
+//	The created guard.
+func NewGuard(realm string, authenticators ...Authenticator) *Guard {
+	return &Guard{
+		realm:          realm,
+		authenticators: authenticators,
+	}
+}
+
+// Description:
+//
+//	Creates an authenticator for JWTs sent in the Authorization header with the Bearer scheme.
+//
+// Parameters:
+//
+//	verifier The token verifier.
+//
+// Returns:
+//
+//	The created authenticator.
+func BearerAuthenticator(verifier *Verifier) Authenticator {
+	return &bearerAuthenticator{verifier: verifier}
+}
+
+// Description:
+//
+//	Creates a middleware attaching the principal of authenticated requests.
+//	Requests without credentials pass anonymously, requests with invalid credentials are answered with 401,
+//	requests with credentials which cannot be verified with 503.
+//	The reason is logged only, so that internal errors are not exposed to clients.
+//
+// Returns:
+//
+//	The middleware.
+func (guard *Guard) Authenticate() router.Middleware {
+	return func(request *api.APIRequest, next router.RouterHandlerFunc) *api.APIResponse {
+		for _, authenticator := range guard.authenticators {
+			principal, err := authenticator.Authenticate(request)
+
+			if errors.Is(err, ErrUnavailable) {
+				request.Logger.Errorf("cannot verify %s credentials: %s", authenticator.Scheme(), err)
+				return &api.APIResponse{
+					StatusCode: http.StatusServiceUnavailable,
+					Body: api.ErrorResponseBody{
+						Message:   "authentication unavailable",
+						RequestID: request.RequestID,
+					},
+				}
+			}
+
+			if err != nil {
+				request.Logger.Warnf("rejected %s credentials: %s", authenticator.Scheme(), err)
+
+				challenge := fmt.Sprintf(`%s realm=%q, error="invalid_token", error_description="invalid credentials"`, authenticator.Scheme(), guard.realm)
+				return guard.reject(request, http.StatusUnauthorized, challenge, "invalid credentials")
+			}
+
+			if principal != nil {
+				request.Principal = principal
+				request.Logger = request.Logger.With(logging.F("subject", principal.Subject))
+				break
+			}
+		}
+
+		return next(request)
+	}
+}
+
+// Description:
+//
+//	Creates a middleware requiring an authenticated principal, which was granted all given scopes.
+//	Answers anonymous requests with 401 and requests lacking a scope with 403.
+//
+// Parameters:
+//
+//	scopes The required scopes, e.g. albums:write.
+//
+// Returns:
+//
+//	The middleware.
+func (guard *Guard) RequireScopes(scopes ...string) router.Middleware {
+	return func(request *api.APIRequest, next router.RouterHandlerFunc) *api.APIResponse {
+		if request.Principal == nil {
+			return guard.reject(request, http.StatusUnauthorized, guard.challenges(""), "authentication required")
+		}
+
+		for _, scope := range scopes {
+			if !request.Principal.HasScope(scope) {
+				request.Logger.Warnf("missing scope %s", scope)
+
+				parameters := fmt.Sprintf(`error="insufficient_scope", scope=%q`, strings.Join(scopes, " "))
+				return guard.reject(request, http.StatusForbidden, guard.challenges(parameters), "insufficient scope")
+			}
+		}
+
+		return next(request)
+	}
+}
+
+// Description:
+//
+//	Creates a middleware requiring an authenticated principal, which was granted at least one of the given roles.
+//	Answers anonymous requests with 401 and requests lacking a role with 403.
+//
+// Parameters:
+//
+//	roles The accepted roles, e.g. admin.
+//
+// Returns:
+//
+//	The middleware.
+func (guard *Guard) RequireRoles(roles ...string) router.Middleware {
+	return func(request *api.APIRequest, next router.RouterHandlerFunc) *api.APIResponse {
+		if request.Principal == nil {
+			return guard.reject(request, http.StatusUnauthorized, guard.challenges(""), "authentication required")
+		}
+
+		for _, role := range roles {
+			if request.Principal.HasRole(role) {
+				return next(request)
+			}
+		}
+
+		request.Logger.Warnf("missing any role of %s", strings.Join(roles, ", "))
+		return guard.reject(request, http.StatusForbidden, guard.challenges(`error="insufficient_scope"`), "insufficient role")
+	}
+}
+
+// Description:
+//
+//	Creates the WWW-Authenticate challenges of all authenticators.
+//
+// Parameters:
+//
+//	parameters Additional challenge parameters, or an empty string.
+//
+// Returns:
+//
+//	The challenges, separated by commas.
+func (guard *Guard) challenges(parameters string) string {
+	challenges := make([]string, 0)
+
+	for _, authenticator := range guard.authenticators {
+		challenge := fmt.Sprintf("%s realm=%q", authenticator.Scheme(), guard.realm)
+		if parameters != "" {
+			challenge += ", " + parameters
+		}
+
+		challenges = append(challenges, challenge)
+	}
+
+	return strings.Join(challenges, ", ")
+}
+
+// Description:
+//
+//	Creates a rejection response.
+//
+// Parameters:
+//
+//	request 	The rejected request.
+//	statusCode 	The status code, 401 or 403.
+//	challenge 	The WWW-Authenticate header.
+//	message 	The error message.
+//
+// Returns:
+//
+//	The response.
+func (guard *Guard) reject(request *api.APIRequest, statusCode int, challenge string, message string) *api.APIResponse {
+	return &api.APIResponse{
+		StatusCode: statusCode,
+		Headers: map[string]string{
+			"WWW-Authenticate": challenge,
+		},
+		Body: api.ErrorResponseBody{
+			Message:   message,
+			RequestID: request.RequestID,
+		},
+	}
+}
+
+// Description:
+//
+//	Gets the authentication scheme.
+//
+// Returns:
+//
+//	Bearer.
+func (authenticator *bearerAuthenticator) Scheme() string {
+	return "Bearer"
+}
+
+// Description:
+//
+//	Authenticates a request via its bearer token.
+//
+// Parameters:
+//
+//	request The request.
+//
+// Returns:
+//
+//	The principal, or nil if the request carries no bearer token.
+//	An error if the token is invalid.
+func (authenticator *bearerAuthenticator) Authenticate(request *api.APIRequest) (*api.Principal, error) {
+	scheme, token, ok := strings.Cut(request.Header("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, nil
+	}
+
+	return authenticator.verifier.Verify(request.Context, strings.TrimSpace(token))
+}
@@ -0,0 +1,326 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/clock"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/router"
+)
+
+// Description:
+//
+//	The secret signing the test tokens.
+var secret = []byte("test-secret")
+
+// Description:
+//
+//	The fixed time of the test clock.
+var now = time.Unix(1700000000, 0)
+
+// Description:
+//
+//	Creates a HS256 token with the given claims.
+//
+// Parameters:
+//
+//	claims The token claims.
+//
+// Returns:
+//
+//	The compact serialized token.
+func sign(claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": AlgorithmHS256, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Description:
+//
+//	Creates a verifier for the test tokens.
+//
+// Parameters:
+//
+//	keys The key set.
+//
+// Returns:
+//
+//	The verifier.
+func newVerifier(keys KeySet) *Verifier {
+	return NewVerifier(VerifierConfig{
+		Keys:     keys,
+		Issuer:   "issuer",
+		Audience: "albums",
+		Clock:    clock.Fixed(now),
+	})
+}
+
+// Description:
+//
+//	Creates the claims of a valid token.
+//
+// Returns:
+//
+//	The claims.
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":   "issuer",
+		"sub":   "subject",
+		"aud":   []string{"albums"},
+		"exp":   now.Add(time.Minute).Unix(),
+		"scope": "albums:read albums:write",
+		"roles": []string{"admin"},
+	}
+}
+
+// Description:
+//
+//	Creates a request with the given headers.
+//
+// Parameters:
+//
+//	headers The request headers.
+//
+// Returns:
+//
+//	The request.
+func newRequest(headers map[string]string) *api.APIRequest {
+	multiValueHeaders := make(map[string][]string)
+	for name, value := range headers {
+		multiValueHeaders[http.CanonicalHeaderKey(name)] = []string{value}
+	}
+
+	return &api.APIRequest{
+		Headers:           headers,
+		MultiValueHeaders: multiValueHeaders,
+		RequestID:         "request",
+		Context:           context.Background(),
+		Logger:            logging.Named("test"),
+	}
+}
+
+// Description:
+//
+//	A handler answering with 200.
+//
+// Parameters:
+//
+//	request The request.
+//
+// Returns:
+//
+//	The response.
+func ok(request *api.APIRequest) *api.APIResponse {
+	return &api.APIResponse{StatusCode: http.StatusOK}
+}
+
+// Description:
+//
+//	A resolver of a fixed API key.
+type staticResolver struct {
+
+	// The error returned for every key other than "valid".
+	err error
+}
+
+// Description:
+//
+//	Resolves the API key "valid".
+//
+// Parameters:
+//
+//	ctx The request context.
+//	key The plaintext API key.
+//
+// Returns:
+//
+//	The principal, or the configured error.
+func (resolver staticResolver) Resolve(ctx context.Context, key string) (*api.Principal, error) {
+	if key == "valid" {
+		return &api.Principal{Subject: "machine", Method: MethodAPIKey, Scopes: []string{"albums:write"}}, nil
+	}
+
+	return nil, resolver.err
+}
+
+func TestVerifierAcceptsValidTokens(t *testing.T) {
+	principal, err := newVerifier(SecretKeySet("", secret)).Verify(context.Background(), sign(validClaims()))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if principal.Subject != "subject" || principal.Method != MethodJWT {
+		t.Fatalf("unexpected principal %+v", principal)
+	}
+
+	if !principal.HasScope("albums:write") || !principal.HasRole("admin") {
+		t.Fatalf("expected the scopes and roles of the token, got %+v", principal)
+	}
+}
+
+func TestVerifierRejectsInvalidTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(claims map[string]interface{})
+		token  string
+	}{
+		{name: "expired", modify: func(claims map[string]interface{}) { claims["exp"] = now.Add(-time.Minute).Unix() }},
+		{name: "without expiry", modify: func(claims map[string]interface{}) { delete(claims, "exp") }},
+		{name: "not yet valid", modify: func(claims map[string]interface{}) { claims["nbf"] = now.Add(time.Minute).Unix() }},
+		{name: "wrong issuer", modify: func(claims map[string]interface{}) { claims["iss"] = "other" }},
+		{name: "wrong audience", modify: func(claims map[string]interface{}) { claims["aud"] = "other" }},
+		{name: "without subject", modify: func(claims map[string]interface{}) { delete(claims, "sub") }},
+		{name: "malformed", token: "not-a-token"},
+		{name: "tampered", token: sign(validClaims())[:10] + "x" + sign(validClaims())[11:]},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token := test.token
+			if token == "" {
+				claims := validClaims()
+				test.modify(claims)
+				token = sign(claims)
+			}
+
+			_, err := newVerifier(SecretKeySet("", secret)).Verify(context.Background(), token)
+			if err == nil {
+				t.Fatalf("expected the token to be rejected")
+			}
+
+			if errors.Is(err, ErrUnavailable) {
+				t.Fatalf("expected an invalid token, not an unavailable key set: %s", err)
+			}
+		})
+	}
+}
+
+func TestVerifierSignalsUnreachableKeySets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := newVerifier(NewRemoteKeySet(server.URL, time.Minute, clock.Fixed(now))).Verify(context.Background(), sign(validClaims()))
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestGuardAuthenticate(t *testing.T) {
+	guard := NewGuard("albums",
+		BearerAuthenticator(newVerifier(SecretKeySet("", secret))),
+		APIKeyAuthenticator("X-API-Key", staticResolver{err: fmt.Errorf("unknown api key")}),
+	)
+
+	unavailable := NewGuard("albums",
+		APIKeyAuthenticator("X-API-Key", staticResolver{err: fmt.Errorf("%w: database down", ErrUnavailable)}),
+	)
+
+	expired := validClaims()
+	expired["exp"] = now.Add(-time.Minute).Unix()
+
+	tests := []struct {
+		name       string
+		guard      *Guard
+		headers    map[string]string
+		statusCode int
+		subject    string
+	}{
+		{name: "anonymous", guard: guard, statusCode: http.StatusOK},
+		{name: "valid token", guard: guard, headers: map[string]string{"Authorization": "Bearer " + sign(validClaims())}, statusCode: http.StatusOK, subject: "subject"},
+		{name: "expired token", guard: guard, headers: map[string]string{"Authorization": "Bearer " + sign(expired)}, statusCode: http.StatusUnauthorized},
+		{name: "valid api key", guard: guard, headers: map[string]string{"X-API-Key": "valid"}, statusCode: http.StatusOK, subject: "machine"},
+		{name: "unknown api key", guard: guard, headers: map[string]string{"X-API-Key": "guessed"}, statusCode: http.StatusUnauthorized},
+		{name: "unavailable api key store", guard: unavailable, headers: map[string]string{"X-API-Key": "guessed"}, statusCode: http.StatusServiceUnavailable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var principal *api.Principal
+
+			response := test.guard.Authenticate()(newRequest(test.headers), func(request *api.APIRequest) *api.APIResponse {
+				principal = request.Principal
+				return ok(request)
+			})
+
+			if response.StatusCode != test.statusCode {
+				t.Fatalf("expected status %d, got %d", test.statusCode, response.StatusCode)
+			}
+
+			if test.subject != "" && (principal == nil || principal.Subject != test.subject) {
+				t.Fatalf("expected subject %s, got %+v", test.subject, principal)
+			}
+
+			if response.StatusCode == http.StatusUnauthorized {
+				challenge := response.Headers["WWW-Authenticate"]
+				if !strings.Contains(challenge, `error_description="invalid credentials"`) {
+					t.Fatalf("expected a fixed error description, got %q", challenge)
+				}
+			}
+
+			if response.StatusCode != http.StatusOK {
+				body, ok := response.Body.(api.ErrorResponseBody)
+				if !ok || body.RequestID != "request" {
+					t.Fatalf("expected an error body with the request id, got %+v", response.Body)
+				}
+
+				if strings.Contains(body.Message, "database") || strings.Contains(body.Message, "unknown") {
+					t.Fatalf("expected internal errors not to be exposed, got %q", body.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestGuardRequirements(t *testing.T) {
+	guard := NewGuard("albums", BearerAuthenticator(newVerifier(SecretKeySet("", secret))))
+
+	tests := []struct {
+		name       string
+		principal  *api.Principal
+		middleware router.Middleware
+		statusCode int
+	}{
+		{"scope anonymous", nil, guard.RequireScopes("albums:write"), http.StatusUnauthorized},
+		{"scope missing", &api.Principal{Scopes: []string{"albums:read"}}, guard.RequireScopes("albums:write"), http.StatusForbidden},
+		{"scope granted", &api.Principal{Scopes: []string{"albums:write"}}, guard.RequireScopes("albums:write"), http.StatusOK},
+		{"role anonymous", nil, guard.RequireRoles("admin"), http.StatusUnauthorized},
+		{"role missing", &api.Principal{Roles: []string{"editor"}}, guard.RequireRoles("admin"), http.StatusForbidden},
+		{"role granted", &api.Principal{Roles: []string{"editor", "admin"}}, guard.RequireRoles("admin"), http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := newRequest(nil)
+			request.Principal = test.principal
+
+			response := test.middleware(request, ok)
+			if response.StatusCode != test.statusCode {
+				t.Fatalf("expected status %d, got %d", test.statusCode, response.StatusCode)
+			}
+
+			if response.StatusCode != http.StatusOK && !strings.HasPrefix(response.Headers["WWW-Authenticate"], `Bearer realm="albums"`) {
+				t.Fatalf("expected a bearer challenge, got %q", response.Headers["WWW-Authenticate"])
+			}
+		})
+	}
+}
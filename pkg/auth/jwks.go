@@ -0,0 +1,511 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/clock"
+)
+
+const (
+
+	// The minimum interval between refetching a remote key set for unknown key ids.
+	minRefreshInterval = 30 * time.Second
+
+	// The timeout of fetching a remote key set.
+	fetchTimeout = 10 * time.Second
+)
+
+// Description:
+//
+//	A verification key.
+type Key struct {
+
+	// The key id, matched against the kid header of tokens. May be empty.
+	ID string
+
+	// The verification key: *rsa.PublicKey, *ecdsa.PublicKey or []byte for HMAC secrets.
+	Material interface{}
+}
+
+// Description:
+//
+//	A source of verification keys.
+type KeySet interface {
+
+	// Description:
+	//
+	//	Gets the candidate keys for the given key id.
+	//
+	// Parameters:
+	//
+	//	ctx The request context.
+	//	kid The key id of the token, or an empty string if the token has none.
+	//
+	// Returns:
+	//
+	//	The keys with the given id, or all keys if the id is empty, or an error if the keys cannot be loaded.
+	Lookup(ctx context.Context, kid string) ([]Key, error)
+}
+
+// Description:
+//
+//	A fixed set of keys.
+type StaticKeySet []Key
+
+// Description:
+//
+//	A key set combining several key sets.
+type MultiKeySet []KeySet
+
+// Description:
+//
+//	A key set fetched from a JWKS URL and cached.
+//	Refetched once the cache expires, or when a token references an unknown key id.
+//	Concurrent lookups share a single fetch, which is not bound to any request.
+type RemoteKeySet struct {
+
+	// The JWKS URL.
+	url string
+
+	// The duration keys are cached.
+	ttl time.Duration
+
+	// The HTTP client.
+	client *http.Client
+
+	// The clock, used for cache expiry and fetch throttling.
+	clock clock.Clock
+
+	// Guards the fields below. Not held while fetching.
+	mutex sync.Mutex
+
+	// Closed once the running fetch completes, or nil if no fetch is running.
+	inflight chan struct{}
+
+	// The cached keys.
+	keys StaticKeySet
+
+	// The time the keys were fetched.
+	fetched time.Time
+
+	// The time the keys were last attempted to be fetched.
+	attempted time.Time
+
+	// The error of the last fetch, or nil.
+	err error
+}
+
+// Description:
+//
+//	A JSON web key, see RFC 7517.
+type jsonWebKey struct {
+
+	// The key type: RSA, EC or oct.
+	KeyType string `json:"kty"`
+
+	// The key id.
+	ID string `json:"kid"`
+
+	// The intended use, e.g. sig.
+	Use string `json:"use"`
+
+	// The RSA modulus.
+	N string `json:"n"`
+
+	// The RSA exponent.
+	E string `json:"e"`
+
+	// The elliptic curve.
+	Curve string `json:"crv"`
+
+	// The elliptic curve x coordinate.
+	X string `json:"x"`
+
+	// The elliptic curve y coordinate.
+	Y string `json:"y"`
+
+	// The symmetric key.
+	K string `json:"k"`
+}
+
+// Description:
+//
+//	Creates a key set holding a single HMAC secret.
+//
+// Parameters:
+//
+//	id 		The key id, or an empty string.
+//	secret 	The secret.
+//
+// Returns:
+//
+//	The key set.
+func SecretKeySet(id string, secret []byte) StaticKeySet {
+	return StaticKeySet{{ID: id, Material: secret}}
+}
+
+// Description:
+//
+//	Loads a key set from a local JWKS file.
+//
+// Parameters:
+//
+//	path The path of the JWKS file.
+//
+// Returns:
+//
+//	The key set, or an error if the file cannot be read or parsed.
+func LoadKeySetFile(path string) (StaticKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKeySet(data)
+}
+
+// Description:
+//
+//	Parses a JWKS document. Keys not used for signatures and unsupported keys are skipped.
+//
+// Parameters:
+//
+//	data The JWKS document.
+//
+// Returns:
+//
+//	The key set, or an error if the document is malformed.
+func ParseKeySet(data []byte) (StaticKeySet, error) {
+	document := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+
+	err := json.Unmarshal(data, &document)
+	if err != nil {
+		return nil, fmt.Errorf("auth: malformed JWKS: %s", err)
+	}
+
+	keys := make(StaticKeySet, 0)
+
+	for _, webKey := range document.Keys {
+		if webKey.Use != "" && webKey.Use != "sig" {
+			continue
+		}
+
+		material, err := webKey.material()
+		if err != nil {
+			logger.Warnf("skipping JWK %q: %s", webKey.ID, err)
+			continue
+		}
+
+		keys = append(keys, Key{ID: webKey.ID, Material: material})
+	}
+
+	return keys, nil
+}
+
+// Description:
+//
+//	Creates a key set fetched from a JWKS URL.
+//
+// Parameters:
+//
+//	url 	The JWKS URL.
+//	ttl 	The duration keys are cached.
+//	clock 	The clock, used for cache expiry and fetch throttling.
+//
+// Returns:
+//
+//	The key set.
+func NewRemoteKeySet(url string, ttl time.Duration, clock clock.Clock) *RemoteKeySet {
+	return &RemoteKeySet{
+		url:    url,
+		ttl:    ttl,
+		client: &http.Client{Timeout: fetchTimeout},
+		clock:  clock,
+	}
+}
+
+// Description:
+//
+//	Gets the candidate keys for the given key id.
+//
+// Parameters:
+//
+//	ctx The request context.
+//	kid The key id, or an empty string.
+//
+// Returns:
+//
+//	The matching keys.
+func (keys StaticKeySet) Lookup(ctx context.Context, kid string) ([]Key, error) {
+	if kid == "" {
+		return keys, nil
+	}
+
+	matches := make([]Key, 0)
+
+	for _, key := range keys {
+		if key.ID == kid {
+			matches = append(matches, key)
+		}
+	}
+
+	return matches, nil
+}
+
+// Description:
+//
+//	Gets the candidate keys of all key sets for the given key id.
+//
+// Parameters:
+//
+//	ctx The request context.
+//	kid The key id, or an empty string.
+//
+// Returns:
+//
+//	The matching keys, or an error if no key set could be loaded.
+func (sets MultiKeySet) Lookup(ctx context.Context, kid string) ([]Key, error) {
+	matches := make([]Key, 0)
+
+	var lastErr error
+
+	for _, set := range sets {
+		keys, err := set.Lookup(ctx, kid)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		matches = append(matches, keys...)
+	}
+
+	if len(matches) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	return matches, nil
+}
+
+// Description:
+//
+//	Gets the candidate keys for the given key id, fetching the key set if required.
+//	Lookups requiring a fetch wait for it, unless their context is done first.
+//	If fetching fails, the previously fetched keys are used.
+//
+// Parameters:
+//
+//	ctx The request context. Only bounds waiting, the fetch itself is not cancelled with it.
+//	kid The key id, or an empty string.
+//
+// Returns:
+//
+//	The matching keys, or an error if the key set was never fetched successfully.
+func (set *RemoteKeySet) Lookup(ctx context.Context, kid string) ([]Key, error) {
+	set.mutex.Lock()
+
+	now := set.clock.Now()
+	matches, _ := set.keys.Lookup(ctx, kid)
+
+	// Keys may have been rotated if the key id is unknown.
+	refresh := set.keys == nil || now.Sub(set.fetched) > set.ttl || len(matches) == 0
+
+	// Fetches are throttled, so that unknown key ids or an unavailable endpoint cannot cause a fetch per request.
+	if !refresh || (set.inflight == nil && now.Sub(set.attempted) < minRefreshInterval) {
+		keys, err := set.keys, set.err
+		set.mutex.Unlock()
+
+		if keys == nil {
+			return nil, err
+		}
+
+		return matches, nil
+	}
+
+	if set.inflight == nil {
+		set.attempted = now
+		set.inflight = make(chan struct{})
+
+		go set.refresh(set.inflight)
+	}
+
+	inflight := set.inflight
+	set.mutex.Unlock()
+
+	select {
+	case <-inflight:
+	case <-ctx.Done():
+	}
+
+	set.mutex.Lock()
+	keys, err := set.keys, set.err
+	set.mutex.Unlock()
+
+	if keys == nil {
+		if err == nil {
+			err = fmt.Errorf("auth: JWKS not fetched yet: %s", ctx.Err())
+		}
+
+		return nil, err
+	}
+
+	return keys.Lookup(ctx, kid)
+}
+
+// Description:
+//
+//	Fetches the key set and stores the result. Must not be called with the mutex held.
+//
+// Parameters:
+//
+//	done The channel of the fetch, closed once the result is stored.
+func (set *RemoteKeySet) refresh(done chan struct{}) {
+	keys, err := set.fetch()
+
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+	defer close(done)
+
+	set.inflight = nil
+	set.err = err
+
+	if err != nil {
+		if set.keys != nil {
+			logger.Warnf("failed to refresh JWKS, using cached keys: %s", err)
+		}
+
+		return
+	}
+
+	set.keys = keys
+	set.fetched = set.clock.Now()
+}
+
+// Description:
+//
+//	Fetches and parses the key set.
+//	The fetch is bound to the fetch timeout only, so that it is shared by concurrent lookups
+//	and not failed by a single client disconnecting.
+//
+// Returns:
+//
+//	The key set, or an error if fetching or parsing fails.
+func (set *RemoteKeySet) fetch() (StaticKeySet, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, set.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := set.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to fetch JWKS: %s", err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("auth: failed to fetch JWKS: status %d", response.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to fetch JWKS: %s", err)
+	}
+
+	return ParseKeySet(data)
+}
+
+// Description:
+//
+//	Converts a JSON web key to its verification key.
+//
+// Returns:
+//
+//	The verification key, or an error if the key is malformed or not supported.
+func (webKey *jsonWebKey) material() (interface{}, error) {
+	switch webKey.KeyType {
+	case "RSA":
+		modulus, err := decodeBigInt(webKey.N)
+		if err != nil {
+			return nil, err
+		}
+
+		exponent, err := decodeBigInt(webKey.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}, nil
+
+	case "EC":
+		if webKey.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", webKey.Curve)
+		}
+
+		x, err := decodeBigInt(webKey.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(webKey.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+
+		// Fails for points which are not on the curve.
+		_, err = key.ECDH()
+		if err != nil {
+			return nil, err
+		}
+
+		return key, nil
+
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(webKey.K)
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", webKey.KeyType)
+	}
+}
+
+// Description:
+//
+//	Decodes a base64url encoded big-endian integer.
+//
+// Parameters:
+//
+//	value The encoded integer.
+//
+// Returns:
+//
+//	The integer, or an error if the value is malformed.
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty integer")
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Description:
+//
+//	A clock which is advanced manually. Safe for concurrent use.
+type manualClock struct {
+
+	// Guards the current time.
+	mutex sync.Mutex
+
+	// The current time.
+	now time.Time
+}
+
+// Description:
+//
+//	A JWKS endpoint serving oct keys with configurable ids.
+type jwksServer struct {
+	*httptest.Server
+
+	// Guards the fields below.
+	mutex sync.Mutex
+
+	// The ids of the served keys.
+	kids []string
+
+	// Whether the endpoint fails.
+	failing bool
+
+	// Closed to release blocked requests, or nil if requests are not blocked.
+	release chan struct{}
+
+	// The number of requests served.
+	requests atomic.Int32
+}
+
+// Description:
+//
+//	Gets the current time.
+//
+// Returns:
+//
+//	The current time.
+func (clock *manualClock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	return clock.now
+}
+
+// Description:
+//
+//	Advances the clock.
+//
+// Parameters:
+//
+//	duration The duration to advance by.
+func (clock *manualClock) Advance(duration time.Duration) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	clock.now = clock.now.Add(duration)
+}
+
+// Description:
+//
+//	Starts a JWKS endpoint.
+//
+// Parameters:
+//
+//	t 		The test.
+//	kids 	The ids of the served keys.
+//
+// Returns:
+//
+//	The endpoint, closed when the test ends.
+func newJWKSServer(t *testing.T, kids ...string) *jwksServer {
+	server := &jwksServer{kids: kids}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		server.requests.Add(1)
+
+		server.mutex.Lock()
+		kids, failing, release := server.kids, server.failing, server.release
+		server.mutex.Unlock()
+
+		if release != nil {
+			<-release
+		}
+
+		if failing {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		keys := make([]string, 0)
+		for _, kid := range kids {
+			keys = append(keys, fmt.Sprintf(`{"kty":"oct","kid":%q,"k":"c2VjcmV0"}`, kid))
+		}
+
+		fmt.Fprintf(writer, `{"keys":[%s]}`, strings.Join(keys, ","))
+	}))
+
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Description:
+//
+//	Changes the served keys and whether the endpoint fails.
+//
+// Parameters:
+//
+//	failing Whether the endpoint fails.
+//	kids 	The ids of the served keys.
+func (server *jwksServer) serve(failing bool, kids ...string) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.failing = failing
+	server.kids = kids
+}
+
+// Description:
+//
+//	Looks up a key id and fails the test if the result differs from the expected key ids.
+//
+// Parameters:
+//
+//	t 		The test.
+//	set 	The key set.
+//	kid 	The key id.
+//	want 	The expected key ids.
+func expectKeys(t *testing.T, set KeySet, kid string, want ...string) {
+	t.Helper()
+
+	keys, err := set.Lookup(context.Background(), kid)
+	if err != nil {
+		t.Fatalf("unexpected error for kid %q: %s", kid, err)
+	}
+
+	got := make([]string, 0)
+	for _, key := range keys {
+		got = append(got, key.ID)
+	}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected keys %v for kid %q, got %v", want, kid, got)
+	}
+}
+
+func TestRemoteKeySetExpiresKeys(t *testing.T) {
+	server := newJWKSServer(t, "a")
+	clock := &manualClock{now: now}
+	set := NewRemoteKeySet(server.URL, 10*time.Minute, clock)
+
+	expectKeys(t, set, "a", "a")
+	expectKeys(t, set, "a", "a")
+
+	if requests := server.requests.Load(); requests != 1 {
+		t.Fatalf("expected cached keys to be used, got %d fetches", requests)
+	}
+
+	server.serve(false, "b")
+	clock.Advance(10*time.Minute + time.Second)
+
+	expectKeys(t, set, "b", "b")
+
+	if requests := server.requests.Load(); requests != 2 {
+		t.Fatalf("expected expired keys to be refetched, got %d fetches", requests)
+	}
+}
+
+func TestRemoteKeySetThrottlesUnknownKeyIDs(t *testing.T) {
+	server := newJWKSServer(t, "a")
+	clock := &manualClock{now: now}
+	set := NewRemoteKeySet(server.URL, time.Hour, clock)
+
+	expectKeys(t, set, "a", "a")
+
+	server.serve(false, "a", "b")
+
+	for index := 0; index < 3; index++ {
+		expectKeys(t, set, "b")
+		clock.Advance(5 * time.Second)
+	}
+
+	if requests := server.requests.Load(); requests != 1 {
+		t.Fatalf("expected unknown key ids not to be refetched within %s, got %d fetches", minRefreshInterval, requests)
+	}
+
+	clock.Advance(minRefreshInterval)
+	expectKeys(t, set, "b", "b")
+
+	if requests := server.requests.Load(); requests != 2 {
+		t.Fatalf("expected the rotated keys to be fetched, got %d fetches", requests)
+	}
+}
+
+func TestRemoteKeySetFallsBackToStaleKeys(t *testing.T) {
+	server := newJWKSServer(t, "a")
+	clock := &manualClock{now: now}
+	set := NewRemoteKeySet(server.URL, time.Minute, clock)
+
+	expectKeys(t, set, "a", "a")
+
+	server.serve(true)
+	clock.Advance(2 * time.Minute)
+
+	expectKeys(t, set, "a", "a")
+
+	if requests := server.requests.Load(); requests != 2 {
+		t.Fatalf("expected a failed refetch, got %d fetches", requests)
+	}
+
+	_, err := NewRemoteKeySet(server.URL, time.Minute, clock).Lookup(context.Background(), "a")
+	if err == nil {
+		t.Fatalf("expected an error without any fetched keys")
+	}
+}
+
+func TestRemoteKeySetSharesFetchesAcrossRequests(t *testing.T) {
+	server := newJWKSServer(t, "a")
+	server.release = make(chan struct{})
+
+	set := NewRemoteKeySet(server.URL, time.Hour, &manualClock{now: now})
+
+	// The first request gives up while the fetch is running, e.g. because its client disconnected.
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := set.Lookup(cancelled, "a")
+	if err == nil {
+		t.Fatalf("expected an error for a request giving up before the first fetch completed")
+	}
+
+	group := sync.WaitGroup{}
+	failures := atomic.Int32{}
+
+	for index := 0; index < 10; index++ {
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			keys, err := set.Lookup(context.Background(), "a")
+			if err != nil || len(keys) != 1 {
+				failures.Add(1)
+			}
+		}()
+	}
+
+	close(server.release)
+	group.Wait()
+
+	if failures.Load() != 0 {
+		t.Fatalf("expected all waiting requests to get the fetched keys, %d failed", failures.Load())
+	}
+
+	if requests := server.requests.Load(); requests != 1 {
+		t.Fatalf("expected concurrent requests to share a single fetch, got %d fetches", requests)
+	}
+}
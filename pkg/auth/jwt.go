@@ -0,0 +1,387 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/clock"
+)
+
+const (
+
+	// The RSASSA-PKCS1-v1_5 with SHA-256 algorithm.
+	AlgorithmRS256 = "RS256"
+
+	// The ECDSA with P-256 and SHA-256 algorithm.
+	AlgorithmES256 = "ES256"
+
+	// The HMAC with SHA-256 algorithm.
+	AlgorithmHS256 = "HS256"
+
+	// The authentication method of principals verified via JWT.
+	MethodJWT = "jwt"
+)
+
+// Description:
+//
+//	The configuration of a JWT verifier.
+type VerifierConfig struct {
+
+	// The verification keys.
+	Keys KeySet
+
+	// The expected issuer. Not checked if empty.
+	Issuer string
+
+	// The expected audience. Not checked if empty.
+	Audience string
+
+	// The tolerated clock skew for the expiry and not-before checks.
+	Leeway time.Duration
+
+	// The clock used for the expiry and not-before checks.
+	Clock clock.Clock
+}
+
+// Description:
+//
+//	Verifies JSON web tokens (RFC 7519) signed with RS256, ES256 or HS256.
+type Verifier struct {
+
+	// The verifier configuration.
+	config VerifierConfig
+}
+
+// Description:
+//
+//	The JOSE header of a token.
+type tokenHeader struct {
+
+	// The signature algorithm.
+	Algorithm string `json:"alg"`
+
+	// The key id.
+	KeyID string `json:"kid"`
+}
+
+// Description:
+//
+//	The registered and well-known claims of a token.
+type tokenClaims struct {
+
+	// The issuer.
+	Issuer string `json:"iss"`
+
+	// The subject.
+	Subject string `json:"sub"`
+
+	// The audience, either a string or a list of strings.
+	Audience json.RawMessage `json:"aud"`
+
+	// The expiry time, in seconds since the epoch.
+	ExpiresAt *json.Number `json:"exp"`
+
+	// The not-before time, in seconds since the epoch.
+	NotBefore *json.Number `json:"nbf"`
+
+	// The space separated scopes.
+	Scope string `json:"scope"`
+
+	// The scopes, as a list.
+	Scopes []string `json:"scp"`
+
+	// The roles.
+	Roles []string `json:"roles"`
+}
+
+// Description:
+//
+//	Creates a JWT verifier.
+//
+// Parameters:
+//
+//	config The verifier configuration.
+//
+// Returns:
+//
+//	The created verifier.
+func NewVerifier(config VerifierConfig) *Verifier {
+	if config.Clock == nil {
+		config.Clock = clock.System()
+	}
+
+	return &Verifier{config: config}
+}
+
+// Description:
+//
+//	Verifies a token and extracts its principal.
+//	Checks the signature, expiry, not-before, issuer and audience.
+//
+// Parameters:
+//
+//	ctx 	The request context.
+//	token 	The compact serialized token.
+//
+// Returns:
+//
+//	The principal, or an error describing why the token is invalid.
+//	The error wraps ErrUnavailable if the verification keys cannot be loaded.
+func (verifier *Verifier) Verify(ctx context.Context, token string) (*api.Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	header := tokenHeader{}
+
+	err := decodeSegment(parts[0], &header)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token header")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature")
+	}
+
+	if verifier.config.Keys == nil {
+		return nil, fmt.Errorf("no verification keys configured")
+	}
+
+	keys, err := verifier.config.Keys.Lookup(ctx, header.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	signed := []byte(parts[0] + "." + parts[1])
+
+	verified := false
+	for _, key := range keys {
+		if verifySignature(header.Algorithm, key.Material, signed, digest[:], signature) {
+			verified = true
+			break
+		}
+	}
+
+	if !verified {
+		return nil, fmt.Errorf("invalid token signature")
+	}
+
+	claims := tokenClaims{}
+	all := make(map[string]interface{})
+
+	err = decodeSegment(parts[1], &claims)
+	if err == nil {
+		err = decodeSegment(parts[1], &all)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("malformed token claims")
+	}
+
+	err = verifier.checkClaims(&claims)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := claims.Scopes
+	if claims.Scope != "" {
+		scopes = append(scopes, strings.Fields(claims.Scope)...)
+	}
+
+	return &api.Principal{
+		Subject: claims.Subject,
+		Issuer:  claims.Issuer,
+		Method:  MethodJWT,
+		Scopes:  scopes,
+		Roles:   claims.Roles,
+		Claims:  all,
+	}, nil
+}
+
+// Description:
+//
+//	Checks the time, issuer and audience claims.
+//
+// Parameters:
+//
+//	claims The claims of the token.
+//
+// Returns:
+//
+//	An error if a claim is not satisfied.
+func (verifier *Verifier) checkClaims(claims *tokenClaims) error {
+	now := verifier.config.Clock.Now()
+	leeway := verifier.config.Leeway
+
+	if claims.ExpiresAt == nil {
+		return fmt.Errorf("token has no expiry")
+	}
+
+	expiresAt, err := parseNumericDate(*claims.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("malformed expiry claim")
+	}
+
+	if now.After(expiresAt.Add(leeway)) {
+		return fmt.Errorf("token expired")
+	}
+
+	if claims.NotBefore != nil {
+		notBefore, err := parseNumericDate(*claims.NotBefore)
+		if err != nil {
+			return fmt.Errorf("malformed not-before claim")
+		}
+
+		if now.Add(leeway).Before(notBefore) {
+			return fmt.Errorf("token not yet valid")
+		}
+	}
+
+	if claims.Subject == "" {
+		return fmt.Errorf("token has no subject")
+	}
+
+	if verifier.config.Issuer != "" && claims.Issuer != verifier.config.Issuer {
+		return fmt.Errorf("unexpected token issuer")
+	}
+
+	if verifier.config.Audience != "" && !containsAudience(claims.Audience, verifier.config.Audience) {
+		return fmt.Errorf("unexpected token audience")
+	}
+
+	return nil
+}
+
+// Description:
+//
+//	Verifies a signature with the given algorithm and key.
+//	The key type must match the algorithm, so that e.g. public RSA keys are never used as HMAC secrets.
+//
+// Parameters:
+//
+//	algorithm 	The signature algorithm of the token header.
+//	key 		The verification key.
+//	signed 		The signed bytes.
+//	digest 		The SHA-256 digest of the signed bytes.
+//	signature 	The signature.
+//
+// Returns:
+//
+//	Whether the signature is valid.
+func verifySignature(algorithm string, key interface{}, signed []byte, digest []byte, signature []byte) bool {
+	switch algorithm {
+	case AlgorithmRS256:
+		publicKey, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest, signature) == nil
+
+	case AlgorithmES256:
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return false
+		}
+
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+
+		return ecdsa.Verify(publicKey, digest, r, s)
+
+	case AlgorithmHS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return false
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+
+		return hmac.Equal(mac.Sum(nil), signature)
+
+	default:
+		return false
+	}
+}
+
+// Description:
+//
+//	Decodes a base64url encoded JSON segment of a token.
+//
+// Parameters:
+//
+//	segment The encoded segment.
+//	target 	A pointer to the target object.
+//
+// Returns:
+//
+//	An error if the segment is malformed.
+func decodeSegment(segment string, target interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
+
+// Description:
+//
+//	Parses a numeric date, i.e. seconds since the epoch.
+//
+// Parameters:
+//
+//	value The numeric date.
+//
+// Returns:
+//
+//	The time, or an error if the value is not a number.
+func parseNumericDate(value json.Number) (time.Time, error) {
+	seconds, err := value.Float64()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))), nil
+}
+
+// Description:
+//
+//	Checks whether an audience claim contains the given audience.
+//
+// Parameters:
+//
+//	claim 		The audience claim, either a string or a list of strings.
+//	audience 	The expected audience.
+//
+// Returns:
+//
+//	Whether the claim contains the audience.
+func containsAudience(claim json.RawMessage, audience string) bool {
+	var single string
+	if json.Unmarshal(claim, &single) == nil {
+		return single == audience
+	}
+
+	var multiple []string
+	if json.Unmarshal(claim, &multiple) == nil {
+		for _, candidate := range multiple {
+			if candidate == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
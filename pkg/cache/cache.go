@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/clock"
+)
+
+// Description:
+//
+//	A key-value cache with per-entry expiry.
+type Cache interface {
+
+	// Description:
+	//
+	//	Gets a cached value.
+	//
+	// Parameters:
+	//
+	//	key The key of the value.
+	//
+	// Returns:
+	//
+	//	The value, and whether a non-expired value was found.
+	Get(key string) (interface{}, bool)
+
+	// Description:
+	//
+	//	Caches a value.
+	//
+	// Parameters:
+	//
+	//	key 	The key of the value.
+	//	value 	The value to cache.
+	//	ttl 	The time to live. Zero caches the value until it is deleted.
+	Set(key string, value interface{}, ttl time.Duration)
+
+	// Description:
+	//
+	//	Deletes a cached value.
+	//
+	// Parameters:
+	//
+	//	key The key of the value.
+	Delete(key string)
+}
+
+// Description:
+//
+//	A cached value.
+type entry struct {
+
+	// The cached value.
+	value interface{}
+
+	// The expiry time. Zero if the entry does not expire.
+	expires time.Time
+}
+
+// Description:
+//
+//	An in-memory cache, local to the process.
+//	Expired entries are removed lazily on access.
+type MemoryCache struct {
+
+	// Guards the entries.
+	mutex sync.Mutex
+
+	// The cached entries.
+	entries map[string]entry
+
+	// The clock used for expiry.
+	clock clock.Clock
+}
+
+// Description:
+//
+//	Creates an empty in-memory cache.
+//
+// Parameters:
+//
+//	clock The clock used for expiry.
+//
+// Returns:
+//
+//	The created cache.
+func NewMemoryCache(clock clock.Clock) *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]entry),
+		clock:   clock,
+	}
+}
+
+// Description:
+//
+//	Gets a cached value.
+//
+// Parameters:
+//
+//	key The key of the value.
+//
+// Returns:
+//
+//	The value, and whether a non-expired value was found.
+func (cache *MemoryCache) Get(key string) (interface{}, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cached, ok := cache.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !cached.expires.IsZero() && !cache.clock.Now().Before(cached.expires) {
+		delete(cache.entries, key)
+		return nil, false
+	}
+
+	return cached.value, true
+}
+
+// Description:
+//
+//	Caches a value.
+//
+// Parameters:
+//
+//	key 	The key of the value.
+//	value 	The value to cache.
+//	ttl 	The time to live. Zero caches the value until it is deleted.
+func (cache *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
+	cached := entry{value: value}
+
+	if ttl > 0 {
+		cached.expires = cache.clock.Now().Add(ttl)
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.entries[key] = cached
+}
+
+// Description:
+//
+//	Deletes a cached value.
+//
+// Parameters:
+//
+//	key The key of the value.
+func (cache *MemoryCache) Delete(key string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	delete(cache.entries, key)
+}
@@ -0,0 +1,81 @@
+package clock
+
+import "time"
+
+// Description:
+//
+//	A source of the current time.
+//	Allows replacing the system clock in tests.
+type Clock interface {
+
+	// Description:
+	//
+	//	Gets the current time.
+	//
+	// Returns:
+	//
+	//	The current time.
+	Now() time.Time
+}
+
+// Description:
+//
+//	The system clock.
+type systemClock struct{}
+
+// Description:
+//
+//	A clock which always returns the same time.
+type fixedClock struct {
+
+	// The returned time.
+	time time.Time
+}
+
+// Description:
+//
+//	Gets the system clock.
+//
+// Returns:
+//
+//	The system clock.
+func System() Clock {
+	return systemClock{}
+}
+
+// Description:
+//
+//	Creates a clock which always returns the given time.
+//
+// Parameters:
+//
+//	time The returned time.
+//
+// Returns:
+//
+//	The fixed clock.
+func Fixed(time time.Time) Clock {
+	return fixedClock{time: time}
+}
+
+// Description:
+//
+//	Gets the current system time.
+//
+// Returns:
+//
+//	The current time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Description:
+//
+//	Gets the fixed time.
+//
+// Returns:
+//
+//	The fixed time.
+func (clock fixedClock) Now() time.Time {
+	return clock.time
+}
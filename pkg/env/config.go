@@ -0,0 +1,538 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+
+	// The struct tag holding the environment variable name of a field.
+	TagEnv = "env"
+
+	// The struct tag holding the default value of a field.
+	TagDefault = "default"
+
+	// The struct tag marking a field as required.
+	TagRequired = "required"
+
+	// The struct tag marking a field as secret, i.e. it is redacted when dumped.
+	TagSecret = "secret"
+
+	// The suffix of environment variables which refer to a file containing the actual value.
+	FileSuffix = "_FILE"
+
+	// The placeholder printed instead of secret values.
+	RedactedValue = "******"
+)
+
+// Description:
+//
+//	A single effective configuration value.
+type Setting struct {
+
+	// The environment variable name of the value, or its field path if it has none.
+	Name string
+
+	// The rendered value. Redacted for secret values.
+	Value string
+}
+
+// Description:
+//
+//	The options used for loading a configuration.
+type LoadOptions struct {
+
+	// The path of an optional YAML or TOML configuration file.
+	// If empty, no configuration file is read.
+	FilePath string
+
+	// The function used to look up environment variables.
+	// Defaults to os.LookupEnv if nil.
+	Lookup func(name string) (string, bool)
+}
+
+// Description:
+//
+//	Populates the given configuration struct.
+//	Values are applied in layers, where each layer overrides the previous one:
+//
+//	 1. default values, declared via the `default` struct tag
+//	 2. the optional YAML or TOML configuration file
+//	 3. environment variables, declared via the `env` struct tag
+//	 4. secret files, referenced via environment variables with the `_FILE` suffix
+//
+//	Fields tagged as `required:"true"` must hold a non-zero value after all layers were applied.
+//
+// Parameters:
+//
+//	target 	A pointer to the configuration struct to populate.
+//	options The load options.
+//
+// Returns:
+//
+//	An error if a value cannot be converted, a file cannot be read or a required field is missing.
+func Load(target interface{}, options LoadOptions) error {
+	value := reflect.ValueOf(target)
+
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("env: configuration target must be a pointer to a struct")
+	}
+
+	lookup := options.Lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	err := applyDefaults(value.Elem())
+	if err != nil {
+		return err
+	}
+
+	if options.FilePath != "" {
+		err = loadFile(options.FilePath, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	return walkFields(value.Elem(), "", func(field reflect.StructField, fieldValue reflect.Value, path string) error {
+		name := field.Tag.Get(TagEnv)
+
+		if name != "" {
+			raw, ok, err := lookupVariable(name, lookup)
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				err = setFieldValue(fieldValue, raw, path)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if field.Tag.Get(TagRequired) == "true" && fieldValue.IsZero() {
+			if name != "" {
+				return fmt.Errorf("env: missing required configuration value: %s (%s)", path, name)
+			}
+
+			return fmt.Errorf("env: missing required configuration value: %s", path)
+		}
+
+		return nil
+	})
+}
+
+// Description:
+//
+//	Populates the given configuration struct with its default values only,
+//	ignoring configuration files and environment variables. Required fields are not checked.
+//
+// Parameters:
+//
+//	target A pointer to the configuration struct to populate.
+//
+// Returns:
+//
+//	An error if a default value cannot be converted.
+func Defaults(target interface{}) error {
+	value := reflect.ValueOf(target)
+
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("env: configuration target must be a pointer to a struct")
+	}
+
+	return applyDefaults(value.Elem())
+}
+
+// Description:
+//
+//	Applies the default values, declared via the `default` struct tag.
+//
+// Parameters:
+//
+//	value The configuration struct.
+//
+// Returns:
+//
+//	An error if a default value cannot be converted.
+func applyDefaults(value reflect.Value) error {
+	return walkFields(value, "", func(field reflect.StructField, fieldValue reflect.Value, path string) error {
+		defaultValue, ok := field.Tag.Lookup(TagDefault)
+		if !ok {
+			return nil
+		}
+
+		return setFieldValue(fieldValue, defaultValue, path)
+	})
+}
+
+// Description:
+//
+//	Renders the effective configuration as human readable text.
+//	Each line holds a single value. Secret values are redacted.
+//
+// Parameters:
+//
+//	target The configuration struct, or a pointer to it.
+//
+// Returns:
+//
+//	The rendered configuration.
+func Dump(target interface{}) string {
+	lines := make([]string, 0)
+
+	for _, setting := range Settings(target) {
+		lines = append(lines, fmt.Sprintf("%s=%s", setting.Name, setting.Value))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Description:
+//
+//	Lists the effective configuration values in declaration order.
+//	Secret values are redacted.
+//
+// Parameters:
+//
+//	target The configuration struct, or a pointer to it.
+//
+// Returns:
+//
+//	The configuration values.
+func Settings(target interface{}) []Setting {
+	settings := make([]Setting, 0)
+	value := reflect.Indirect(reflect.ValueOf(target))
+
+	if value.Kind() != reflect.Struct {
+		return settings
+	}
+
+	walkFields(value, "", func(field reflect.StructField, fieldValue reflect.Value, path string) error {
+		name := field.Tag.Get(TagEnv)
+		if name == "" {
+			name = path
+		}
+
+		rendered := fmt.Sprintf("%v", fieldValue.Interface())
+
+		if field.Tag.Get(TagSecret) == "true" && !fieldValue.IsZero() {
+			rendered = RedactedValue
+		}
+
+		settings = append(settings, Setting{
+			Name:  name,
+			Value: rendered,
+		})
+
+		return nil
+	})
+
+	return settings
+}
+
+// Description:
+//
+//	Looks up an environment variable, honouring the `_FILE` indirection.
+//	If the variable itself and its file variant are both set, an error is returned.
+//
+// Parameters:
+//
+//	name 	The name of the environment variable.
+//	lookup 	The lookup function.
+//
+// Returns:
+//
+//	The value, whether a value was found, and an error if the secret file cannot be read.
+func lookupVariable(name string, lookup func(string) (string, bool)) (string, bool, error) {
+	value, exists := lookup(name)
+	path, fileExists := lookup(name + FileSuffix)
+
+	if exists && fileExists {
+		return "", false, fmt.Errorf("env: both %s and %s%s are set", name, name, FileSuffix)
+	}
+
+	if !fileExists {
+		return value, exists, nil
+	}
+
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("env: cannot read secret file for %s: %s", name, err)
+	}
+
+	return strings.TrimRight(string(bytes), "\r\n"), true, nil
+}
+
+// Description:
+//
+//	Decodes a YAML or TOML configuration file into the target.
+//	The format is derived from the file extension.
+//
+// Parameters:
+//
+//	path 	The path of the configuration file.
+//	target 	A pointer to the configuration struct.
+//
+// Returns:
+//
+//	An error if the file cannot be read or decoded.
+func loadFile(path string, target interface{}) error {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("env: cannot read configuration file: %s", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(bytes, target)
+	case ".toml":
+		err = decodeTOML(bytes, target)
+	default:
+		return fmt.Errorf("env: unsupported configuration file format: %s", path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("env: cannot decode configuration file: %s", err)
+	}
+
+	return nil
+}
+
+// Description:
+//
+//	Decodes a TOML document into the target.
+//	TOML has no duration type, so durations are given as strings, e.g. "15s", like in YAML files.
+//	These are converted before decoding, as the TOML decoder cannot store strings into durations.
+//
+// Parameters:
+//
+//	bytes 	The TOML document.
+//	target 	A pointer to the configuration struct.
+//
+// Returns:
+//
+//	An error if the document cannot be decoded.
+func decodeTOML(bytes []byte, target interface{}) error {
+	document := make(map[string]interface{})
+
+	err := toml.Unmarshal(bytes, &document)
+	if err != nil {
+		return err
+	}
+
+	err = convertDurations(document, reflect.TypeOf(target).Elem())
+	if err != nil {
+		return err
+	}
+
+	converted, err := toml.Marshal(document)
+	if err != nil {
+		return err
+	}
+
+	return toml.Unmarshal(converted, target)
+}
+
+// Description:
+//
+//	Replaces duration strings of a decoded TOML document by their nanoseconds.
+//
+// Parameters:
+//
+//	document 	The decoded TOML table.
+//	structType 	The struct type the table is decoded into.
+//
+// Returns:
+//
+//	An error if a duration string is invalid.
+func convertDurations(document map[string]interface{}, structType reflect.Type) error {
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+
+		name := strings.Split(field.Tag.Get("toml"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+
+		// Keys are matched case-insensitively, as go-toml decodes them.
+		for key, value := range document {
+			if !strings.EqualFold(key, name) {
+				continue
+			}
+
+			if field.Type == reflect.TypeOf(time.Duration(0)) {
+				raw, ok := value.(string)
+				if !ok {
+					continue
+				}
+
+				duration, err := time.ParseDuration(raw)
+				if err != nil {
+					return fmt.Errorf("invalid duration for %s: %s", key, err)
+				}
+
+				document[key] = int64(duration)
+				continue
+			}
+
+			table, ok := value.(map[string]interface{})
+			if ok && field.Type.Kind() == reflect.Struct {
+				err := convertDurations(table, field.Type)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Description:
+//
+//	Visits all leaf fields of a struct, descending into nested structs.
+//	Unexported fields are skipped.
+//
+// Parameters:
+//
+//	value 	The struct value.
+//	prefix 	The field path of the struct.
+//	visit 	The function called for every leaf field.
+//
+// Returns:
+//
+//	The first error returned by the visit function.
+func walkFields(value reflect.Value, prefix string, visit func(reflect.StructField, reflect.Value, string) error) error {
+	structType := value.Type()
+
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		path := field.Name
+		if prefix != "" {
+			path = prefix + "." + field.Name
+		}
+
+		fieldValue := value.Field(index)
+
+		if fieldValue.Kind() == reflect.Struct && fieldValue.Type() != reflect.TypeOf(time.Time{}) {
+			err := walkFields(fieldValue, path, visit)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err := visit(field, fieldValue, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Description:
+//
+//	Converts a raw string and assigns it to the given field.
+//
+// Parameters:
+//
+//	field 	The field to assign.
+//	raw 	The raw string value.
+//	path 	The field path, used for error messages.
+//
+// Returns:
+//
+//	An error if the value cannot be converted to the field type.
+func setFieldValue(field reflect.Value, raw string, path string) error {
+	fail := func(err error) error {
+		return fmt.Errorf("env: invalid value for %s: %s", path, err)
+	}
+
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fail(err)
+		}
+
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fail(err)
+		}
+
+		field.SetBool(parsed)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return fail(err)
+		}
+
+		field.SetInt(parsed)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return fail(err)
+		}
+
+		field.SetUint(parsed)
+
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return fail(err)
+		}
+
+		field.SetFloat(parsed)
+
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fail(fmt.Errorf("unsupported slice type %s", field.Type()))
+		}
+
+		items := make([]string, 0)
+
+		for _, item := range strings.Split(raw, ",") {
+			item = strings.TrimSpace(item)
+
+			if item != "" {
+				items = append(items, item)
+			}
+		}
+
+		field.Set(reflect.ValueOf(items).Convert(field.Type()))
+
+	default:
+		return fail(fmt.Errorf("unsupported type %s", field.Type()))
+	}
+
+	return nil
+}
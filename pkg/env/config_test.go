@@ -0,0 +1,269 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Description:
+//
+//	The configuration struct used by the tests.
+type testConfig struct {
+
+	// The service name.
+	Name string `env:"NAME" default:"albums" yaml:"name" toml:"name"`
+
+	// The server settings.
+	Server struct {
+
+		// The port.
+		Port int `env:"PORT" default:"8080" yaml:"port" toml:"port"`
+
+		// The request timeout.
+		Timeout time.Duration `env:"TIMEOUT" default:"5s" yaml:"timeout" toml:"timeout"`
+
+		// The timeout for reading requests.
+		ReadTimeout time.Duration `env:"READ_TIMEOUT" default:"1s" yaml:"readTimeout" toml:"readTimeout"`
+	} `yaml:"server" toml:"server"`
+
+	// Whether debugging is enabled.
+	Debug bool `env:"DEBUG" default:"false" yaml:"debug" toml:"debug"`
+
+	// The sample ratio.
+	Ratio float64 `env:"RATIO" default:"0.5" yaml:"ratio" toml:"ratio"`
+
+	// The allowed origins.
+	Origins []string `env:"ORIGINS" yaml:"origins" toml:"origins"`
+
+	// The database password.
+	Password string `env:"PASSWORD" required:"true" secret:"true" yaml:"password" toml:"password"`
+}
+
+// Description:
+//
+//	Creates a lookup function over a fixed set of variables.
+//
+// Parameters:
+//
+//	variables The environment variables.
+//
+// Returns:
+//
+//	The lookup function.
+func lookupFrom(variables map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := variables[name]
+		return value, ok
+	}
+}
+
+// Description:
+//
+//	Writes a file into a temporary directory.
+//
+// Parameters:
+//
+//	t 		The test.
+//	name 	The file name.
+//	content The file content.
+//
+// Returns:
+//
+//	The path of the file.
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write %s: %s", name, err)
+	}
+
+	return path
+}
+
+func TestLoadAppliesDefaults(t *testing.T) {
+	config := testConfig{}
+
+	err := Load(&config, LoadOptions{Lookup: lookupFrom(map[string]string{"PASSWORD": "secret"})})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if config.Name != "albums" || config.Server.Port != 8080 || config.Server.Timeout != 5*time.Second || config.Debug || config.Ratio != 0.5 {
+		t.Fatalf("expected the default values, got %+v", config)
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": "name: from-file\nserver:\n  port: 9000\n  timeout: 10s\norigins: [a, b]\npassword: file\n",
+		"config.toml": "name = \"from-file\"\norigins = [\"a\", \"b\"]\npassword = \"file\"\n\n[server]\nport = 9000\ntimeout = \"10s\"\n",
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			config := testConfig{}
+
+			err := Load(&config, LoadOptions{
+				FilePath: writeFile(t, name, content),
+				Lookup:   lookupFrom(map[string]string{"PORT": "9100", "DEBUG": "true"}),
+			})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if config.Name != "from-file" || config.Server.Timeout != 10*time.Second || strings.Join(config.Origins, ",") != "a,b" || config.Password != "file" {
+				t.Fatalf("expected the file values, got %+v", config)
+			}
+
+			if config.Server.Port != 9100 || !config.Debug {
+				t.Fatalf("expected environment variables to override the file, got %+v", config)
+			}
+
+			if config.Ratio != 0.5 {
+				t.Fatalf("expected defaults to remain for unset values, got %+v", config)
+			}
+		})
+	}
+}
+
+func TestLoadMatchesTOMLKeysCaseInsensitively(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "tag case", content: "password = \"file\"\n\n[server]\nreadTimeout = \"5s\"\n"},
+		{name: "lower case", content: "password = \"file\"\n\n[server]\nreadtimeout = \"5s\"\n"},
+		{name: "upper case", content: "PASSWORD = \"file\"\n\n[SERVER]\nREADTIMEOUT = \"5s\"\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := testConfig{}
+
+			err := Load(&config, LoadOptions{
+				FilePath: writeFile(t, "config.toml", test.content),
+				Lookup:   lookupFrom(map[string]string{}),
+			})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if config.Server.ReadTimeout != 5*time.Second || config.Password != "file" {
+				t.Fatalf("expected the file values, got %+v", config)
+			}
+		})
+	}
+}
+
+func TestLoadConvertsTypes(t *testing.T) {
+	config := testConfig{}
+
+	err := Load(&config, LoadOptions{Lookup: lookupFrom(map[string]string{
+		"PORT":     "81",
+		"TIMEOUT":  "1m30s",
+		"DEBUG":    "1",
+		"RATIO":    "0.25",
+		"ORIGINS":  " a, ,b ",
+		"PASSWORD": "secret",
+	})})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if config.Server.Port != 81 || config.Server.Timeout != 90*time.Second || !config.Debug || config.Ratio != 0.25 {
+		t.Fatalf("unexpected converted values %+v", config)
+	}
+
+	if strings.Join(config.Origins, ",") != "a,b" {
+		t.Fatalf("expected trimmed list items, got %q", config.Origins)
+	}
+
+	for name, value := range map[string]string{"PORT": "eighty", "TIMEOUT": "5", "DEBUG": "maybe", "RATIO": "half"} {
+		err := Load(&testConfig{}, LoadOptions{Lookup: lookupFrom(map[string]string{name: value, "PASSWORD": "secret"})})
+		if err == nil || !strings.Contains(err.Error(), "invalid value") {
+			t.Fatalf("expected a conversion error for %s=%s, got %v", name, value, err)
+		}
+	}
+}
+
+func TestLoadReadsSecretFiles(t *testing.T) {
+	config := testConfig{}
+	path := writeFile(t, "password", "from-file\n")
+
+	err := Load(&config, LoadOptions{Lookup: lookupFrom(map[string]string{"PASSWORD_FILE": path})})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if config.Password != "from-file" {
+		t.Fatalf("expected the secret file value without trailing newline, got %q", config.Password)
+	}
+
+	err = Load(&testConfig{}, LoadOptions{Lookup: lookupFrom(map[string]string{"PASSWORD": "plain", "PASSWORD_FILE": path})})
+	if err == nil {
+		t.Fatalf("expected an error if a variable and its file variant are both set")
+	}
+
+	err = Load(&testConfig{}, LoadOptions{Lookup: lookupFrom(map[string]string{"PASSWORD_FILE": path + ".missing"})})
+	if err == nil {
+		t.Fatalf("expected an error for a missing secret file")
+	}
+}
+
+func TestLoadValidatesRequiredValues(t *testing.T) {
+	err := Load(&testConfig{}, LoadOptions{Lookup: lookupFrom(nil)})
+	if err == nil || !strings.Contains(err.Error(), "Password (PASSWORD)") {
+		t.Fatalf("expected a missing required value error, got %v", err)
+	}
+
+	err = Load(testConfig{}, LoadOptions{Lookup: lookupFrom(nil)})
+	if err == nil {
+		t.Fatalf("expected an error for a non-pointer target")
+	}
+
+	err = Load(&testConfig{}, LoadOptions{FilePath: writeFile(t, "config.json", "{}"), Lookup: lookupFrom(nil)})
+	if err == nil || !strings.Contains(err.Error(), "unsupported configuration file format") {
+		t.Fatalf("expected an unsupported format error, got %v", err)
+	}
+}
+
+func TestDefaultsIgnoresRequiredValues(t *testing.T) {
+	config := testConfig{}
+
+	err := Defaults(&config)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if config.Name != "albums" || config.Password != "" {
+		t.Fatalf("expected the default values only, got %+v", config)
+	}
+}
+
+func TestDumpRedactsSecrets(t *testing.T) {
+	config := testConfig{}
+	_ = Defaults(&config)
+	config.Password = "hunter2"
+
+	dump := Dump(&config)
+
+	if strings.Contains(dump, "hunter2") || !strings.Contains(dump, "PASSWORD="+RedactedValue) {
+		t.Fatalf("expected the secret to be redacted, got %s", dump)
+	}
+
+	if !strings.HasPrefix(dump, "NAME=albums\nPORT=8080\nTIMEOUT=5s\n") {
+		t.Fatalf("expected the values in declaration order, got %s", dump)
+	}
+
+	config.Password = ""
+	if !strings.Contains(Dump(config), "PASSWORD=\n") && !strings.HasSuffix(Dump(config), "PASSWORD=") {
+		t.Fatalf("expected empty secrets to be shown as empty, got %s", Dump(config))
+	}
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"context"
+
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/parallel"
+)
+
+// Description:
+//
+//	A publisher of domain events, e.g. to a message broker.
+type Publisher interface {
+
+	// Description:
+	//
+	//	Publishes an event.
+	//
+	// Parameters:
+	//
+	//	ctx 	The request context.
+	//	topic 	The topic of the event, e.g. albums.created.
+	//	payload The event payload.
+	//
+	// Returns:
+	//
+	//	An error if publishing fails.
+	Publish(ctx context.Context, topic string, payload interface{}) error
+}
+
+// Description:
+//
+//	A publisher which writes events to the log instead of a broker.
+type logPublisher struct {
+
+	// The logger events are written to.
+	logger *logging.Logger
+}
+
+// Description:
+//
+//	Creates a publisher which writes events to the "events" logger at debug level.
+//	Used while no broker is configured.
+//
+// Returns:
+//
+//	The log publisher.
+func LogPublisher() Publisher {
+	return logPublisher{logger: logging.Named("events")}
+}
+
+// Description:
+//
+//	Writes the event to the log.
+//
+// Parameters:
+//
+//	ctx 	The request context.
+//	topic 	The topic of the event.
+//	payload The event payload.
+//
+// Returns:
+//
+//	Always nil.
+func (publisher logPublisher) Publish(ctx context.Context, topic string, payload interface{}) error {
+	publisher.logger.Log(logging.LevelDebug, "event published",
+		logging.F("requestId", parallel.IDFromContext(ctx)),
+		logging.F("topic", topic),
+		logging.F("payload", payload),
+	)
+
+	return nil
+}
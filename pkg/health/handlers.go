@@ -0,0 +1,139 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/router"
+)
+
+// Description:
+//
+//	The response body of the probe endpoints.
+type ProbeResponseBody struct {
+
+	// The status of the probe.
+	Status Status `json:"status"`
+
+	// The names of the failed checks, if any.
+	Failed []string `json:"failed,omitempty"`
+}
+
+// Description:
+//
+//	Creates the liveness handler.
+//	Responds with 200 as long as the process serves requests.
+//
+// Returns:
+//
+//	The router handler, e.g. to be registered for GET /healthz.
+func (checker *Checker) LivenessHandler() router.RouterHandlerFunc {
+	return func(request *api.APIRequest) *api.APIResponse {
+		return probeResponse(true, nil)
+	}
+}
+
+// Description:
+//
+//	Creates the startup handler.
+//	Responds with 200 once all startup checks passed, 503 otherwise.
+//
+// Returns:
+//
+//	The router handler, e.g. to be registered for GET /startupz.
+func (checker *Checker) StartupHandler() router.RouterHandlerFunc {
+	return func(request *api.APIRequest) *api.APIResponse {
+		started, results := checker.Started(request.Context)
+		return probeResponse(started, failedChecks(results))
+	}
+}
+
+// Description:
+//
+//	Creates the readiness handler.
+//	Responds with 200 if the service started, all checks pass and the service is not shutting down, 503 otherwise.
+//
+// Returns:
+//
+//	The router handler, e.g. to be registered for GET /readyz.
+func (checker *Checker) ReadinessHandler() router.RouterHandlerFunc {
+	return func(request *api.APIRequest) *api.APIResponse {
+		report := checker.Report(request.Context)
+		failed := failedChecks(report.Checks)
+
+		if report.ShuttingDown {
+			failed = append(failed, "shutdown")
+		}
+
+		if !report.Started {
+			failed = append(failed, "startup")
+		}
+
+		return probeResponse(report.Status == StatusUp, failed)
+	}
+}
+
+// Description:
+//
+//	Creates the handler of the detailed health report.
+//	Responds with the report and 200 if the service is ready, 503 otherwise.
+//
+// Returns:
+//
+//	The router handler, e.g. to be registered for GET /health.
+func (checker *Checker) ReportHandler() router.RouterHandlerFunc {
+	return func(request *api.APIRequest) *api.APIResponse {
+		report := checker.Report(request.Context)
+
+		return &api.APIResponse{
+			StatusCode: statusCode(report.Status == StatusUp),
+			Body:       report,
+		}
+	}
+}
+
+// Description:
+//
+//	Creates the response of a probe endpoint.
+//
+// Parameters:
+//
+//	up 		Whether the probe passed.
+//	failed 	The names of the failed checks.
+//
+// Returns:
+//
+//	The probe response.
+func probeResponse(up bool, failed []string) *api.APIResponse {
+	status := StatusUp
+	if !up {
+		status = StatusDown
+	}
+
+	return &api.APIResponse{
+		StatusCode: statusCode(up),
+		Body: ProbeResponseBody{
+			Status: status,
+			Failed: failed,
+		},
+	}
+}
+
+// Description:
+//
+//	Maps a probe result to a status code.
+//
+// Parameters:
+//
+//	up Whether the probe passed.
+//
+// Returns:
+//
+//	200 if the probe passed, 503 otherwise.
+func statusCode(up bool) int {
+	if up {
+		return http.StatusOK
+	}
+
+	return http.StatusServiceUnavailable
+}
@@ -0,0 +1,393 @@
+package health
+
+import (
+	"context"
+	"encoding/xml"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Description:
+//
+//	The status of a check or of the service.
+type Status = string
+
+const (
+
+	// The check passed.
+	StatusUp Status = "up"
+
+	// The check failed.
+	StatusDown Status = "down"
+)
+
+// Description:
+//
+//	Function definition for health checks.
+//	A check passes if it returns nil within the timeout of the checker.
+type Check = func(ctx context.Context) error
+
+// Description:
+//
+//	The result of a single check.
+type CheckResult struct {
+
+	// The status of the check.
+	Status Status `json:"status" xml:"status"`
+
+	// The duration of the check in milliseconds.
+	LatencyMs float64 `json:"latencyMs" xml:"latencyMs"`
+
+	// The error of the check. Empty if the check passed.
+	Error string `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+// Description:
+//
+//	The detailed health report of the service.
+type Report struct {
+
+	// The overall status. Down if any check failed, the service did not start or is shutting down.
+	Status Status `json:"status" xml:"status"`
+
+	// Whether all startup checks passed once.
+	Started bool `json:"started" xml:"started"`
+
+	// Whether the service is shutting down.
+	ShuttingDown bool `json:"shuttingDown" xml:"shuttingDown"`
+
+	// The results of all checks, keyed by the check name.
+	// Encoded as a list of check elements in XML, see MarshalXML.
+	Checks map[string]CheckResult `json:"checks" xml:"-"`
+}
+
+// Description:
+//
+//	The fields of a report, without its XML encoding.
+type reportFields Report
+
+// Description:
+//
+//	The XML representation of a report, as XML cannot represent maps.
+type xmlReport struct {
+
+	// The name of the root element.
+	XMLName xml.Name `xml:"Report"`
+
+	// The report. Its checks are replaced by the list below.
+	reportFields
+
+	// The results of all checks, ordered by name.
+	Checks []xmlCheckResult `xml:"checks>check"`
+}
+
+// Description:
+//
+//	The XML representation of a named check result.
+type xmlCheckResult struct {
+
+	// The name of the check.
+	Name string `xml:"name,attr"`
+
+	// The result of the check.
+	CheckResult
+}
+
+// Description:
+//
+//	Encodes the report as XML, with the checks as a list ordered by name.
+//
+// Parameters:
+//
+//	encoder The XML encoder.
+//	start 	The start element of the report.
+//
+// Returns:
+//
+//	An error if encoding fails.
+func (report *Report) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	names := make([]string, 0, len(report.Checks))
+	for name := range report.Checks {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	checks := make([]xmlCheckResult, 0, len(names))
+	for _, name := range names {
+		checks = append(checks, xmlCheckResult{Name: name, CheckResult: report.Checks[name]})
+	}
+
+	return encoder.EncodeElement(xmlReport{reportFields: reportFields(*report), Checks: checks}, start)
+}
+
+// Description:
+//
+//	A named check.
+type namedCheck struct {
+
+	// The name of the check.
+	name string
+
+	// The check.
+	check Check
+}
+
+// Description:
+//
+//	Runs the health checks of the service.
+//	Safe for concurrent use once all checks are registered.
+type Checker struct {
+
+	// The maximum duration of a single check.
+	timeout time.Duration
+
+	// The checks which must pass for the service to be ready.
+	checks []namedCheck
+
+	// The checks which must pass once for the service to be started.
+	startupChecks []namedCheck
+
+	// Whether all startup checks passed once.
+	started atomic.Bool
+
+	// Whether the service is shutting down.
+	shuttingDown atomic.Bool
+}
+
+// Description:
+//
+//	Creates a new checker.
+//
+// Parameters:
+//
+//	timeout The maximum duration of a single check.
+//
+// Returns:
+//
+//	The created checker.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+	}
+}
+
+// Description:
+//
+//	Registers a check which must pass for the service to be ready, e.g. a database ping.
+//	Checks must be registered before the checker is used concurrently.
+//
+// Parameters:
+//
+//	name 	The name of the check.
+//	check 	The check.
+func (checker *Checker) Register(name string, check Check) {
+	checker.checks = append(checker.checks, namedCheck{name: name, check: check})
+}
+
+// Description:
+//
+//	Registers a check which must pass once for the service to be started, e.g. applied migrations.
+//	Startup checks are also part of the readiness checks.
+//	Checks must be registered before the checker is used concurrently.
+//
+// Parameters:
+//
+//	name 	The name of the check.
+//	check 	The check.
+func (checker *Checker) RegisterStartup(name string, check Check) {
+	checker.startupChecks = append(checker.startupChecks, namedCheck{name: name, check: check})
+}
+
+// Description:
+//
+//	Marks the service as shutting down. Readiness fails from now on.
+//	Can be registered as drain hook of a router.
+func (checker *Checker) Drain() {
+	checker.shuttingDown.Store(true)
+}
+
+// Description:
+//
+//	Checks whether the service started, i.e. all startup checks passed once.
+//	Once passed, the startup checks are not run again.
+//
+// Parameters:
+//
+//	ctx The request context.
+//
+// Returns:
+//
+//	Whether the service started, and the results of the startup checks if they were run.
+func (checker *Checker) Started(ctx context.Context) (bool, map[string]CheckResult) {
+	if checker.started.Load() {
+		return true, nil
+	}
+
+	results := checker.run(ctx, checker.startupChecks)
+
+	if allUp(results) {
+		checker.started.Store(true)
+		return true, results
+	}
+
+	return false, results
+}
+
+// Description:
+//
+//	Runs all checks and creates the detailed health report.
+//
+// Parameters:
+//
+//	ctx The request context.
+//
+// Returns:
+//
+//	The health report.
+func (checker *Checker) Report(ctx context.Context) *Report {
+	started, _ := checker.Started(ctx)
+	checks := append(append([]namedCheck{}, checker.startupChecks...), checker.checks...)
+	results := checker.run(ctx, checks)
+
+	report := &Report{
+		Status:       StatusUp,
+		Started:      started,
+		ShuttingDown: checker.shuttingDown.Load(),
+		Checks:       results,
+	}
+
+	if !report.Started || report.ShuttingDown || !allUp(results) {
+		report.Status = StatusDown
+	}
+
+	return report
+}
+
+// Description:
+//
+//	Runs the given checks concurrently, each bounded by the timeout of the checker.
+//
+// Parameters:
+//
+//	ctx 	The request context.
+//	checks 	The checks to run.
+//
+// Returns:
+//
+//	The check results, keyed by the check name.
+func (checker *Checker) run(ctx context.Context, checks []namedCheck) map[string]CheckResult {
+	results := make(map[string]CheckResult, len(checks))
+
+	var group sync.WaitGroup
+	var mutex sync.Mutex
+
+	for _, named := range checks {
+		group.Add(1)
+
+		go func(named namedCheck) {
+			defer group.Done()
+
+			checkContext := ctx
+			if checker.timeout > 0 {
+				var cancel context.CancelFunc
+				checkContext, cancel = context.WithTimeout(ctx, checker.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			err := runCheck(checkContext, named.check)
+
+			result := CheckResult{
+				Status:    StatusUp,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+
+			if err != nil {
+				result.Status = StatusDown
+				result.Error = err.Error()
+			}
+
+			mutex.Lock()
+			results[named.name] = result
+			mutex.Unlock()
+		}(named)
+	}
+
+	group.Wait()
+	return results
+}
+
+// Description:
+//
+//	Runs a single check, failing it if it does not return before the context is done.
+//
+// Parameters:
+//
+//	ctx 	The context bounding the check.
+//	check 	The check to run.
+//
+// Returns:
+//
+//	The error of the check, or the context error on timeout.
+func runCheck(ctx context.Context, check Check) error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Description:
+//
+//	Checks whether all results are up.
+//
+// Parameters:
+//
+//	results The check results.
+//
+// Returns:
+//
+//	Whether all results are up.
+func allUp(results map[string]CheckResult) bool {
+	for _, result := range results {
+		if result.Status != StatusUp {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Description:
+//
+//	Gets the names of the failed checks.
+//
+// Parameters:
+//
+//	results The check results.
+//
+// Returns:
+//
+//	The sorted names of all failed checks.
+func failedChecks(results map[string]CheckResult) []string {
+	names := make([]string, 0)
+
+	for name, result := range results {
+		if result.Status != StatusUp {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/marshal"
+)
+
+// Description:
+//
+//	Creates a checker with a passing startup check and the given check.
+//
+// Parameters:
+//
+//	check The check.
+//
+// Returns:
+//
+//	The checker.
+func newChecker(check Check) *Checker {
+	checker := NewChecker(time.Second)
+
+	checker.RegisterStartup("migrations", func(ctx context.Context) error { return nil })
+	checker.Register("mongo", check)
+
+	return checker
+}
+
+// Description:
+//
+//	Creates a request.
+//
+// Returns:
+//
+//	The request.
+func newRequest() *api.APIRequest {
+	return &api.APIRequest{
+		Context: context.Background(),
+		Logger:  logging.Named("test"),
+	}
+}
+
+func TestReportHandler(t *testing.T) {
+	response := newChecker(func(ctx context.Context) error { return nil }).ReportHandler()(newRequest())
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", response.StatusCode)
+	}
+
+	response = newChecker(func(ctx context.Context) error { return errors.New("unreachable") }).ReportHandler()(newRequest())
+	if response.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", response.StatusCode)
+	}
+
+	report := response.Body.(*Report)
+	if report.Checks["mongo"].Status != StatusDown || report.Checks["mongo"].Error != "unreachable" {
+		t.Fatalf("expected the failed check in the report, got %+v", report.Checks)
+	}
+}
+
+func TestReportEncodesInEveryCodec(t *testing.T) {
+	response := newChecker(func(ctx context.Context) error { return errors.New("unreachable") }).ReportHandler()(newRequest())
+
+	for _, codec := range marshal.DefaultCodecs().All() {
+		data, err := codec.Marshal(response.Body)
+		if err != nil {
+			t.Fatalf("failed to encode the report as %s: %s", codec.MediaTypes()[0], err)
+		}
+
+		if len(data) == 0 {
+			t.Fatalf("expected a non-empty %s report", codec.MediaTypes()[0])
+		}
+	}
+
+	data, _ := marshal.XMLCodec{}.Marshal(response.Body)
+
+	expected := `<checks><check name="migrations"><status>up</status>`
+	if !strings.Contains(string(data), expected) || !strings.Contains(string(data), `<check name="mongo"><status>down</status>`) {
+		t.Fatalf("expected named check elements, got %s", data)
+	}
+}
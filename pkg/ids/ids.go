@@ -0,0 +1,102 @@
+package ids
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Description:
+//
+//	A generator of unique ids for new documents.
+//	Allows replacing random ids with predictable ones in tests.
+type Generator interface {
+
+	// Description:
+	//
+	//	Generates a new id.
+	//
+	// Returns:
+	//
+	//	The generated id.
+	NewID() string
+}
+
+// Description:
+//
+//	A generator of random UUIDs.
+type uuidGenerator struct{}
+
+// Description:
+//
+//	A generator returning the given ids in order.
+type sequenceGenerator struct {
+
+	// Guards the index.
+	mutex sync.Mutex
+
+	// The ids to return.
+	ids []string
+
+	// The index of the next id.
+	index int
+}
+
+// Description:
+//
+//	Gets the generator of random version 4 UUIDs.
+//
+// Returns:
+//
+//	The UUID generator.
+func UUID() Generator {
+	return uuidGenerator{}
+}
+
+// Description:
+//
+//	Creates a generator returning the given ids in order.
+//	Panics once all ids were returned.
+//
+// Parameters:
+//
+//	ids The ids to return.
+//
+// Returns:
+//
+//	The sequence generator.
+func Sequence(ids ...string) Generator {
+	return &sequenceGenerator{ids: ids}
+}
+
+// Description:
+//
+//	Generates a new random UUID.
+//
+// Returns:
+//
+//	The generated UUID.
+func (uuidGenerator) NewID() string {
+	return uuid.New().String()
+}
+
+// Description:
+//
+//	Gets the next id of the sequence.
+//
+// Returns:
+//
+//	The next id.
+func (generator *sequenceGenerator) NewID() string {
+	generator.mutex.Lock()
+	defer generator.mutex.Unlock()
+
+	if generator.index >= len(generator.ids) {
+		panic("ids: sequence exhausted")
+	}
+
+	id := generator.ids[generator.index]
+	generator.index++
+
+	return id
+}
@@ -0,0 +1,181 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Description:
+//
+//	A single log entry.
+type Entry struct {
+
+	// The time the entry was logged.
+	Time time.Time
+
+	// The severity of the entry.
+	Level Level
+
+	// The name of the logger, e.g. the package name.
+	Logger string
+
+	// The log message.
+	Message string
+
+	// The contextual fields.
+	Fields []Field
+}
+
+// Description:
+//
+//	Encodes log entries into their textual representation.
+type Encoder interface {
+
+	// Description:
+	//
+	//	Encodes a log entry, including the trailing newline.
+	//
+	// Parameters:
+	//
+	//	entry The entry to encode.
+	//
+	// Returns:
+	//
+	//	The encoded entry.
+	Encode(entry *Entry) []byte
+}
+
+// Description:
+//
+//	Encodes log entries as single line JSON objects.
+//	Fields are added as top level keys.
+type JSONEncoder struct{}
+
+// Description:
+//
+//	Encodes log entries as human readable lines.
+//	Fields are appended as key=value pairs.
+type ConsoleEncoder struct{}
+
+// Description:
+//
+//	Encodes a log entry as a single line JSON object.
+//
+// Parameters:
+//
+//	entry The entry to encode.
+//
+// Returns:
+//
+//	The encoded entry.
+func (JSONEncoder) Encode(entry *Entry) []byte {
+	buffer := &bytes.Buffer{}
+	buffer.WriteByte('{')
+
+	writeJSONField(buffer, "time", entry.Time.UTC().Format(time.RFC3339Nano), true)
+	writeJSONField(buffer, "level", entry.Level.String(), false)
+
+	if entry.Logger != "" {
+		writeJSONField(buffer, "logger", entry.Logger, false)
+	}
+
+	writeJSONField(buffer, "msg", entry.Message, false)
+
+	for _, field := range entry.Fields {
+		writeJSONField(buffer, field.Key, fieldValue(field.Value), false)
+	}
+
+	buffer.WriteString("}\n")
+	return buffer.Bytes()
+}
+
+// Description:
+//
+//	Encodes a log entry as a human readable line.
+//
+// Parameters:
+//
+//	entry The entry to encode.
+//
+// Returns:
+//
+//	The encoded entry.
+func (ConsoleEncoder) Encode(entry *Entry) []byte {
+	buffer := &bytes.Buffer{}
+
+	buffer.WriteString(entry.Time.Format("2006/01/02 15:04:05.000"))
+	buffer.WriteString(fmt.Sprintf(" %-5s ", strings.ToUpper(entry.Level.String())))
+
+	if entry.Logger != "" {
+		buffer.WriteString("[" + entry.Logger + "] ")
+	}
+
+	buffer.WriteString(entry.Message)
+
+	for _, field := range entry.Fields {
+		value := fmt.Sprintf("%v", fieldValue(field.Value))
+
+		if strings.ContainsAny(value, " \t\n\"=") {
+			value = fmt.Sprintf("%q", value)
+		}
+
+		buffer.WriteString(" " + field.Key + "=" + value)
+	}
+
+	buffer.WriteByte('\n')
+	return buffer.Bytes()
+}
+
+// Description:
+//
+//	Writes a JSON key-value pair.
+//
+// Parameters:
+//
+//	buffer 	The output buffer.
+//	key 	The key.
+//	value 	The value.
+//	first 	Whether this is the first pair of the object.
+func writeJSONField(buffer *bytes.Buffer, key string, value interface{}, first bool) {
+	if !first {
+		buffer.WriteByte(',')
+	}
+
+	keyBytes, _ := json.Marshal(key)
+	buffer.Write(keyBytes)
+	buffer.WriteByte(':')
+
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		valueBytes, _ = json.Marshal(fmt.Sprintf("%v", value))
+	}
+
+	buffer.Write(valueBytes)
+}
+
+// Description:
+//
+//	Converts field values which do not encode well, e.g. errors and durations.
+//
+// Parameters:
+//
+//	value The field value.
+//
+// Returns:
+//
+//	The converted value.
+func fieldValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case error:
+		return typed.Error()
+	case time.Duration:
+		return typed.String()
+	case fmt.Stringer:
+		return typed.String()
+	}
+
+	return value
+}
@@ -0,0 +1,122 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Description:
+//
+//	The severity of a log entry.
+type Level int
+
+const (
+
+	// The trace level. Used for very detailed diagnostics.
+	LevelTrace Level = iota
+
+	// The debug level. Used for diagnostics.
+	LevelDebug
+
+	// The info level. Used for regular operational messages.
+	LevelInfo
+
+	// The warn level. Used for unexpected, but handled situations.
+	LevelWarn
+
+	// The error level. Used for failures.
+	LevelError
+
+	// The fatal level. Used for failures which terminate the process.
+	LevelFatal
+)
+
+// Description:
+//
+//	Gets the lowercase name of the level.
+//
+// Returns:
+//
+//	The name of the level.
+func (level Level) String() string {
+	switch level {
+	case LevelTrace:
+		return "trace"
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	case LevelFatal:
+		return "fatal"
+	}
+
+	return fmt.Sprintf("level(%d)", int(level))
+}
+
+// Description:
+//
+//	Parses a level from its name. Parsing is case-insensitive.
+//
+// Parameters:
+//
+//	name The name of the level.
+//
+// Returns:
+//
+//	The parsed level, or an error if the name is unknown.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "trace":
+		return LevelTrace, nil
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	case "fatal":
+		return LevelFatal, nil
+	}
+
+	return LevelInfo, fmt.Errorf("logging: unknown level: %s", name)
+}
+
+// Description:
+//
+//	Parses per-logger level overrides.
+//
+// Example:
+//   - router=debug,store=warn
+//
+// Parameters:
+//
+//	overrides The comma separated list of name=level pairs.
+//
+// Returns:
+//
+//	The parsed overrides, or an error if an entry is malformed.
+func ParseLevelOverrides(overrides []string) (map[string]Level, error) {
+	result := make(map[string]Level)
+
+	for _, override := range overrides {
+		name, levelName, found := strings.Cut(override, "=")
+		if !found {
+			return nil, fmt.Errorf("logging: malformed level override: %s", override)
+		}
+
+		level, err := ParseLevel(levelName)
+		if err != nil {
+			return nil, err
+		}
+
+		result[strings.TrimSpace(name)] = level
+	}
+
+	return result, nil
+}
@@ -0,0 +1,367 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Description:
+//
+//	A contextual key-value pair attached to log entries.
+type Field struct {
+
+	// The key of the field.
+	Key string
+
+	// The value of the field.
+	Value interface{}
+}
+
+// Description:
+//
+//	The logging configuration.
+type Config struct {
+
+	// The encoder used for log entries. Defaults to the console encoder.
+	Encoder Encoder
+
+	// The output log entries are written to. Defaults to stdout.
+	Output io.Writer
+
+	// The minimum level of entries to log.
+	Level Level
+
+	// Per-logger level overrides, keyed by logger name.
+	Levels map[string]Level
+}
+
+// Description:
+//
+//	The shared logging backend of all loggers.
+type core struct {
+
+	// The logging configuration.
+	config Config
+
+	// Serializes writes to the output.
+	mutex sync.Mutex
+}
+
+// Description:
+//
+//	A structured logger.
+//	Loggers are cheap to derive and safe for concurrent use.
+//	A nil logger logs via the root logger.
+type Logger struct {
+
+	// The name of the logger, used for level overrides.
+	name string
+
+	// The contextual fields attached to all entries.
+	fields []Field
+}
+
+// Description:
+//
+//	The active logging backend.
+var active atomic.Pointer[core]
+
+// Description:
+//
+//	The root logger.
+var root = &Logger{}
+
+// Description:
+//
+//	Package initializer.
+//	Configures console logging at info level.
+func init() {
+	Configure(Config{
+		Level: LevelInfo,
+	})
+}
+
+// Description:
+//
+//	Replaces the logging configuration.
+//	Applies to all loggers, including the ones created before.
+//
+// Parameters:
+//
+//	config The logging configuration.
+func Configure(config Config) {
+	if config.Encoder == nil {
+		config.Encoder = ConsoleEncoder{}
+	}
+
+	if config.Output == nil {
+		config.Output = os.Stdout
+	}
+
+	active.Store(&core{
+		config: config,
+	})
+}
+
+// Description:
+//
+//	Creates a field.
+//
+// Parameters:
+//
+//	key 	The key of the field.
+//	value 	The value of the field.
+//
+// Returns:
+//
+//	The created field.
+func F(key string, value interface{}) Field {
+	return Field{
+		Key:   key,
+		Value: value,
+	}
+}
+
+// Description:
+//
+//	Gets the root logger.
+//
+// Returns:
+//
+//	The root logger.
+func Root() *Logger {
+	return root
+}
+
+// Description:
+//
+//	Creates a named logger. Names are used for per-logger level overrides,
+//	typically the name of the package the logger is used in.
+//
+// Parameters:
+//
+//	name The name of the logger.
+//
+// Returns:
+//
+//	The created logger.
+func Named(name string) *Logger {
+	return &Logger{
+		name: name,
+	}
+}
+
+// Description:
+//
+//	Derives a logger with additional contextual fields.
+//
+// Parameters:
+//
+//	fields The fields to attach.
+//
+// Returns:
+//
+//	The derived logger.
+func (logger *Logger) With(fields ...Field) *Logger {
+	logger = logger.orRoot()
+
+	combined := make([]Field, 0, len(logger.fields)+len(fields))
+	combined = append(combined, logger.fields...)
+	combined = append(combined, fields...)
+
+	return &Logger{
+		name:   logger.name,
+		fields: combined,
+	}
+}
+
+// Description:
+//
+//	Derives a logger with another name, keeping the contextual fields.
+//
+// Parameters:
+//
+//	name The name of the derived logger.
+//
+// Returns:
+//
+//	The derived logger.
+func (logger *Logger) Named(name string) *Logger {
+	logger = logger.orRoot()
+
+	return &Logger{
+		name:   name,
+		fields: logger.fields,
+	}
+}
+
+// Description:
+//
+//	Checks whether entries of the given level are logged by this logger.
+//
+// Parameters:
+//
+//	level The level to check.
+//
+// Returns:
+//
+//	True, if entries of the given level are logged.
+func (logger *Logger) Enabled(level Level) bool {
+	logger = logger.orRoot()
+	config := &active.Load().config
+
+	minimum, ok := config.Levels[logger.name]
+	if !ok {
+		minimum = config.Level
+	}
+
+	return level >= minimum
+}
+
+// Description:
+//
+//	Logs a message with additional fields.
+//
+// Parameters:
+//
+//	level 	The level of the entry.
+//	message The message.
+//	fields 	Additional fields for this entry only.
+func (logger *Logger) Log(level Level, message string, fields ...Field) {
+	logger = logger.orRoot()
+
+	if !logger.Enabled(level) {
+		return
+	}
+
+	entry := &Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Logger:  logger.name,
+		Message: message,
+		Fields:  logger.fields,
+	}
+
+	if len(fields) > 0 {
+		entry.Fields = append(append(make([]Field, 0, len(logger.fields)+len(fields)), logger.fields...), fields...)
+	}
+
+	backend := active.Load()
+	encoded := backend.config.Encoder.Encode(entry)
+
+	backend.mutex.Lock()
+	backend.config.Output.Write(encoded)
+	backend.mutex.Unlock()
+
+	if level == LevelFatal {
+		os.Exit(1)
+	}
+}
+
+// Description:
+//
+//	Logs a formatted message at trace level.
+//
+// Parameters:
+//
+//	format 	The format string.
+//	args 	The format arguments.
+func (logger *Logger) Tracef(format string, args ...interface{}) {
+	logger.logf(LevelTrace, format, args)
+}
+
+// Description:
+//
+//	Logs a formatted message at debug level.
+//
+// Parameters:
+//
+//	format 	The format string.
+//	args 	The format arguments.
+func (logger *Logger) Debugf(format string, args ...interface{}) {
+	logger.logf(LevelDebug, format, args)
+}
+
+// Description:
+//
+//	Logs a formatted message at info level.
+//
+// Parameters:
+//
+//	format 	The format string.
+//	args 	The format arguments.
+func (logger *Logger) Infof(format string, args ...interface{}) {
+	logger.logf(LevelInfo, format, args)
+}
+
+// Description:
+//
+//	Logs a formatted message at warn level.
+//
+// Parameters:
+//
+//	format 	The format string.
+//	args 	The format arguments.
+func (logger *Logger) Warnf(format string, args ...interface{}) {
+	logger.logf(LevelWarn, format, args)
+}
+
+// Description:
+//
+//	Logs a formatted message at error level.
+//
+// Parameters:
+//
+//	format 	The format string.
+//	args 	The format arguments.
+func (logger *Logger) Errorf(format string, args ...interface{}) {
+	logger.logf(LevelError, format, args)
+}
+
+// Description:
+//
+//	Logs a formatted message at fatal level and terminates the process.
+//
+// Parameters:
+//
+//	format 	The format string.
+//	args 	The format arguments.
+func (logger *Logger) Fatalf(format string, args ...interface{}) {
+	logger.logf(LevelFatal, format, args)
+}
+
+// Description:
+//
+//	Formats and logs a message, if the level is enabled.
+//
+// Parameters:
+//
+//	level 	The level of the entry.
+//	format 	The format string.
+//	args 	The format arguments.
+func (logger *Logger) logf(level Level, format string, args []interface{}) {
+	if !logger.Enabled(level) {
+		return
+	}
+
+	logger.Log(level, fmt.Sprintf(format, args...))
+}
+
+// Description:
+//
+//	Resolves nil loggers to the root logger.
+//
+// Returns:
+//
+//	The logger, or the root logger if the logger is nil.
+func (logger *Logger) orRoot() *Logger {
+	if logger == nil {
+		return root
+	}
+
+	return logger
+}
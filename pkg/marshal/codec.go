@@ -0,0 +1,283 @@
+package marshal
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Description:
+//
+//	An encoding of request and response bodies.
+type Codec interface {
+
+	// Description:
+	//
+	//	Gets the media types handled by this codec.
+	//	The first media type is used as content type of encoded bodies.
+	//
+	// Returns:
+	//
+	//	The media types, e.g. application/json.
+	MediaTypes() []string
+
+	// Description:
+	//
+	//	Encodes an object.
+	//
+	// Parameters:
+	//
+	//	object The object to encode.
+	//
+	// Returns:
+	//
+	//	The encoded bytes, or an error if encoding fails.
+	Marshal(object interface{}) ([]byte, error)
+
+	// Description:
+	//
+	//	Decodes bytes into the given target.
+	//
+	// Parameters:
+	//
+	//	data 	The bytes to decode.
+	//	target 	A pointer to the target object.
+	//
+	// Returns:
+	//
+	//	An error if decoding fails.
+	Unmarshal(data []byte, target interface{}) error
+}
+
+// Description:
+//
+//	An ordered set of codecs, used for content negotiation.
+//	The first codec is the default, used if the client does not express a preference.
+type Codecs struct {
+
+	// The codecs, in order of preference.
+	codecs []Codec
+}
+
+// Description:
+//
+//	A media range of an Accept header, e.g. application/* with a quality.
+type mediaRange struct {
+
+	// The media type, possibly with wildcards.
+	mediaType string
+
+	// The quality, between 0 and 1.
+	quality float64
+}
+
+// Description:
+//
+//	Creates a set of codecs.
+//
+// Parameters:
+//
+//	codecs The codecs, in order of preference.
+//
+// Returns:
+//
+//	The created set of codecs.
+func NewCodecs(codecs ...Codec) *Codecs {
+	return &Codecs{codecs: codecs}
+}
+
+// Description:
+//
+//	Creates the default set of codecs: JSON, XML, MessagePack, CBOR and YAML.
+//
+// Returns:
+//
+//	The default set of codecs.
+func DefaultCodecs() *Codecs {
+	return NewCodecs(JSONCodec{}, XMLCodec{}, MsgPackCodec(), CBORCodec(), YAMLCodec{})
+}
+
+// Description:
+//
+//	Gets the default codec.
+//
+// Returns:
+//
+//	The first codec, or nil if the set is empty.
+func (codecs *Codecs) Default() Codec {
+	if len(codecs.codecs) == 0 {
+		return nil
+	}
+
+	return codecs.codecs[0]
+}
+
+// Description:
+//
+//	Gets all codecs.
+//
+// Returns:
+//
+//	The codecs, in order of preference.
+func (codecs *Codecs) All() []Codec {
+	return append([]Codec{}, codecs.codecs...)
+}
+
+// Description:
+//
+//	Gets the codec for the given Content-Type header.
+//	An empty content type selects the default codec.
+//
+// Parameters:
+//
+//	contentType The Content-Type header, e.g. application/json; charset=utf-8.
+//
+// Returns:
+//
+//	The codec, and whether a codec handles the content type.
+func (codecs *Codecs) ForContentType(contentType string) (Codec, bool) {
+	if strings.TrimSpace(contentType) == "" {
+		return codecs.Default(), codecs.Default() != nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+
+	for _, codec := range codecs.codecs {
+		for _, supported := range codec.MediaTypes() {
+			if supported == mediaType {
+				return codec, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// Description:
+//
+//	Selects the codec for the given Accept header.
+//	Media ranges are ranked by quality, more specific ranges win on equal quality.
+//	An empty Accept header selects the default codec.
+//
+// Parameters:
+//
+//	accept The Accept header, e.g. application/msgpack, application/json;q=0.5.
+//
+// Returns:
+//
+//	The codec, and whether any codec is acceptable.
+func (codecs *Codecs) Negotiate(accept string) (Codec, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return codecs.Default(), codecs.Default() != nil
+	}
+
+	ranges := parseAccept(accept)
+
+	for _, accepted := range ranges {
+		if accepted.quality <= 0 {
+			continue
+		}
+
+		for _, codec := range codecs.codecs {
+			for _, supported := range codec.MediaTypes() {
+				if matchesMediaRange(accepted.mediaType, supported) && !excluded(ranges, supported) {
+					return codec, true
+				}
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// Description:
+//
+//	Parses an Accept header, sorted by descending quality and specificity.
+//
+// Parameters:
+//
+//	accept The Accept header.
+//
+// Returns:
+//
+//	The media ranges.
+func parseAccept(accept string) []mediaRange {
+	ranges := make([]mediaRange, 0)
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, parameters, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+
+		if value, ok := parameters["q"]; ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err == nil {
+				quality = parsed
+			}
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].quality != ranges[j].quality {
+			return ranges[i].quality > ranges[j].quality
+		}
+
+		return strings.Count(ranges[i].mediaType, "*") < strings.Count(ranges[j].mediaType, "*")
+	})
+
+	return ranges
+}
+
+// Description:
+//
+//	Checks whether a media type is explicitly excluded via a quality of zero.
+//
+// Parameters:
+//
+//	ranges 		The media ranges of the Accept header.
+//	mediaType 	The media type.
+//
+// Returns:
+//
+//	Whether the media type is excluded.
+func excluded(ranges []mediaRange, mediaType string) bool {
+	for _, accepted := range ranges {
+		if accepted.quality <= 0 && accepted.mediaType == mediaType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Description:
+//
+//	Checks whether a media type matches a media range, e.g. application/json matches application/*.
+//
+// Parameters:
+//
+//	mediaRange 	The media range, possibly with wildcards.
+//	mediaType 	The media type.
+//
+// Returns:
+//
+//	Whether the media type matches.
+func matchesMediaRange(mediaRange string, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+
+	rangeType, rangeSubtype, _ := strings.Cut(mediaRange, "/")
+	typeName, _, _ := strings.Cut(mediaType, "/")
+
+	return rangeSubtype == "*" && rangeType == typeName
+}
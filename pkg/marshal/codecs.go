@@ -0,0 +1,314 @@
+package marshal
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+
+	"github.com/ugorji/go/codec"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+
+	// The root element of encoded XML lists.
+	xmlListElement = "items"
+)
+
+// Description:
+//
+//	The JSON codec.
+type JSONCodec struct{}
+
+// Description:
+//
+//	The XML codec.
+//	Lists are wrapped in an items element, as XML documents require a single root element.
+type XMLCodec struct{}
+
+// Description:
+//
+//	The YAML codec.
+//	Field names follow the JSON representation, i.e. the json struct tags.
+type YAMLCodec struct{}
+
+// Description:
+//
+//	A codec backed by a ugorji handle, used for MessagePack and CBOR.
+//	Field names follow the json struct tags.
+type binaryCodec struct {
+
+	// The media types of the codec.
+	mediaTypes []string
+
+	// The ugorji handle.
+	handle codec.Handle
+}
+
+// Description:
+//
+//	Creates the MessagePack codec.
+//
+// Returns:
+//
+//	The MessagePack codec.
+func MsgPackCodec() Codec {
+	handle := &codec.MsgpackHandle{}
+	handle.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	handle.RawToString = true
+	handle.WriteExt = true
+
+	return binaryCodec{
+		mediaTypes: []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"},
+		handle:     handle,
+	}
+}
+
+// Description:
+//
+//	Creates the CBOR codec.
+//
+// Returns:
+//
+//	The CBOR codec.
+func CBORCodec() Codec {
+	handle := &codec.CborHandle{}
+	handle.MapType = reflect.TypeOf(map[string]interface{}(nil))
+
+	return binaryCodec{
+		mediaTypes: []string{"application/cbor"},
+		handle:     handle,
+	}
+}
+
+// Description:
+//
+//	Gets the media types handled by this codec.
+//
+// Returns:
+//
+//	The media types.
+func (JSONCodec) MediaTypes() []string {
+	return []string{"application/json"}
+}
+
+// Description:
+//
+//	Encodes an object as JSON.
+//
+// Parameters:
+//
+//	object The object to encode.
+//
+// Returns:
+//
+//	The encoded bytes, or an error if encoding fails.
+func (JSONCodec) Marshal(object interface{}) ([]byte, error) {
+	return json.Marshal(object)
+}
+
+// Description:
+//
+//	Decodes JSON into the given target.
+//
+// Parameters:
+//
+//	data 	The bytes to decode.
+//	target 	A pointer to the target object.
+//
+// Returns:
+//
+//	An error if decoding fails.
+func (JSONCodec) Unmarshal(data []byte, target interface{}) error {
+	return json.Unmarshal(data, target)
+}
+
+// Description:
+//
+//	Gets the media types handled by this codec.
+//
+// Returns:
+//
+//	The media types.
+func (XMLCodec) MediaTypes() []string {
+	return []string{"application/xml", "text/xml"}
+}
+
+// Description:
+//
+//	Encodes an object as XML.
+//
+// Parameters:
+//
+//	object The object to encode.
+//
+// Returns:
+//
+//	The encoded bytes, or an error if encoding fails.
+func (XMLCodec) Marshal(object interface{}) ([]byte, error) {
+	value := reflect.Indirect(reflect.ValueOf(object))
+
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return xml.Marshal(object)
+	}
+
+	buffer := &bytes.Buffer{}
+	encoder := xml.NewEncoder(buffer)
+	root := xml.StartElement{Name: xml.Name{Local: xmlListElement}}
+
+	err := encoder.EncodeToken(root)
+	if err != nil {
+		return nil, err
+	}
+
+	for index := 0; index < value.Len(); index++ {
+		err = encoder.Encode(value.Index(index).Interface())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = encoder.EncodeToken(root.End())
+	if err != nil {
+		return nil, err
+	}
+
+	err = encoder.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Description:
+//
+//	Decodes XML into the given target.
+//
+// Parameters:
+//
+//	data 	The bytes to decode.
+//	target 	A pointer to the target object.
+//
+// Returns:
+//
+//	An error if decoding fails.
+func (XMLCodec) Unmarshal(data []byte, target interface{}) error {
+	return xml.Unmarshal(data, target)
+}
+
+// Description:
+//
+//	Gets the media types handled by this codec.
+//
+// Returns:
+//
+//	The media types.
+func (YAMLCodec) MediaTypes() []string {
+	return []string{"application/yaml", "application/x-yaml", "text/yaml"}
+}
+
+// Description:
+//
+//	Encodes an object as YAML, using the field names of its JSON representation.
+//
+// Parameters:
+//
+//	object The object to encode.
+//
+// Returns:
+//
+//	The encoded bytes, or an error if encoding fails.
+func (YAMLCodec) Marshal(object interface{}) ([]byte, error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+
+	err = json.Unmarshal(data, &generic)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(generic)
+}
+
+// Description:
+//
+//	Decodes YAML into the given target, using the field names of its JSON representation.
+//
+// Parameters:
+//
+//	data 	The bytes to decode.
+//	target 	A pointer to the target object.
+//
+// Returns:
+//
+//	An error if decoding fails.
+func (YAMLCodec) Unmarshal(data []byte, target interface{}) error {
+	var generic interface{}
+
+	err := yaml.Unmarshal(data, &generic)
+	if err != nil {
+		return err
+	}
+
+	converted, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(converted, target)
+}
+
+// Description:
+//
+//	Gets the media types handled by this codec.
+//
+// Returns:
+//
+//	The media types.
+func (binary binaryCodec) MediaTypes() []string {
+	return binary.mediaTypes
+}
+
+// Description:
+//
+//	Encodes an object.
+//
+// Parameters:
+//
+//	object The object to encode.
+//
+// Returns:
+//
+//	The encoded bytes, or an error if encoding fails.
+func (binary binaryCodec) Marshal(object interface{}) ([]byte, error) {
+	var data []byte
+
+	err := codec.NewEncoderBytes(&data, binary.handle).Encode(object)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Description:
+//
+//	Decodes bytes into the given target.
+//
+// Parameters:
+//
+//	data 	The bytes to decode.
+//	target 	A pointer to the target object.
+//
+// Returns:
+//
+//	An error if decoding fails.
+func (binary binaryCodec) Unmarshal(data []byte, target interface{}) error {
+	return codec.NewDecoderBytes(data, binary.handle).Decode(target)
+}
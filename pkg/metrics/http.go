@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/router"
+)
+
+const (
+
+	// The route label of requests which did not match any route.
+	// Prevents unbounded label cardinality caused by arbitrary request paths.
+	UnmatchedRoute = "unmatched"
+
+	// The method label of requests with a non-standard method.
+	// Prevents unbounded label cardinality caused by arbitrary request methods.
+	OtherMethod = "other"
+)
+
+// Description:
+//
+//	Creates an observer which records per-route request counts and latencies.
+//	Requests are labelled with the method, the route template and the status code.
+//	Unmatched routes and non-standard methods are labelled with fixed values.
+//
+//	Registered metrics:
+//	  - http_requests_total
+//	  - http_request_duration_seconds
+//
+// Parameters:
+//
+//	registry The registry to register the metrics with.
+//
+// Returns:
+//
+//	The observer, to be registered with a router.
+func HTTPObserver(registry *Registry) router.Observer {
+	requests := registry.NewCounterVec(
+		"http_requests_total",
+		"Number of handled HTTP requests.",
+		"method", "route", "status",
+	)
+
+	durations := registry.NewHistogramVec(
+		"http_request_duration_seconds",
+		"Duration of handled HTTP requests in seconds.",
+		DefaultDurationBuckets,
+		"method", "route", "status",
+	)
+
+	return func(exchange *router.Exchange) {
+		route := exchange.Route
+		if route == "" {
+			route = UnmatchedRoute
+		}
+
+		method := methodLabel(exchange.Method)
+		status := strconv.Itoa(exchange.Status)
+
+		requests.Inc(method, route, status)
+		durations.Observe(exchange.Latency.Seconds(), method, route, status)
+	}
+}
+
+// Description:
+//
+//	Gets the method label of a request.
+//
+// Parameters:
+//
+//	method The request method.
+//
+// Returns:
+//
+//	The method, or OtherMethod if it is not a standard HTTP method.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace:
+		return method
+	}
+
+	return OtherMethod
+}
+
+// Description:
+//
+//	Creates a handler exposing all metrics of the given registry in the Prometheus text format.
+//
+// Parameters:
+//
+//	registry The registry to expose.
+//
+// Returns:
+//
+//	The router handler, e.g. to be registered for GET /metrics.
+func Handler(registry *Registry) router.RouterHandlerFunc {
+	return func(request *api.APIRequest) *api.APIResponse {
+		buffer := &bytes.Buffer{}
+
+		err := registry.WriteText(buffer)
+		if err != nil {
+			request.Logger.Errorf("failed to write metrics: %s", err)
+
+			return &api.APIResponse{
+				StatusCode: http.StatusInternalServerError,
+				Body: api.ErrorResponseBody{
+					Message:   "failed to write metrics",
+					RequestID: request.RequestID,
+				},
+			}
+		}
+
+		return api.Raw(http.StatusOK, ContentType, buffer.Bytes())
+	}
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/router"
+)
+
+func TestHTTPObserver(t *testing.T) {
+	registry := NewRegistry()
+
+	engine := router.New(router.DefaultConfig())
+	engine.Observe(HTTPObserver(registry))
+
+	engine.Handle(http.MethodGet, "/albums/:id", func(request *api.APIRequest) *api.APIResponse {
+		return &api.APIResponse{StatusCode: http.StatusOK, Body: []string{}}
+	})
+
+	engine.Handle(http.MethodGet, "/metrics", Handler(registry))
+
+	for _, request := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/albums/1"},
+		{http.MethodGet, "/albums/2"},
+		{http.MethodGet, "/unknown/1"},
+		{http.MethodGet, "/unknown/2"},
+		{"BREW", "/pot-1"},
+		{"BREW", "/pot-2"},
+	} {
+		engine.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(request.method, request.path, nil))
+	}
+
+	recorder := httptest.NewRecorder()
+	engine.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != ContentType {
+		t.Fatalf("expected metrics, got %d with %s", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	exposition := recorder.Body.String()
+
+	for _, line := range []string{
+		`http_requests_total{method="GET",route="/albums/:id",status="200"} 2`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 2`,
+		`http_requests_total{method="other",route="unmatched",status="404"} 2`,
+		`http_request_duration_seconds_count{method="GET",route="/albums/:id",status="200"} 2`,
+	} {
+		if !strings.Contains(exposition, line+"\n") {
+			t.Fatalf("expected %q in:\n%s", line, exposition)
+		}
+	}
+
+	for _, raw := range []string{"/albums/1", "/unknown", "BREW"} {
+		if strings.Contains(exposition, raw) {
+			t.Fatalf("expected no raw paths or methods as labels, found %q in:\n%s", raw, exposition)
+		}
+	}
+}
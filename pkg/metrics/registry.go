@@ -0,0 +1,647 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+
+	// The content type of the Prometheus text exposition format.
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// Description:
+//
+//	The default histogram buckets for durations in seconds.
+var DefaultDurationBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Description:
+//
+//	A metric family which can be written in the Prometheus text exposition format.
+type family interface {
+
+	// Description:
+	//
+	//	Gets the name of the metric family.
+	//
+	// Returns:
+	//
+	//	The name of the metric family.
+	familyName() string
+
+	// Description:
+	//
+	//	Writes all samples of the metric family, including the HELP and TYPE lines.
+	//
+	// Parameters:
+	//
+	//	writer The output writer.
+	write(writer *bufio.Writer)
+}
+
+// Description:
+//
+//	A registry of metric families.
+//	Safe for concurrent use.
+type Registry struct {
+
+	// Guards the families.
+	mutex sync.RWMutex
+
+	// The registered metric families, keyed by name.
+	families map[string]family
+}
+
+// Description:
+//
+//	The common description of a metric family.
+type descriptor struct {
+
+	// The name of the metric family.
+	name string
+
+	// The help text of the metric family.
+	help string
+
+	// The label names.
+	labels []string
+}
+
+// Description:
+//
+//	A set of samples of a metric family, keyed by their label values.
+type series[T any] struct {
+
+	// Guards the samples.
+	mutex sync.Mutex
+
+	// The label values of the samples, keyed by the joined label values.
+	labelValues map[string][]string
+
+	// The samples, keyed by the joined label values.
+	samples map[string]*T
+}
+
+// Description:
+//
+//	A counter metric family with labels.
+//	Counters only increase.
+type CounterVec struct {
+	descriptor
+	series[float64]
+}
+
+// Description:
+//
+//	A gauge metric family with labels.
+//	Gauges can increase and decrease.
+type GaugeVec struct {
+	descriptor
+	series[float64]
+}
+
+// Description:
+//
+//	A single histogram sample.
+type histogramSample struct {
+
+	// The cumulative counts per bucket.
+	counts []uint64
+
+	// The sum of all observed values.
+	sum float64
+
+	// The number of observed values.
+	count uint64
+}
+
+// Description:
+//
+//	A histogram metric family with labels.
+type HistogramVec struct {
+	descriptor
+	series[histogramSample]
+
+	// The upper bounds of the buckets, in increasing order.
+	buckets []float64
+}
+
+// Description:
+//
+//	A metric family whose single value is computed on every scrape.
+type FuncMetric struct {
+	descriptor
+
+	// The metric type, counter or gauge.
+	metricType string
+
+	// Computes the current value.
+	value func() float64
+}
+
+// Description:
+//
+//	The default registry.
+var Default = NewRegistry()
+
+// Description:
+//
+//	Creates a new, empty registry.
+//
+// Returns:
+//
+//	The created registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		families: make(map[string]family),
+	}
+}
+
+// Description:
+//
+//	Registers a new counter metric family.
+//	Panics if a family with the same name is already registered.
+//
+// Parameters:
+//
+//	name 	The name of the metric family.
+//	help 	The help text.
+//	labels 	The label names.
+//
+// Returns:
+//
+//	The created counter.
+func (registry *Registry) NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	counter := &CounterVec{
+		descriptor: descriptor{name: name, help: help, labels: labels},
+		series:     newSeries[float64](),
+	}
+
+	registry.register(counter)
+	return counter
+}
+
+// Description:
+//
+//	Registers a new gauge metric family.
+//	Panics if a family with the same name is already registered.
+//
+// Parameters:
+//
+//	name 	The name of the metric family.
+//	help 	The help text.
+//	labels 	The label names.
+//
+// Returns:
+//
+//	The created gauge.
+func (registry *Registry) NewGaugeVec(name string, help string, labels ...string) *GaugeVec {
+	gauge := &GaugeVec{
+		descriptor: descriptor{name: name, help: help, labels: labels},
+		series:     newSeries[float64](),
+	}
+
+	registry.register(gauge)
+	return gauge
+}
+
+// Description:
+//
+//	Registers a new histogram metric family.
+//	Panics if a family with the same name is already registered.
+//
+// Parameters:
+//
+//	name 	The name of the metric family.
+//	help 	The help text.
+//	buckets The upper bounds of the buckets. Sorted on registration.
+//	labels 	The label names.
+//
+// Returns:
+//
+//	The created histogram.
+func (registry *Registry) NewHistogramVec(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+
+	histogram := &HistogramVec{
+		descriptor: descriptor{name: name, help: help, labels: labels},
+		series:     newSeries[histogramSample](),
+		buckets:    sorted,
+	}
+
+	registry.register(histogram)
+	return histogram
+}
+
+// Description:
+//
+//	Registers a new gauge whose value is computed on every scrape.
+//	Panics if a family with the same name is already registered.
+//
+// Parameters:
+//
+//	name 	The name of the metric family.
+//	help 	The help text.
+//	value 	Computes the current value.
+func (registry *Registry) NewGaugeFunc(name string, help string, value func() float64) {
+	registry.register(&FuncMetric{
+		descriptor: descriptor{name: name, help: help},
+		metricType: "gauge",
+		value:      value,
+	})
+}
+
+// Description:
+//
+//	Registers a new counter whose value is computed on every scrape.
+//	Panics if a family with the same name is already registered.
+//
+// Parameters:
+//
+//	name 	The name of the metric family.
+//	help 	The help text.
+//	value 	Computes the current value.
+func (registry *Registry) NewCounterFunc(name string, help string, value func() float64) {
+	registry.register(&FuncMetric{
+		descriptor: descriptor{name: name, help: help},
+		metricType: "counter",
+		value:      value,
+	})
+}
+
+// Description:
+//
+//	Writes all metric families in the Prometheus text exposition format, sorted by name.
+//
+// Parameters:
+//
+//	output The output writer.
+//
+// Returns:
+//
+//	An error if writing fails.
+func (registry *Registry) WriteText(output io.Writer) error {
+	registry.mutex.RLock()
+
+	names := make([]string, 0, len(registry.families))
+	for name := range registry.families {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	families := make([]family, 0, len(names))
+	for _, name := range names {
+		families = append(families, registry.families[name])
+	}
+
+	registry.mutex.RUnlock()
+
+	writer := bufio.NewWriter(output)
+
+	for _, family := range families {
+		family.write(writer)
+	}
+
+	return writer.Flush()
+}
+
+// Description:
+//
+//	Registers a metric family.
+//
+// Parameters:
+//
+//	family The metric family to register.
+func (registry *Registry) register(family family) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if _, exists := registry.families[family.familyName()]; exists {
+		panic(fmt.Sprintf("metrics: duplicate metric family: %s", family.familyName()))
+	}
+
+	registry.families[family.familyName()] = family
+}
+
+// Description:
+//
+//	Increments the counter by one.
+//
+// Parameters:
+//
+//	labelValues The label values, in the order of the label names.
+func (counter *CounterVec) Inc(labelValues ...string) {
+	counter.Add(1, labelValues...)
+}
+
+// Description:
+//
+//	Adds the given value to the counter.
+//	Negative values are ignored, as counters must not decrease.
+//
+// Parameters:
+//
+//	value 		The value to add.
+//	labelValues The label values, in the order of the label names.
+func (counter *CounterVec) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		return
+	}
+
+	counter.update(labelValues, func(sample *float64) {
+		*sample += value
+	})
+}
+
+// Description:
+//
+//	Sets the gauge to the given value.
+//
+// Parameters:
+//
+//	value 		The value to set.
+//	labelValues The label values, in the order of the label names.
+func (gauge *GaugeVec) Set(value float64, labelValues ...string) {
+	gauge.update(labelValues, func(sample *float64) {
+		*sample = value
+	})
+}
+
+// Description:
+//
+//	Adds the given value to the gauge. The value may be negative.
+//
+// Parameters:
+//
+//	value 		The value to add.
+//	labelValues The label values, in the order of the label names.
+func (gauge *GaugeVec) Add(value float64, labelValues ...string) {
+	gauge.update(labelValues, func(sample *float64) {
+		*sample += value
+	})
+}
+
+// Description:
+//
+//	Observes a value, e.g. a duration in seconds.
+//
+// Parameters:
+//
+//	value 		The observed value.
+//	labelValues The label values, in the order of the label names.
+func (histogram *HistogramVec) Observe(value float64, labelValues ...string) {
+	histogram.update(labelValues, func(sample *histogramSample) {
+		if sample.counts == nil {
+			sample.counts = make([]uint64, len(histogram.buckets))
+		}
+
+		for index, bound := range histogram.buckets {
+			if value <= bound {
+				sample.counts[index]++
+			}
+		}
+
+		sample.sum += value
+		sample.count++
+	})
+}
+
+// Description:
+//
+//	Gets the name of the metric family.
+//
+// Returns:
+//
+//	The name of the metric family.
+func (descriptor *descriptor) familyName() string {
+	return descriptor.name
+}
+
+// Description:
+//
+//	Writes the HELP and TYPE lines of the metric family.
+//
+// Parameters:
+//
+//	writer 		The output writer.
+//	metricType 	The metric type.
+func (descriptor *descriptor) writeHeader(writer *bufio.Writer, metricType string) {
+	help := strings.NewReplacer("\\", `\\`, "\n", `\n`).Replace(descriptor.help)
+
+	fmt.Fprintf(writer, "# HELP %s %s\n", descriptor.name, help)
+	fmt.Fprintf(writer, "# TYPE %s %s\n", descriptor.name, metricType)
+}
+
+// Description:
+//
+//	Writes all samples of the counter.
+//
+// Parameters:
+//
+//	writer The output writer.
+func (counter *CounterVec) write(writer *bufio.Writer) {
+	counter.writeHeader(writer, "counter")
+	writeFloatSeries(writer, &counter.descriptor, &counter.series)
+}
+
+// Description:
+//
+//	Writes all samples of the gauge.
+//
+// Parameters:
+//
+//	writer The output writer.
+func (gauge *GaugeVec) write(writer *bufio.Writer) {
+	gauge.writeHeader(writer, "gauge")
+	writeFloatSeries(writer, &gauge.descriptor, &gauge.series)
+}
+
+// Description:
+//
+//	Writes all samples of the histogram.
+//
+// Parameters:
+//
+//	writer The output writer.
+func (histogram *HistogramVec) write(writer *bufio.Writer) {
+	histogram.writeHeader(writer, "histogram")
+
+	histogram.mutex.Lock()
+	defer histogram.mutex.Unlock()
+
+	for _, key := range sortedKeys(histogram.samples) {
+		sample := histogram.samples[key]
+		labelValues := histogram.labelValues[key]
+
+		// Copied, so that appending the le label never writes to the backing array of the label names.
+		bucketLabels := append(append([]string{}, histogram.labels...), "le")
+
+		for index, bound := range histogram.buckets {
+			labels := formatLabels(bucketLabels, append(append([]string{}, labelValues...), formatValue(bound)))
+			fmt.Fprintf(writer, "%s_bucket%s %d\n", histogram.name, labels, sample.counts[index])
+		}
+
+		labels := formatLabels(bucketLabels, append(append([]string{}, labelValues...), "+Inf"))
+		fmt.Fprintf(writer, "%s_bucket%s %d\n", histogram.name, labels, sample.count)
+
+		labels = formatLabels(histogram.labels, labelValues)
+		fmt.Fprintf(writer, "%s_sum%s %s\n", histogram.name, labels, formatValue(sample.sum))
+		fmt.Fprintf(writer, "%s_count%s %d\n", histogram.name, labels, sample.count)
+	}
+}
+
+// Description:
+//
+//	Writes the current value of the metric.
+//
+// Parameters:
+//
+//	writer The output writer.
+func (metric *FuncMetric) write(writer *bufio.Writer) {
+	metric.writeHeader(writer, metric.metricType)
+	fmt.Fprintf(writer, "%s %s\n", metric.name, formatValue(metric.value()))
+}
+
+// Description:
+//
+//	Creates an empty set of samples.
+//
+// Returns:
+//
+//	The created set of samples.
+func newSeries[T any]() series[T] {
+	return series[T]{
+		labelValues: make(map[string][]string),
+		samples:     make(map[string]*T),
+	}
+}
+
+// Description:
+//
+//	Updates the sample with the given label values, creating it if needed.
+//
+// Parameters:
+//
+//	labelValues The label values.
+//	update 		The update applied to the sample.
+func (series *series[T]) update(labelValues []string, update func(sample *T)) {
+	key := strings.Join(labelValues, "\xff")
+
+	series.mutex.Lock()
+	defer series.mutex.Unlock()
+
+	sample, exists := series.samples[key]
+	if !exists {
+		sample = new(T)
+		series.samples[key] = sample
+		series.labelValues[key] = append([]string{}, labelValues...)
+	}
+
+	update(sample)
+}
+
+// Description:
+//
+//	Writes all samples of a counter or gauge.
+//
+// Parameters:
+//
+//	writer 		The output writer.
+//	descriptor 	The descriptor of the metric family.
+//	series 		The samples.
+func writeFloatSeries(writer *bufio.Writer, descriptor *descriptor, series *series[float64]) {
+	series.mutex.Lock()
+	defer series.mutex.Unlock()
+
+	for _, key := range sortedKeys(series.samples) {
+		labels := formatLabels(descriptor.labels, series.labelValues[key])
+		fmt.Fprintf(writer, "%s%s %s\n", descriptor.name, labels, formatValue(*series.samples[key]))
+	}
+}
+
+// Description:
+//
+//	Gets the keys of a map in sorted order.
+//
+// Parameters:
+//
+//	values The map.
+//
+// Returns:
+//
+//	The sorted keys.
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Description:
+//
+//	Formats a label set, e.g. {method="GET",status="200"}.
+//
+// Parameters:
+//
+//	names 	The label names.
+//	values 	The label values.
+//
+// Returns:
+//
+//	The formatted label set, or an empty string if there are no labels.
+func formatLabels(names []string, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	escaper := strings.NewReplacer("\\", `\\`, "\"", `\"`, "\n", `\n`)
+	pairs := make([]string, 0, len(names))
+
+	for index, name := range names {
+		value := ""
+		if index < len(values) {
+			value = values[index]
+		}
+
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, escaper.Replace(value)))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Description:
+//
+//	Formats a sample value.
+//
+// Parameters:
+//
+//	value The value.
+//
+// Returns:
+//
+//	The formatted value.
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Description:
+//
+//	Writes the text exposition of a registry.
+//
+// Parameters:
+//
+//	t 			The test.
+//	registry 	The registry.
+//
+// Returns:
+//
+//	The text exposition.
+func expose(t *testing.T, registry *Registry) string {
+	buffer := &bytes.Buffer{}
+
+	err := registry.WriteText(buffer)
+	if err != nil {
+		t.Fatalf("failed to write metrics: %s", err)
+	}
+
+	return buffer.String()
+}
+
+func TestWriteText(t *testing.T) {
+	registry := NewRegistry()
+
+	requests := registry.NewCounterVec("requests_total", "Number of requests.\nSplit by \\ path.", "path", "code")
+	requests.Inc("/a", "200")
+	requests.Add(2.5, "/a", "200")
+	requests.Inc(`quote " backslash \ newline`+"\n", "500")
+
+	connections := registry.NewGaugeVec("connections", "Open connections.")
+	connections.Set(3)
+	connections.Add(-1)
+
+	durations := registry.NewHistogramVec("duration_seconds", "Durations.", []float64{0.1, 1}, "route")
+	durations.Observe(0.05, "/a")
+	durations.Observe(0.5, "/a")
+	durations.Observe(5, "/a")
+
+	registry.NewGaugeFunc("temperature", "Temperature.", func() float64 { return math.Inf(1) })
+	registry.NewCounterFunc("starts_total", "Starts.", func() float64 { return 1e21 })
+
+	expected := `# HELP connections Open connections.
+# TYPE connections gauge
+connections 2
+# HELP duration_seconds Durations.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{route="/a",le="0.1"} 1
+duration_seconds_bucket{route="/a",le="1"} 2
+duration_seconds_bucket{route="/a",le="+Inf"} 3
+duration_seconds_sum{route="/a"} 5.55
+duration_seconds_count{route="/a"} 3
+# HELP requests_total Number of requests.\nSplit by \\ path.
+# TYPE requests_total counter
+requests_total{path="/a",code="200"} 3.5
+requests_total{path="quote \" backslash \\ newline\n",code="500"} 1
+# HELP starts_total Starts.
+# TYPE starts_total counter
+starts_total 1e+21
+# HELP temperature Temperature.
+# TYPE temperature gauge
+temperature +Inf
+`
+
+	if actual := expose(t, registry); actual != expected {
+		t.Fatalf("unexpected exposition:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
+
+func TestHistogramWithoutObservations(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewHistogramVec("empty_seconds", "Empty.", DefaultDurationBuckets)
+
+	expected := "# HELP empty_seconds Empty.\n# TYPE empty_seconds histogram\n"
+	if actual := expose(t, registry); actual != expected {
+		t.Fatalf("expected only the header, got:\n%s", actual)
+	}
+}
+
+func TestDuplicateFamiliesPanic(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("requests_total", "Requests.")
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected registering a duplicate family to panic")
+		}
+	}()
+
+	registry.NewGaugeVec("requests_total", "Requests.")
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	registry := NewRegistry()
+
+	counter := registry.NewCounterVec("requests_total", "Requests.", "worker")
+	gauge := registry.NewGaugeVec("inflight", "Inflight.")
+	histogram := registry.NewHistogramVec("duration_seconds", "Durations.", []float64{1}, "worker")
+
+	const workers = 8
+	const updates = 1000
+
+	group := sync.WaitGroup{}
+
+	for worker := 0; worker < workers; worker++ {
+		group.Add(1)
+
+		go func(worker int) {
+			defer group.Done()
+
+			label := fmt.Sprintf("%d", worker%2)
+
+			for index := 0; index < updates; index++ {
+				counter.Inc(label)
+				gauge.Add(1)
+				histogram.Observe(0.5, label)
+
+				// Exposition runs concurrently with updates, like scrapes do.
+				if index%100 == 0 {
+					expose(t, registry)
+				}
+			}
+		}(worker)
+	}
+
+	group.Wait()
+	exposition := expose(t, registry)
+
+	for _, line := range []string{
+		fmt.Sprintf(`requests_total{worker="0"} %d`, workers/2*updates),
+		fmt.Sprintf(`requests_total{worker="1"} %d`, workers/2*updates),
+		fmt.Sprintf(`inflight %d`, workers*updates),
+		fmt.Sprintf(`duration_seconds_bucket{worker="1",le="+Inf"} %d`, workers/2*updates),
+		fmt.Sprintf(`duration_seconds_count{worker="0"} %d`, workers/2*updates),
+	} {
+		if !strings.Contains(exposition, line+"\n") {
+			t.Fatalf("expected %q in:\n%s", line, exposition)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+
+	// The maximum age of cached memory statistics.
+	// Reading memory statistics stops the world, so they are shared between the runtime metrics of a scrape.
+	memStatsMaxAge = time.Second
+)
+
+// Description:
+//
+//	Registers Go runtime and process metrics with the given registry:
+//	goroutines, heap and memory usage, garbage collections and the process start time.
+//
+// Parameters:
+//
+//	registry The registry to register the metrics with.
+func RegisterRuntimeMetrics(registry *Registry) {
+	var mutex sync.Mutex
+	var stats runtime.MemStats
+	var readAt time.Time
+
+	memStats := func() runtime.MemStats {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if time.Since(readAt) > memStatsMaxAge {
+			runtime.ReadMemStats(&stats)
+			readAt = time.Now()
+		}
+
+		return stats
+	}
+
+	startTime := float64(time.Now().UnixNano()) / 1e9
+
+	registry.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+
+	registry.NewGaugeFunc("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", func() float64 {
+		return float64(memStats().Alloc)
+	})
+
+	registry.NewGaugeFunc("go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", func() float64 {
+		return float64(memStats().HeapInuse)
+	})
+
+	registry.NewGaugeFunc("go_memstats_heap_objects", "Number of allocated objects.", func() float64 {
+		return float64(memStats().HeapObjects)
+	})
+
+	registry.NewGaugeFunc("go_memstats_sys_bytes", "Number of bytes obtained from the system.", func() float64 {
+		return float64(memStats().Sys)
+	})
+
+	registry.NewCounterFunc("go_gc_cycles_total", "Number of completed garbage collection cycles.", func() float64 {
+		return float64(memStats().NumGC)
+	})
+
+	registry.NewCounterFunc("go_gc_pause_seconds_total", "Total duration of garbage collection pauses.", func() float64 {
+		return float64(memStats().PauseTotalNs) / 1e9
+	})
+
+	registry.NewGaugeFunc("go_gomaxprocs", "Number of operating system threads that can execute Go code simultaneously.", func() float64 {
+		return float64(runtime.GOMAXPROCS(0))
+	})
+
+	registry.NewGaugeFunc("process_start_time_seconds", "Start time of the process since the unix epoch in seconds.", func() float64 {
+		return startTime
+	})
+}
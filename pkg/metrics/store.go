@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"github.com/gostream-official/albums/pkg/store"
+)
+
+// Description:
+//
+//	Creates an observer which records the durations of store operations.
+//	Operations are labelled with the collection, the operation name and the outcome (success or error).
+//
+//	Registered metrics:
+//	  - mongo_operation_duration_seconds
+//
+// Parameters:
+//
+//	registry The registry to register the metrics with.
+//
+// Returns:
+//
+//	The observer, to be registered with a mongo instance.
+func StoreObserver(registry *Registry) store.OperationObserver {
+	durations := registry.NewHistogramVec(
+		"mongo_operation_duration_seconds",
+		"Duration of MongoDB store operations in seconds.",
+		DefaultDurationBuckets,
+		"collection", "operation", "outcome",
+	)
+
+	return func(operation *store.Operation) {
+		outcome := "success"
+		if operation.Err != nil {
+			outcome = "error"
+		}
+
+		durations.Observe(operation.Duration.Seconds(), operation.Collection, operation.Name, outcome)
+	}
+}
+
+// Description:
+//
+//	Registers gauges exposing the connection pool of the given mongo instance.
+//
+//	Registered metrics:
+//	  - mongo_pool_connections_open
+//	  - mongo_pool_connections_in_use
+//
+// Parameters:
+//
+//	registry 	The registry to register the metrics with.
+//	instance 	The mongo instance.
+func RegisterPoolMetrics(registry *Registry, instance *store.MongoInstance) {
+	registry.NewGaugeFunc("mongo_pool_connections_open", "Number of open MongoDB connections.", func() float64 {
+		return float64(instance.PoolStats().Open)
+	})
+
+	registry.NewGaugeFunc("mongo_pool_connections_in_use", "Number of MongoDB connections currently checked out.", func() float64 {
+		return float64(instance.PoolStats().InUse)
+	})
+}
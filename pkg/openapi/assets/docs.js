@@ -0,0 +1,235 @@
+// Renders the OpenAPI specification referenced by the data-spec-url attribute of #docs.
+// The page is self-contained and loads no third-party resources, so it works offline.
+(function () {
+  "use strict";
+
+  var root = document.getElementById("docs");
+  var methods = ["get", "put", "post", "delete", "options", "head", "patch", "trace"];
+
+  // Creates an element with the given class and text content.
+  function element(tag, className, text) {
+    var node = document.createElement(tag);
+
+    if (className) {
+      node.className = className;
+    }
+
+    if (text !== undefined && text !== null) {
+      node.textContent = String(text);
+    }
+
+    return node;
+  }
+
+  // Renders a description, keeping its paragraphs.
+  function description(parent, text) {
+    if (!text) {
+      return;
+    }
+
+    text.split(/\n\s*\n/).forEach(function (paragraph) {
+      parent.appendChild(element("p", "", paragraph));
+    });
+  }
+
+  // Resolves a local reference, e.g. #/components/schemas/AlbumInfo.
+  function resolve(spec, ref) {
+    return ref.replace(/^#\//, "").split("/").reduce(function (node, segment) {
+      return node ? node[segment] : undefined;
+    }, spec);
+  }
+
+  // Renders a schema as indented, TypeScript-like text. References are expanded once per path.
+  function schemaText(spec, schema, indent, seen) {
+    if (!schema) {
+      return "any";
+    }
+
+    if (schema.$ref) {
+      var name = schema.$ref.split("/").pop();
+
+      if (seen.indexOf(name) >= 0) {
+        return name;
+      }
+
+      return name + " " + schemaText(spec, resolve(spec, schema.$ref), indent, seen.concat([name]));
+    }
+
+    var text;
+
+    if (schema.type === "array") {
+      text = schemaText(spec, schema.items, indent, seen) + "[]";
+    } else if (schema.type === "object" || schema.properties) {
+      var properties = schema.properties || {};
+      var required = schema.required || [];
+      var names = Object.keys(properties).sort();
+
+      if (names.length === 0) {
+        text = schema.additionalProperties ? "{ [key: string]: " + schemaText(spec, schema.additionalProperties, indent, seen) + " }" : "object";
+      } else {
+        var lines = names.map(function (property) {
+          var optional = required.indexOf(property) >= 0 ? "" : "?";
+          return indent + "  " + property + optional + ": " + schemaText(spec, properties[property], indent + "  ", seen);
+        });
+
+        text = "{\n" + lines.join("\n") + "\n" + indent + "}";
+      }
+    } else {
+      text = schema.type || "any";
+
+      if (schema.format) {
+        text += " (" + schema.format + ")";
+      }
+
+      if (schema.enum) {
+        text = schema.enum.map(function (value) { return JSON.stringify(value); }).join(" | ");
+      }
+    }
+
+    if (schema.nullable) {
+      text += " | null";
+    }
+
+    return text;
+  }
+
+  // Gets the schema of the JSON content, or of the first content otherwise.
+  function contentSchema(content) {
+    if (!content) {
+      return null;
+    }
+
+    var mediaType = content["application/json"] || content[Object.keys(content)[0]];
+    return mediaType ? mediaType.schema : null;
+  }
+
+  // Renders the parameters of an operation.
+  function parameters(spec, parent, list) {
+    if (!list || list.length === 0) {
+      return;
+    }
+
+    parent.appendChild(element("h4", "", "Parameters"));
+
+    var table = element("table");
+    var header = element("tr");
+
+    ["Name", "In", "Type", "Description"].forEach(function (title) {
+      header.appendChild(element("th", "", title));
+    });
+
+    table.appendChild(header);
+
+    list.forEach(function (parameter) {
+      var row = element("tr");
+
+      row.appendChild(element("td", "", parameter.name + (parameter.required ? " *" : "")));
+      row.appendChild(element("td", "", parameter.in));
+      row.appendChild(element("td", "", schemaText(spec, parameter.schema, "", [])));
+      row.appendChild(element("td", "", parameter.description || ""));
+
+      table.appendChild(row);
+    });
+
+    parent.appendChild(table);
+  }
+
+  // Renders a single operation.
+  function operation(spec, path, method, details) {
+    var container = element("details", "operation");
+    var summary = element("summary");
+
+    summary.appendChild(element("span", "method " + method, method));
+    summary.appendChild(element("code", "", path));
+    summary.appendChild(document.createTextNode(" " + (details.summary || "")));
+
+    if (details.security && details.security.length > 0) {
+      summary.appendChild(element("span", "secured", " (authenticated)"));
+    }
+
+    container.appendChild(summary);
+
+    var body = element("div");
+    description(body, details.description);
+    parameters(spec, body, details.parameters);
+
+    if (details.requestBody) {
+      body.appendChild(element("h4", "", "Request body"));
+      description(body, details.requestBody.description);
+      body.appendChild(element("pre", "", schemaText(spec, contentSchema(details.requestBody.content), "", [])));
+    }
+
+    body.appendChild(element("h4", "", "Responses"));
+
+    Object.keys(details.responses || {}).sort().forEach(function (status) {
+      var response = details.responses[status];
+      var schema = contentSchema(response.content);
+
+      body.appendChild(element("p", "", status + " " + (response.description || "")));
+
+      if (schema) {
+        body.appendChild(element("pre", "", schemaText(spec, schema, "", [])));
+      }
+    });
+
+    container.appendChild(body);
+    return container;
+  }
+
+  // Renders the whole specification, grouping operations by their first tag.
+  function render(spec) {
+    var info = spec.info || {};
+    var groups = {};
+
+    root.textContent = "";
+    root.appendChild(element("h1", "", info.title));
+    root.appendChild(element("p", "muted", "Version " + info.version + ", OpenAPI " + spec.openapi));
+    description(root, info.description);
+
+    Object.keys(spec.paths || {}).sort().forEach(function (path) {
+      methods.forEach(function (method) {
+        var details = spec.paths[path][method];
+
+        if (details) {
+          var tag = (details.tags && details.tags[0]) || "default";
+          (groups[tag] = groups[tag] || []).push(operation(spec, path, method, details));
+        }
+      });
+    });
+
+    Object.keys(groups).sort().forEach(function (tag) {
+      root.appendChild(element("h2", "", tag));
+
+      groups[tag].forEach(function (node) {
+        root.appendChild(node);
+      });
+    });
+
+    var schemes = (spec.components && spec.components.securitySchemes) || {};
+
+    if (Object.keys(schemes).length > 0) {
+      root.appendChild(element("h2", "", "Authentication"));
+
+      Object.keys(schemes).sort().forEach(function (name) {
+        var scheme = schemes[name];
+
+        root.appendChild(element("h4", "", name + " (" + (scheme.scheme || scheme.type) + (scheme.name ? ", header " + scheme.name : "") + ")"));
+        description(root, scheme.description);
+      });
+    }
+  }
+
+  fetch(root.getAttribute("data-spec-url"), { headers: { Accept: "application/json" } })
+    .then(function (response) {
+      if (!response.ok) {
+        throw new Error("status " + response.status);
+      }
+
+      return response.json();
+    })
+    .then(render)
+    .catch(function (err) {
+      root.textContent = "";
+      root.appendChild(element("p", "status error", "Failed to load the specification: " + err.message));
+    });
+})();
@@ -0,0 +1,138 @@
+package openapi
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"path"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/router"
+)
+
+// Description:
+//
+//	The assets of the documentation page: the page template, its script and its stylesheet.
+//	The assets are embedded, so that the page loads no third-party resources and works offline.
+//
+//go:embed assets
+var assets embed.FS
+
+// Description:
+//
+//	The content types of the served assets, by file extension.
+var assetContentTypes = map[string]string{
+	".css": "text/css; charset=utf-8",
+	".js":  "text/javascript; charset=utf-8",
+}
+
+// Description:
+//
+//	Encodes a document as indented JSON, with a trailing newline.
+//	The encoding is deterministic, so that it can be compared with a checked-in copy.
+//
+// Parameters:
+//
+//	document The document.
+//
+// Returns:
+//
+//	The encoded document, or an error if encoding fails.
+func Encode(document *Document) ([]byte, error) {
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// Description:
+//
+//	Creates a handler serving a document as JSON.
+//	The document is encoded once, when the handler is created.
+//
+// Parameters:
+//
+//	document The document.
+//
+// Returns:
+//
+//	The handler, or an error if encoding fails.
+func Handler(document *Document) (router.RouterHandlerFunc, error) {
+	data, err := Encode(document)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(request *api.APIRequest) *api.APIResponse {
+		return api.Raw(http.StatusOK, "application/json", data)
+	}, nil
+}
+
+// Description:
+//
+//	Creates a handler serving an HTML page, which renders the document served at the given URL.
+//	The page loads its script and stylesheet from the given assets URL, served by AssetsHandler.
+//
+// Parameters:
+//
+//	title 		The page title.
+//	specURL 	The URL of the document, e.g. /openapi.json.
+//	assetsURL 	The URL the assets are served on, e.g. /docs/assets.
+//
+// Returns:
+//
+//	The handler, or an error if rendering the page fails.
+func DocsHandler(title string, specURL string, assetsURL string) (router.RouterHandlerFunc, error) {
+	page, err := template.ParseFS(assets, "assets/docs.html")
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := bytes.Buffer{}
+
+	err = page.Execute(&buffer, struct {
+		Title     string
+		SpecURL   string
+		AssetsURL string
+	}{title, specURL, assetsURL})
+
+	if err != nil {
+		return nil, err
+	}
+
+	data := buffer.Bytes()
+
+	return func(request *api.APIRequest) *api.APIResponse {
+		return api.Raw(http.StatusOK, "text/html; charset=utf-8", data)
+	}, nil
+}
+
+// Description:
+//
+//	Creates a handler serving the script and stylesheet of the documentation page.
+//	The handler must be registered on a route with a :name path parameter, e.g. /docs/assets/:name.
+//
+// Returns:
+//
+//	The handler.
+func AssetsHandler() router.RouterHandlerFunc {
+	return func(request *api.APIRequest) *api.APIResponse {
+		name := request.PathParameters["name"]
+
+		contentType, ok := assetContentTypes[path.Ext(name)]
+		if !ok {
+			return api.Raw(http.StatusNotFound, "text/plain; charset=utf-8", []byte("not found"))
+		}
+
+		data, err := assets.ReadFile("assets/" + name)
+		if err != nil {
+			return api.Raw(http.StatusNotFound, "text/plain; charset=utf-8", []byte("not found"))
+		}
+
+		return api.Raw(http.StatusOK, contentType, data)
+	}
+}
@@ -0,0 +1,544 @@
+package openapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/router"
+)
+
+const (
+
+	// The OpenAPI version of generated documents.
+	Version = "3.0.3"
+)
+
+// Description:
+//
+//	The configuration of generated documents.
+type Config struct {
+
+	// The API metadata.
+	Info Info
+
+	// The media types of request and response bodies, e.g. application/json.
+	// Defaults to application/json if empty.
+	MediaTypes []string
+
+	// The security schemes accepted by secured routes, by name.
+	SecuritySchemes map[string]SecurityScheme
+}
+
+// Description:
+//
+//	An OpenAPI document.
+type Document struct {
+
+	// The OpenAPI version.
+	OpenAPI string `json:"openapi"`
+
+	// The API metadata.
+	Info Info `json:"info"`
+
+	// The operations, by path template.
+	Paths map[string]*PathItem `json:"paths"`
+
+	// The reusable schemas and security schemes.
+	Components Components `json:"components"`
+}
+
+// Description:
+//
+//	The API metadata.
+type Info struct {
+
+	// The title of the API.
+	Title string `json:"title"`
+
+	// A description of the API.
+	Description string `json:"description,omitempty"`
+
+	// The version of the API.
+	Version string `json:"version"`
+}
+
+// Description:
+//
+//	The operations of a path.
+type PathItem struct {
+
+	// The GET operation.
+	Get *Operation `json:"get,omitempty"`
+
+	// The PUT operation.
+	Put *Operation `json:"put,omitempty"`
+
+	// The POST operation.
+	Post *Operation `json:"post,omitempty"`
+
+	// The DELETE operation.
+	Delete *Operation `json:"delete,omitempty"`
+
+	// The OPTIONS operation.
+	Options *Operation `json:"options,omitempty"`
+
+	// The HEAD operation.
+	Head *Operation `json:"head,omitempty"`
+
+	// The PATCH operation.
+	Patch *Operation `json:"patch,omitempty"`
+}
+
+// Description:
+//
+//	An operation, i.e. a route.
+type Operation struct {
+
+	// The unique id of the operation.
+	OperationID string `json:"operationId"`
+
+	// A short summary of the operation.
+	Summary string `json:"summary,omitempty"`
+
+	// A detailed description of the operation.
+	Description string `json:"description,omitempty"`
+
+	// The tags grouping the operation.
+	Tags []string `json:"tags,omitempty"`
+
+	// The path and query parameters.
+	Parameters []Parameter `json:"parameters,omitempty"`
+
+	// The request body.
+	RequestBody *RequestBody `json:"requestBody,omitempty"`
+
+	// The responses, by status code.
+	Responses map[string]*Response `json:"responses"`
+
+	// The security requirements, any of which must be met.
+	Security []map[string][]string `json:"security,omitempty"`
+}
+
+// Description:
+//
+//	A path or query parameter.
+type Parameter struct {
+
+	// The name of the parameter.
+	Name string `json:"name"`
+
+	// The location of the parameter: path or query.
+	In string `json:"in"`
+
+	// A description of the parameter.
+	Description string `json:"description,omitempty"`
+
+	// Whether the parameter is required.
+	Required bool `json:"required,omitempty"`
+
+	// The schema of the parameter.
+	Schema *Schema `json:"schema"`
+}
+
+// Description:
+//
+//	A request body.
+type RequestBody struct {
+
+	// Whether the body is required.
+	Required bool `json:"required"`
+
+	// The body schema, by media type.
+	Content map[string]MediaType `json:"content"`
+}
+
+// Description:
+//
+//	A response.
+type Response struct {
+
+	// A description of the response.
+	Description string `json:"description"`
+
+	// The body schema, by media type.
+	Content map[string]MediaType `json:"content,omitempty"`
+}
+
+// Description:
+//
+//	The schema of a body with a specific media type.
+type MediaType struct {
+
+	// The body schema.
+	Schema *Schema `json:"schema"`
+}
+
+// Description:
+//
+//	The reusable components of a document.
+type Components struct {
+
+	// The schemas, by name.
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+
+	// The security schemes, by name.
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// Description:
+//
+//	A security scheme.
+type SecurityScheme struct {
+
+	// The scheme type: http or apiKey.
+	Type string `json:"type"`
+
+	// A description of the scheme.
+	Description string `json:"description,omitempty"`
+
+	// The HTTP authentication scheme of http schemes, e.g. bearer.
+	Scheme string `json:"scheme,omitempty"`
+
+	// The format of bearer tokens, e.g. JWT.
+	BearerFormat string `json:"bearerFormat,omitempty"`
+
+	// The name of the header of apiKey schemes.
+	Name string `json:"name,omitempty"`
+
+	// The location of the key of apiKey schemes, e.g. header.
+	In string `json:"in,omitempty"`
+}
+
+// Description:
+//
+//	Generates an OpenAPI document from the documentation of the given routes.
+//	Hidden routes are skipped. Secured routes accept any of the configured security schemes.
+//
+// Parameters:
+//
+//	config The document configuration.
+//	routes The routes, e.g. of Router.Routes.
+//
+// Returns:
+//
+//	The generated document.
+func Generate(config Config, routes []*router.Route) *Document {
+	mediaTypes := config.MediaTypes
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/json"}
+	}
+
+	schemas := newSchemaRegistry()
+
+	document := &Document{
+		OpenAPI: Version,
+		Info:    config.Info,
+		Paths:   make(map[string]*PathItem),
+	}
+
+	for _, route := range routes {
+		if route.Doc.Hidden {
+			continue
+		}
+
+		path, pathParameters := convertPath(route.Path)
+
+		item, ok := document.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			document.Paths[path] = item
+		}
+
+		operation := createOperation(route, pathParameters, mediaTypes, schemas)
+
+		if route.Doc.Secured {
+			for _, name := range sortedKeys(config.SecuritySchemes) {
+				operation.Security = append(operation.Security, map[string][]string{name: {}})
+			}
+		}
+
+		item.set(route.Method, operation)
+	}
+
+	document.Components = Components{
+		Schemas:         schemas.schemas,
+		SecuritySchemes: config.SecuritySchemes,
+	}
+
+	return document
+}
+
+// Description:
+//
+//	Creates the operation of a route.
+//
+// Parameters:
+//
+//	route 			The route.
+//	pathParameters 	The names of the path parameters, in order.
+//	mediaTypes 		The media types of request and response bodies.
+//	schemas 		The registry of the component schemas.
+//
+// Returns:
+//
+//	The operation.
+func createOperation(route *router.Route, pathParameters []string, mediaTypes []string, schemas *schemaRegistry) *Operation {
+	doc := route.Doc
+
+	operation := &Operation{
+		OperationID: doc.OperationID,
+		Summary:     doc.Summary,
+		Description: doc.Description,
+		Tags:        doc.Tags,
+		Responses:   make(map[string]*Response),
+	}
+
+	if operation.OperationID == "" {
+		operation.OperationID = deriveOperationID(route.Method, route.Path)
+	}
+
+	if len(doc.Scopes) > 0 {
+		requirement := "Requires the scopes: " + strings.Join(doc.Scopes, ", ") + "."
+		if operation.Description != "" {
+			requirement = operation.Description + "\n\n" + requirement
+		}
+
+		operation.Description = requirement
+	}
+
+	for _, name := range pathParameters {
+		parameter := findParameter(doc.Path, name)
+		parameter.Required = true
+
+		operation.Parameters = append(operation.Parameters, createParameter(parameter, "path", schemas))
+	}
+
+	for _, parameter := range doc.Query {
+		operation.Parameters = append(operation.Parameters, createParameter(parameter, "query", schemas))
+	}
+
+	if doc.Request != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  createContent(doc.Request, mediaTypes, schemas),
+		}
+	}
+
+	for _, response := range doc.Responses {
+		description := response.Description
+		if description == "" {
+			description = http.StatusText(response.StatusCode)
+		}
+
+		operation.Responses[strconv.Itoa(response.StatusCode)] = &Response{
+			Description: description,
+			Content:     createContent(response.Body, mediaTypes, schemas),
+		}
+	}
+
+	if doc.Secured {
+		addResponse(operation, http.StatusUnauthorized, "Missing or invalid credentials.", api.ErrorResponseBody{}, mediaTypes, schemas)
+	}
+
+	if len(doc.Scopes) > 0 {
+		addResponse(operation, http.StatusForbidden, "Missing scope.", api.ErrorResponseBody{}, mediaTypes, schemas)
+	}
+
+	if len(operation.Responses) == 0 {
+		operation.Responses["default"] = &Response{Description: "The response."}
+	}
+
+	return operation
+}
+
+// Description:
+//
+//	Adds a response to an operation, unless a response with the status code is documented.
+//
+// Parameters:
+//
+//	operation 	The operation.
+//	statusCode 	The status code of the response.
+//	description A description of the response.
+//	body 		A value of the response body type, or nil.
+//	mediaTypes 	The media types of the response body.
+//	schemas 	The registry of the component schemas.
+func addResponse(operation *Operation, statusCode int, description string, body interface{}, mediaTypes []string, schemas *schemaRegistry) {
+	key := strconv.Itoa(statusCode)
+	if _, ok := operation.Responses[key]; ok {
+		return
+	}
+
+	operation.Responses[key] = &Response{
+		Description: description,
+		Content:     createContent(body, mediaTypes, schemas),
+	}
+}
+
+// Description:
+//
+//	Finds the documentation of a parameter.
+//
+// Parameters:
+//
+//	parameters 	The documented parameters.
+//	name 		The name of the parameter.
+//
+// Returns:
+//
+//	The documentation, or an undocumented string parameter if none is found.
+func findParameter(parameters []router.ParameterDoc, name string) router.ParameterDoc {
+	for _, parameter := range parameters {
+		if parameter.Name == name {
+			return parameter
+		}
+	}
+
+	return router.ParameterDoc{Name: name}
+}
+
+// Description:
+//
+//	Creates a parameter.
+//
+// Parameters:
+//
+//	parameter 	The parameter documentation.
+//	location 	The location of the parameter: path or query.
+//	schemas 	The registry of the component schemas.
+//
+// Returns:
+//
+//	The parameter.
+func createParameter(parameter router.ParameterDoc, location string, schemas *schemaRegistry) Parameter {
+	var value interface{} = parameter.Type
+	if value == nil {
+		value = ""
+	}
+
+	schema := schemas.schemaOf(value)
+
+	if parameter.Format != "" && schema.Items != nil {
+		schema.Items.Format = parameter.Format
+	} else if parameter.Format != "" {
+		schema.Format = parameter.Format
+	}
+
+	return Parameter{
+		Name:        parameter.Name,
+		In:          location,
+		Description: parameter.Description,
+		Required:    parameter.Required,
+		Schema:      schema,
+	}
+}
+
+// Description:
+//
+//	Creates the content of a body for all media types.
+//
+// Parameters:
+//
+//	body 		A value of the body type, or nil.
+//	mediaTypes 	The media types.
+//	schemas 	The registry of the component schemas.
+//
+// Returns:
+//
+//	The content by media type, or nil if there is no body.
+func createContent(body interface{}, mediaTypes []string, schemas *schemaRegistry) map[string]MediaType {
+	if body == nil {
+		return nil
+	}
+
+	schema := schemas.schemaOf(body)
+	content := make(map[string]MediaType)
+
+	for _, mediaType := range mediaTypes {
+		content[mediaType] = MediaType{Schema: schema}
+	}
+
+	return content
+}
+
+// Description:
+//
+//	Converts a path template to the OpenAPI syntax, e.g. /albums/:id to /albums/{id}.
+//
+// Parameters:
+//
+//	path The path template.
+//
+// Returns:
+//
+//	The converted path and the names of its parameters.
+func convertPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	parameters := make([]string, 0)
+
+	for index, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			name := segment[1:]
+			parameters = append(parameters, name)
+			segments[index] = "{" + name + "}"
+		}
+	}
+
+	return strings.Join(segments, "/"), parameters
+}
+
+// Description:
+//
+//	Derives an operation id from a method and path, e.g. getAlbumsId for GET /albums/:id.
+//
+// Parameters:
+//
+//	method 	The http method.
+//	path 	The path template.
+//
+// Returns:
+//
+//	The operation id.
+func deriveOperationID(method string, path string) string {
+	id := strings.ToLower(method)
+
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.TrimLeft(segment, ":*")
+		if segment == "" {
+			continue
+		}
+
+		id += strings.ToUpper(segment[:1]) + segment[1:]
+	}
+
+	return id
+}
+
+// Description:
+//
+//	Sets the operation of the given method.
+//
+// Parameters:
+//
+//	method 		The http method.
+//	operation 	The operation.
+func (item *PathItem) set(method string, operation *Operation) {
+	switch method {
+	case http.MethodGet:
+		item.Get = operation
+	case http.MethodPut:
+		item.Put = operation
+	case http.MethodPost:
+		item.Post = operation
+	case http.MethodDelete:
+		item.Delete = operation
+	case http.MethodOptions:
+		item.Options = operation
+	case http.MethodHead:
+		item.Head = operation
+	case http.MethodPatch:
+		item.Patch = operation
+	}
+}
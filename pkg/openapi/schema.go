@@ -0,0 +1,278 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Description:
+//
+//	A JSON schema, in the dialect of OpenAPI 3.0.
+type Schema struct {
+
+	// A reference to a component schema, e.g. #/components/schemas/AlbumInfo.
+	Ref string `json:"$ref,omitempty"`
+
+	// The type: object, array, string, integer, number or boolean.
+	Type string `json:"type,omitempty"`
+
+	// The format, e.g. date-time or int64.
+	Format string `json:"format,omitempty"`
+
+	// Whether the value may be null.
+	Nullable bool `json:"nullable,omitempty"`
+
+	// The minimum of numeric values.
+	Minimum *float64 `json:"minimum,omitempty"`
+
+	// The schema of array items.
+	Items *Schema `json:"items,omitempty"`
+
+	// The properties of objects, by name.
+	Properties map[string]*Schema `json:"properties,omitempty"`
+
+	// The schema of additional object properties, i.e. map values.
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+}
+
+// Description:
+//
+//	Collects the component schemas of named struct types.
+type schemaRegistry struct {
+
+	// The component schemas, by name.
+	schemas map[string]*Schema
+
+	// The component names, by type.
+	names map[reflect.Type]string
+}
+
+// Description:
+//
+//	The type of times, which are marshalled as strings in the date-time format.
+var timeType = reflect.TypeOf(time.Time{})
+
+// Description:
+//
+//	The type of custom JSON marshallers, whose schema cannot be derived.
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// Description:
+//
+//	Creates an empty schema registry.
+//
+// Returns:
+//
+//	The created registry.
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// Description:
+//
+//	Gets the schema of the type of a value.
+//
+// Parameters:
+//
+//	value The value.
+//
+// Returns:
+//
+//	The schema.
+func (registry *schemaRegistry) schemaOf(value interface{}) *Schema {
+	return registry.schemaOfType(reflect.TypeOf(value))
+}
+
+// Description:
+//
+//	Gets the schema of a type, as marshalled by encoding/json.
+//	Named struct types are registered as component schemas and referenced.
+//
+// Parameters:
+//
+//	valueType The type.
+//
+// Returns:
+//
+//	The schema.
+func (registry *schemaRegistry) schemaOfType(valueType reflect.Type) *Schema {
+	if valueType.Kind() == reflect.Pointer {
+		schema := registry.schemaOfType(valueType.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+
+		schema.Nullable = true
+		return schema
+	}
+
+	if valueType == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	if valueType.Implements(marshalerType) {
+		return &Schema{}
+	}
+
+	switch valueType.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := 0.0
+		return &Schema{Type: "integer", Minimum: &minimum}
+
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Slice, reflect.Array:
+		if valueType.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: registry.schemaOfType(valueType.Elem())}
+
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: registry.schemaOfType(valueType.Elem())}
+
+	case reflect.Struct:
+		if valueType.Name() == "" {
+			return registry.structSchema(valueType)
+		}
+
+		return &Schema{Ref: "#/components/schemas/" + registry.register(valueType)}
+
+	default:
+		return &Schema{}
+	}
+}
+
+// Description:
+//
+//	Registers a named struct type as component schema.
+//	Types of different packages with the same name are prefixed with their package name.
+//
+// Parameters:
+//
+//	valueType The struct type.
+//
+// Returns:
+//
+//	The component name.
+func (registry *schemaRegistry) register(valueType reflect.Type) string {
+	if name, ok := registry.names[valueType]; ok {
+		return name
+	}
+
+	name := valueType.Name()
+	if _, taken := registry.schemas[name]; taken {
+		packagePath := strings.Split(valueType.PkgPath(), "/")
+		name = packagePath[len(packagePath)-1] + "." + name
+	}
+
+	// Registered before the properties are derived, so that recursive types terminate.
+	registry.names[valueType] = name
+	registry.schemas[name] = &Schema{}
+
+	*registry.schemas[name] = *registry.structSchema(valueType)
+	return name
+}
+
+// Description:
+//
+//	Derives the object schema of a struct type from its exported fields and their json tags.
+//	Fields of embedded structs are promoted, like encoding/json does.
+//
+// Parameters:
+//
+//	valueType The struct type.
+//
+// Returns:
+//
+//	The object schema.
+func (registry *schemaRegistry) structSchema(valueType reflect.Type) *Schema {
+	schema := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	for index := 0; index < valueType.NumField(); index++ {
+		field := valueType.Field(index)
+		tag := field.Tag.Get("json")
+
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				// Fields of the embedding struct take precedence over promoted fields.
+				for property, propertySchema := range registry.structSchema(embedded).Properties {
+					if _, ok := schema.Properties[property]; !ok {
+						schema.Properties[property] = propertySchema
+					}
+				}
+
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = registry.schemaOfType(field.Type)
+	}
+
+	return schema
+}
+
+// Description:
+//
+//	Gets the keys of a map in ascending order.
+//
+// Parameters:
+//
+//	values The map.
+//
+// Returns:
+//
+//	The sorted keys.
+func sortedKeys(values map[string]SecurityScheme) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -1,56 +1,73 @@
 package parallel
 
 import (
-	"crypto/sha256"
-	"encoding/base64"
-	"strconv"
-	"time"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 )
 
 // Description:
 //
-//	A parallel context.
-//	Parallel contexts are used to identify the current thread.
-//	This can be useful for logging in endpoints. If an endpoint is called multiple times,
-//	asynchronous logging can distort the order of the logs, so that the correct logs might not be identified easily.
+//	The context key type of this package.
+//	Prevents collisions with context keys of other packages.
+type contextKey struct{}
+
+// Description:
 //
-//	Using a parallel context, every log can be assigned an identifier, so that the behavior can be comprehended.
-type Context struct {
+//	The context key holding the request id.
+var requestIDKey = contextKey{}
 
-	// The id of the context. Equals the short id.
-	ID string
+// Description:
+//
+//	Generates a new random request id.
+//	Request ids are used to identify the current request across logs.
+//	If an endpoint is called multiple times, asynchronous logging can distort the order of the logs,
+//	so that the correct logs might not be identified easily.
+//
+// Returns:
+//
+//	The generated id, 32 hex characters.
+func NewID() string {
+	bytes := make([]byte, 16)
 
-	// The short id of the context. Unique in most of the cases.
-	ShortID string
+	// crypto/rand.Read does not fail on supported platforms.
+	rand.Read(bytes)
 
-	// The long id of the context. Unique in pretty much every case.
-	LongID string
+	return hex.EncodeToString(bytes)
 }
 
 // Description:
 //
-//	Creates a new parallel context.
+//	Attaches a request id to the given context.
+//
+// Parameters:
+//
+//	ctx The parent context.
+//	id 	The request id.
 //
 // Returns:
 //
-//	The created context.
-func NewContext() *Context {
-	sha := sha256.New()
-
-	currentTime := time.Now().UnixNano()
-
-	input := strconv.FormatInt(currentTime, 10)
-	bytes := []byte(input)
-
-	sha.Write(bytes)
-
-	result := sha.Sum(nil)
-	decoded := base64.URLEncoding.EncodeToString(result)
-	shortend := decoded[0:7]
+//	The derived context holding the request id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
 
-	return &Context{
-		ID:      shortend,
-		ShortID: shortend,
-		LongID:  decoded,
+// Description:
+//
+//	Gets the request id attached to the given context.
+//
+// Parameters:
+//
+//	ctx The context.
+//
+// Returns:
+//
+//	The request id, or an empty string if no id is attached.
+func IDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
 	}
+
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
 }
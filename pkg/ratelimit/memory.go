@@ -0,0 +1,156 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/clock"
+)
+
+// Description:
+//
+//	The interval in which idle buckets are removed from memory.
+const sweepInterval = time.Minute
+
+// Description:
+//
+//	A token bucket.
+type bucket struct {
+
+	// The available tokens.
+	tokens float64
+
+	// The time the tokens were last refilled.
+	updated time.Time
+
+	// The time the bucket is full again, after which it can be dropped.
+	full time.Time
+}
+
+// Description:
+//
+//	An in-memory backend, local to the process.
+//	Budgets are enforced per service instance.
+type MemoryBackend struct {
+
+	// Guards the buckets.
+	mutex sync.Mutex
+
+	// The buckets, by key.
+	buckets map[string]*bucket
+
+	// The clock used for refilling.
+	clock clock.Clock
+
+	// The time idle buckets were last removed.
+	swept time.Time
+}
+
+// Description:
+//
+//	Creates an in-memory backend.
+//
+// Parameters:
+//
+//	clock The clock used for refilling.
+//
+// Returns:
+//
+//	The created backend.
+func NewMemoryBackend(clock clock.Clock) *MemoryBackend {
+	return &MemoryBackend{
+		buckets: make(map[string]*bucket),
+		clock:   clock,
+		swept:   clock.Now(),
+	}
+}
+
+// Description:
+//
+//	Takes a token from the bucket of the given key.
+//
+// Parameters:
+//
+//	ctx 	The request context.
+//	key 	The bucket key.
+//	limit 	The budget of the bucket.
+//
+// Returns:
+//
+//	The outcome. Never fails.
+func (backend *MemoryBackend) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+
+	now := backend.clock.Now()
+	capacity := limit.capacity()
+	rate := limit.rate()
+
+	backend.sweep(now)
+
+	current, ok := backend.buckets[key]
+	if !ok {
+		current = &bucket{tokens: capacity, updated: now}
+		backend.buckets[key] = current
+	}
+
+	elapsed := now.Sub(current.updated).Seconds()
+	current.tokens = math.Min(capacity, current.tokens+elapsed*rate)
+	current.updated = now
+
+	result := Result{
+		Limit: int(capacity),
+	}
+
+	if current.tokens >= 1 {
+		current.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - current.tokens) / rate)
+	}
+
+	result.Remaining = int(current.tokens)
+	result.Reset = seconds((capacity - current.tokens) / rate)
+	current.full = now.Add(result.Reset)
+
+	return result, nil
+}
+
+// Description:
+//
+//	Removes buckets which are full again, as they are equivalent to new buckets.
+//	Runs at most once per sweep interval. Requires the mutex to be held.
+//
+// Parameters:
+//
+//	now The current time.
+func (backend *MemoryBackend) sweep(now time.Time) {
+	if now.Sub(backend.swept) < sweepInterval {
+		return
+	}
+
+	backend.swept = now
+
+	for key, current := range backend.buckets {
+		if !now.Before(current.full) {
+			delete(backend.buckets, key)
+		}
+	}
+}
+
+// Description:
+//
+//	Converts fractional seconds to a duration.
+//
+// Parameters:
+//
+//	value The seconds.
+//
+// Returns:
+//
+//	The duration.
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
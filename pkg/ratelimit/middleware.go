@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/router"
+)
+
+// Description:
+//
+//	The logger of this package.
+var logger = logging.Named("ratelimit")
+
+// Description:
+//
+//	The rate limiting policy of a group of routes.
+type Policy struct {
+
+	// The budget of read requests, i.e. GET, HEAD and OPTIONS.
+	Read Limit
+
+	// The budget of write requests, i.e. all other methods.
+	Write Limit
+
+	// Derives the client key of a request. Requests without key are not limited.
+	Key KeyFunc
+}
+
+// Description:
+//
+//	Creates a middleware enforcing the given policy.
+//	Every response carries the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers.
+//	If middlewares are nested, the headers of the innermost, i.e. most specific, policy are kept.
+//	Requests exceeding the budget are answered with 429 and a Retry-After header.
+//	If the backend fails, requests are allowed.
+//
+// Parameters:
+//
+//	backend The token bucket backend.
+//	policy 	The rate limiting policy.
+//
+// Returns:
+//
+//	The middleware, or an error if a budget of the policy is invalid.
+func Middleware(backend Backend, policy Policy) (router.Middleware, error) {
+	err := policy.Read.validate()
+	if err != nil {
+		return nil, fmt.Errorf("ratelimit: invalid read budget: %w", err)
+	}
+
+	err = policy.Write.validate()
+	if err != nil {
+		return nil, fmt.Errorf("ratelimit: invalid write budget: %w", err)
+	}
+
+	middleware := func(request *api.APIRequest, next router.RouterHandlerFunc) *api.APIResponse {
+		key := policy.Key(request)
+		if key == "" {
+			return next(request)
+		}
+
+		budget, limit := "write", policy.Write
+		switch request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			budget, limit = "read", policy.Read
+		}
+
+		result, err := backend.Take(request.Context, budget+":"+key, limit)
+		if err != nil {
+			logger.Warnf("rate limit backend failed, allowing request: %s", err)
+			return next(request)
+		}
+
+		var response *api.APIResponse
+
+		if result.Allowed {
+			response = next(request)
+		} else {
+			request.Logger.Warnf("rate limit exceeded for %s budget", budget)
+
+			response = &api.APIResponse{
+				StatusCode: http.StatusTooManyRequests,
+				Headers: map[string]string{
+					"Retry-After": strconv.Itoa(ceilSeconds(result.RetryAfter)),
+				},
+				Body: api.ErrorResponseBody{
+					Message:   "rate limit exceeded",
+					RequestID: request.RequestID,
+				},
+			}
+		}
+
+		if response.Headers == nil {
+			response.Headers = make(map[string]string)
+		}
+
+		if _, ok := response.Headers["RateLimit-Limit"]; ok && result.Allowed {
+			return response
+		}
+
+		response.Headers["RateLimit-Limit"] = strconv.Itoa(result.Limit)
+		response.Headers["RateLimit-Remaining"] = strconv.Itoa(result.Remaining)
+		response.Headers["RateLimit-Reset"] = strconv.Itoa(ceilSeconds(result.Reset))
+		response.Headers["RateLimit-Policy"] = fmt.Sprintf("%d;w=%d", limit.Requests, ceilSeconds(limit.Period))
+
+		return response
+	}
+
+	return middleware, nil
+}
+
+// Description:
+//
+//	Rounds a duration up to full seconds.
+//
+// Parameters:
+//
+//	duration The duration.
+//
+// Returns:
+//
+//	The seconds.
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,215 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/api"
+)
+
+// Description:
+//
+//	A token bucket budget.
+//	The bucket holds up to Burst tokens and is refilled with Requests tokens per Period.
+//	Every request takes one token.
+type Limit struct {
+
+	// The number of requests per period.
+	Requests int
+
+	// The period in which the requests are refilled.
+	Period time.Duration
+
+	// The maximum number of tokens, i.e. requests which can be sent at once.
+	// If zero, the number of requests per period is used.
+	Burst int
+}
+
+// Description:
+//
+//	The outcome of taking a token.
+type Result struct {
+
+	// Whether the request is allowed.
+	Allowed bool
+
+	// The capacity of the bucket.
+	Limit int
+
+	// The number of remaining tokens.
+	Remaining int
+
+	// The duration until the bucket is full again.
+	Reset time.Duration
+
+	// The duration until the next token is available. Zero if the request is allowed.
+	RetryAfter time.Duration
+}
+
+// Description:
+//
+//	The storage of token buckets.
+//	Implementations backed by a shared store enforce budgets across service instances.
+type Backend interface {
+
+	// Description:
+	//
+	//	Takes a token from the bucket of the given key.
+	//
+	// Parameters:
+	//
+	//	ctx 	The request context.
+	//	key 	The bucket key.
+	//	limit 	The budget of the bucket.
+	//
+	// Returns:
+	//
+	//	The outcome, or an error if the backend is unavailable.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Description:
+//
+//	Function definition for deriving the client key of a request.
+//	Returns an empty string if the request carries no such key.
+type KeyFunc = func(request *api.APIRequest) string
+
+// Description:
+//
+//	Validates the budget.
+//	Budgets without requests or period would refill at an infinite or undefined rate.
+//
+// Returns:
+//
+//	An error if the number of requests, the period or the burst is invalid.
+func (limit Limit) validate() error {
+	if limit.Requests <= 0 {
+		return fmt.Errorf("requests must be positive, got %d", limit.Requests)
+	}
+
+	if limit.Period <= 0 {
+		return fmt.Errorf("period must be positive, got %s", limit.Period)
+	}
+
+	if limit.Burst < 0 {
+		return fmt.Errorf("burst must not be negative, got %d", limit.Burst)
+	}
+
+	return nil
+}
+
+// Description:
+//
+//	Gets the capacity of the bucket.
+//
+// Returns:
+//
+//	The burst, or the number of requests per period if no burst is set.
+func (limit Limit) capacity() float64 {
+	if limit.Burst > 0 {
+		return float64(limit.Burst)
+	}
+
+	return float64(limit.Requests)
+}
+
+// Description:
+//
+//	Gets the refill rate of the bucket.
+//
+// Returns:
+//
+//	The tokens per second.
+func (limit Limit) rate() float64 {
+	return float64(limit.Requests) / limit.Period.Seconds()
+}
+
+// Description:
+//
+//	Keys requests by client IP.
+//
+// Returns:
+//
+//	The key function.
+func ByClientIP() KeyFunc {
+	return func(request *api.APIRequest) string {
+		if request.ClientIP == "" {
+			return ""
+		}
+
+		return "ip:" + request.ClientIP
+	}
+}
+
+// Description:
+//
+//	Keys requests by the subject of the authenticated principal.
+//	Requires the authentication middleware to run before the rate limiting middleware.
+//
+// Returns:
+//
+//	The key function.
+func BySubject() KeyFunc {
+	return func(request *api.APIRequest) string {
+		if request.Principal == nil {
+			return ""
+		}
+
+		return "subject:" + request.Principal.Issuer + "/" + request.Principal.Subject
+	}
+}
+
+// Description:
+//
+//	Keys requests by the value of a header, e.g. an API key.
+//	The value is hashed, so that credentials are never stored in a backend.
+//
+// Parameters:
+//
+//	name The header name, e.g. X-API-Key.
+//
+// Returns:
+//
+//	The key function.
+func ByHeader(name string) KeyFunc {
+	return func(request *api.APIRequest) string {
+		value := request.Header(name)
+		if value == "" {
+			return ""
+		}
+
+		hash := sha256.Sum256([]byte(value))
+		return "header:" + hex.EncodeToString(hash[:16])
+	}
+}
+
+// Description:
+//
+//	Keys requests by the first key function returning a key.
+//
+// Example:
+//
+//	ratelimit.FirstOf(ratelimit.ByHeader("X-API-Key"), ratelimit.ByClientIP())
+//
+// Parameters:
+//
+//	keys The key functions, in order of precedence.
+//
+// Returns:
+//
+//	The key function.
+func FirstOf(keys ...KeyFunc) KeyFunc {
+	return func(request *api.APIRequest) string {
+		for _, key := range keys {
+			value := key(request)
+			if value != "" {
+				return value
+			}
+		}
+
+		return ""
+	}
+}
@@ -0,0 +1,262 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/router"
+)
+
+// Description:
+//
+//	A clock which is advanced manually.
+type manualClock struct {
+
+	// The current time.
+	now time.Time
+}
+
+// Description:
+//
+//	Gets the current time.
+//
+// Returns:
+//
+//	The current time.
+func (clock *manualClock) Now() time.Time {
+	return clock.now
+}
+
+// Description:
+//
+//	Creates a request from the given client IP.
+//
+// Parameters:
+//
+//	method 	The request method.
+//	ip 		The client IP.
+//
+// Returns:
+//
+//	The request.
+func newRequest(method string, ip string) *api.APIRequest {
+	return &api.APIRequest{
+		Method:   method,
+		ClientIP: ip,
+		Context:  context.Background(),
+		Logger:   logging.Named("test"),
+	}
+}
+
+// Description:
+//
+//	A handler answering with 200.
+//
+// Parameters:
+//
+//	request The request.
+//
+// Returns:
+//
+//	The response.
+func ok(request *api.APIRequest) *api.APIResponse {
+	return &api.APIResponse{StatusCode: http.StatusOK}
+}
+
+// Description:
+//
+//	Creates a middleware enforcing the given policy, failing the test if the policy is invalid.
+//
+// Parameters:
+//
+//	t 		The test.
+//	backend The token bucket backend.
+//	policy 	The rate limiting policy.
+//
+// Returns:
+//
+//	The middleware.
+func newMiddleware(t *testing.T, backend Backend, policy Policy) router.Middleware {
+	middleware, err := Middleware(backend, policy)
+	if err != nil {
+		t.Fatalf("failed to create middleware: %s", err)
+	}
+
+	return middleware
+}
+
+func TestMemoryBackendRefillsTokens(t *testing.T) {
+	clock := &manualClock{now: time.Unix(0, 0)}
+	backend := NewMemoryBackend(clock)
+	limit := Limit{Requests: 2, Period: time.Second}
+
+	for index := 0; index < 2; index++ {
+		result, _ := backend.Take(context.Background(), "key", limit)
+		if !result.Allowed {
+			t.Fatalf("expected request %d to be allowed", index)
+		}
+	}
+
+	result, _ := backend.Take(context.Background(), "key", limit)
+	if result.Allowed {
+		t.Fatalf("expected the exhausted bucket to deny the request")
+	}
+
+	if result.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("expected retry after 500ms, got %s", result.RetryAfter)
+	}
+
+	other, _ := backend.Take(context.Background(), "other", limit)
+	if !other.Allowed {
+		t.Fatalf("expected buckets to be independent")
+	}
+
+	clock.now = clock.now.Add(500 * time.Millisecond)
+
+	result, _ = backend.Take(context.Background(), "key", limit)
+	if !result.Allowed {
+		t.Fatalf("expected the refilled token to allow the request")
+	}
+}
+
+func TestMemoryBackendHonoursBurst(t *testing.T) {
+	backend := NewMemoryBackend(&manualClock{now: time.Unix(0, 0)})
+	limit := Limit{Requests: 60, Period: time.Minute, Burst: 1}
+
+	first, _ := backend.Take(context.Background(), "key", limit)
+	second, _ := backend.Take(context.Background(), "key", limit)
+
+	if !first.Allowed || second.Allowed {
+		t.Fatalf("expected only one request to be allowed, got %v and %v", first.Allowed, second.Allowed)
+	}
+
+	if first.Limit != 1 {
+		t.Fatalf("expected limit 1, got %d", first.Limit)
+	}
+}
+
+func TestMiddlewareSeparatesReadAndWriteBudgets(t *testing.T) {
+	middleware := newMiddleware(t, NewMemoryBackend(&manualClock{now: time.Unix(0, 0)}), Policy{
+		Read:  Limit{Requests: 2, Period: time.Minute},
+		Write: Limit{Requests: 1, Period: time.Minute},
+		Key:   ByClientIP(),
+	})
+
+	write := middleware(newRequest(http.MethodPost, "10.0.0.1"), ok)
+	if write.StatusCode != http.StatusOK {
+		t.Fatalf("expected the first write to be allowed, got %d", write.StatusCode)
+	}
+
+	denied := middleware(newRequest(http.MethodPost, "10.0.0.1"), ok)
+	if denied.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", denied.StatusCode)
+	}
+
+	if denied.Headers["Retry-After"] != "60" {
+		t.Fatalf("expected Retry-After 60, got %q", denied.Headers["Retry-After"])
+	}
+
+	if denied.Headers["RateLimit-Policy"] != "1;w=60" {
+		t.Fatalf("expected policy 1;w=60, got %q", denied.Headers["RateLimit-Policy"])
+	}
+
+	read := middleware(newRequest(http.MethodGet, "10.0.0.1"), ok)
+	if read.StatusCode != http.StatusOK || read.Headers["RateLimit-Remaining"] != "1" {
+		t.Fatalf("expected the read budget to be separate, got %d with %v", read.StatusCode, read.Headers)
+	}
+}
+
+func TestMiddlewareRejectsInvalidBudgets(t *testing.T) {
+	valid := Limit{Requests: 10, Period: time.Minute}
+
+	tests := []struct {
+		name  string
+		read  Limit
+		write Limit
+	}{
+		{name: "zero read requests", read: Limit{Period: time.Minute}, write: valid},
+		{name: "negative write requests", read: valid, write: Limit{Requests: -1, Period: time.Minute}},
+		{name: "zero read period", read: Limit{Requests: 10}, write: valid},
+		{name: "negative write period", read: valid, write: Limit{Requests: 10, Period: -time.Second}},
+		{name: "negative burst", read: Limit{Requests: 10, Period: time.Minute, Burst: -1}, write: valid},
+		{name: "zero policy"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			middleware, err := Middleware(NewMemoryBackend(&manualClock{now: time.Unix(0, 0)}), Policy{
+				Read:  test.read,
+				Write: test.write,
+				Key:   ByClientIP(),
+			})
+
+			if err == nil || middleware != nil {
+				t.Fatalf("expected the policy to be rejected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMiddlewareSkipsRequestsWithoutKey(t *testing.T) {
+	middleware := newMiddleware(t, NewMemoryBackend(&manualClock{now: time.Unix(0, 0)}), Policy{
+		Read:  Limit{Requests: 1, Period: time.Minute},
+		Write: Limit{Requests: 1, Period: time.Minute},
+		Key:   ByClientIP(),
+	})
+
+	for index := 0; index < 3; index++ {
+		response := middleware(newRequest(http.MethodPost, ""), ok)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected requests without key not to be limited, got %d", response.StatusCode)
+		}
+	}
+}
+
+func TestNestedMiddlewareKeepsInnerHeaders(t *testing.T) {
+	clock := &manualClock{now: time.Unix(0, 0)}
+
+	outer := newMiddleware(t, NewMemoryBackend(clock), Policy{
+		Read:  Limit{Requests: 100, Period: time.Minute},
+		Write: Limit{Requests: 100, Period: time.Minute},
+		Key:   ByClientIP(),
+	})
+
+	inner := newMiddleware(t, NewMemoryBackend(clock), Policy{
+		Read:  Limit{Requests: 5, Period: time.Minute},
+		Write: Limit{Requests: 5, Period: time.Minute},
+		Key:   ByClientIP(),
+	})
+
+	response := outer(newRequest(http.MethodPost, "10.0.0.1"), func(request *api.APIRequest) *api.APIResponse {
+		return inner(request, ok)
+	})
+
+	if response.Headers["RateLimit-Limit"] != "5" {
+		t.Fatalf("expected the inner limit, got %q", response.Headers["RateLimit-Limit"])
+	}
+}
+
+func TestKeyFunctions(t *testing.T) {
+	request := newRequest(http.MethodGet, "10.0.0.1")
+	request.MultiValueHeaders = map[string][]string{"X-Api-Key": {"secret"}}
+
+	key := FirstOf(BySubject(), ByHeader("x-api-key"), ByClientIP())(request)
+	if key == "" || key == "header:secret" || key[:7] != "header:" {
+		t.Fatalf("expected a hashed header key, got %q", key)
+	}
+
+	request.Principal = &api.Principal{Issuer: "issuer", Subject: "subject"}
+
+	key = FirstOf(BySubject(), ByHeader("x-api-key"), ByClientIP())(request)
+	if key != "subject:issuer/subject" {
+		t.Fatalf("expected the subject key, got %q", key)
+	}
+
+	if key := FirstOf(BySubject())(newRequest(http.MethodGet, "")); key != "" {
+		t.Fatalf("expected no key, got %q", key)
+	}
+}
@@ -0,0 +1,317 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+const (
+
+	// The placeholder written instead of redacted values.
+	Placeholder = "[REDACTED]"
+)
+
+// Description:
+//
+//	A redaction policy for sensitive request data.
+//	Used to sanitize requests before they are written to logs.
+type Policy struct {
+
+	// The names of headers whose values are redacted. Matched case-insensitively.
+	Headers []string
+
+	// The names of query parameters whose values are redacted. Matched case-insensitively.
+	QueryParameters []string
+
+	// The JSON body fields whose values are redacted.
+	// A name without dots, e.g. password, matches the field at any depth.
+	// A dotted path, e.g. credentials.token, matches from the document root.
+	// A * segment matches any field. Arrays are traversed transparently.
+	BodyFields []string
+
+	// The maximum length of logged bodies. Longer bodies are truncated. Zero disables the limit.
+	MaxBodyLength int
+}
+
+// Description:
+//
+//	Creates the default redaction policy.
+//	Redacts credentials and cookies, and truncates bodies after 1 KiB.
+//
+// Returns:
+//
+//	The default redaction policy.
+func DefaultPolicy() Policy {
+	return Policy{
+		Headers: []string{
+			"Authorization",
+			"Proxy-Authorization",
+			"Cookie",
+			"Set-Cookie",
+			"X-API-Key",
+		},
+		QueryParameters: []string{
+			"access_token",
+			"api_key",
+			"token",
+		},
+		BodyFields: []string{
+			"password",
+			"secret",
+			"token",
+		},
+		MaxBodyLength: 1024,
+	}
+}
+
+// Description:
+//
+//	Redacts a header map.
+//
+// Parameters:
+//
+//	headers The headers to redact. Not modified.
+//
+// Returns:
+//
+//	A copy of the headers with sensitive values redacted.
+func (policy Policy) RedactHeaders(headers map[string]string) map[string]string {
+	return redactMap(headers, policy.Headers)
+}
+
+// Description:
+//
+//	Redacts a query parameter map.
+//
+// Parameters:
+//
+//	parameters The query parameters to redact. Not modified.
+//
+// Returns:
+//
+//	A copy of the query parameters with sensitive values redacted.
+func (policy Policy) RedactQueryParameters(parameters map[string]string) map[string]string {
+	return redactMap(parameters, policy.QueryParameters)
+}
+
+// Description:
+//
+//	Redacts the query of a request URI, e.g. /albums?token=secret becomes /albums?token=[REDACTED].
+//	Query parameters are matched like in RedactQueryParameters. Their order and encoding is kept.
+//
+// Parameters:
+//
+//	uri The request URI, i.e. the path with the optional query.
+//
+// Returns:
+//
+//	The request URI with sensitive query parameter values redacted.
+func (policy Policy) RedactURI(uri string) string {
+	path, query, found := strings.Cut(uri, "?")
+	if !found || query == "" {
+		return uri
+	}
+
+	pairs := strings.Split(query, "&")
+
+	for index, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+
+		if policy.RedactQueryParameters(map[string]string{name: ""})[name] == Placeholder {
+			pairs[index] = key + "=" + Placeholder
+		}
+	}
+
+	return path + "?" + strings.Join(pairs, "&")
+}
+
+// Description:
+//
+//	Redacts a request body.
+//	JSON bodies have their sensitive fields redacted and are truncated to the maximum body length.
+//	Other bodies, including JSON bodies which fail to parse, cannot be redacted field by field,
+//	and are replaced by a placeholder stating their size and content type.
+//
+// Parameters:
+//
+//	body 		The body to redact.
+//	contentType The content type of the body. An empty content type is treated as JSON.
+//
+// Returns:
+//
+//	The redacted body.
+func (policy Policy) RedactBody(body string, contentType string) string {
+	if body == "" {
+		return body
+	}
+
+	var document interface{}
+
+	if !isJSON(contentType) || json.Unmarshal([]byte(body), &document) != nil {
+		return bodyPlaceholder(body, contentType)
+	}
+
+	if len(policy.BodyFields) > 0 {
+		for _, field := range policy.BodyFields {
+			path := strings.Split(field, ".")
+
+			if len(path) == 1 {
+				redactEverywhere(document, field)
+			} else {
+				redactPath(document, path)
+			}
+		}
+
+		bytes, err := json.Marshal(document)
+		if err != nil {
+			return bodyPlaceholder(body, contentType)
+		}
+
+		body = string(bytes)
+	}
+
+	if policy.MaxBodyLength > 0 && len(body) > policy.MaxBodyLength {
+		return fmt.Sprintf("%s... (%d bytes truncated)", body[:policy.MaxBodyLength], len(body)-policy.MaxBodyLength)
+	}
+
+	return body
+}
+
+// Description:
+//
+//	Checks whether a content type denotes JSON, e.g. application/json or application/problem+json.
+//
+// Parameters:
+//
+//	contentType The content type, possibly with parameters.
+//
+// Returns:
+//
+//	Whether the content type is empty or denotes JSON.
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// Description:
+//
+//	Creates the placeholder logged instead of a body which cannot be redacted.
+//
+// Parameters:
+//
+//	body 		The body.
+//	contentType The content type of the body.
+//
+// Returns:
+//
+//	The placeholder, e.g. <512 bytes, application/xml>.
+func bodyPlaceholder(body string, contentType string) string {
+	if contentType == "" {
+		contentType = "unknown content type"
+	}
+
+	return fmt.Sprintf("<%d bytes, %s>", len(body), contentType)
+}
+
+// Description:
+//
+//	Copies a map, redacting the values of the given keys.
+//
+// Parameters:
+//
+//	values 	The map to copy.
+//	keys 	The keys to redact, matched case-insensitively.
+//
+// Returns:
+//
+//	The redacted copy.
+func redactMap(values map[string]string, keys []string) map[string]string {
+	result := make(map[string]string, len(values))
+
+	for key, value := range values {
+		result[key] = value
+
+		for _, sensitive := range keys {
+			if strings.EqualFold(key, sensitive) {
+				result[key] = Placeholder
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// Description:
+//
+//	Redacts all fields with the given name, at any depth.
+//
+// Parameters:
+//
+//	document 	The decoded JSON document.
+//	name 		The field name.
+func redactEverywhere(document interface{}, name string) {
+	switch typed := document.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			if strings.EqualFold(key, name) {
+				typed[key] = Placeholder
+				continue
+			}
+
+			redactEverywhere(value, name)
+		}
+
+	case []interface{}:
+		for _, item := range typed {
+			redactEverywhere(item, name)
+		}
+	}
+}
+
+// Description:
+//
+//	Redacts the field at the given path.
+//
+// Parameters:
+//
+//	document 	The decoded JSON document.
+//	path 		The path segments, relative to the document.
+func redactPath(document interface{}, path []string) {
+	switch typed := document.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			if path[0] != "*" && !strings.EqualFold(key, path[0]) {
+				continue
+			}
+
+			if len(path) == 1 {
+				typed[key] = Placeholder
+				continue
+			}
+
+			redactPath(value, path[1:])
+		}
+
+	case []interface{}:
+		for _, item := range typed {
+			redactPath(item, path)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.BodyFields = append(policy.BodyFields, "credentials.key")
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		expected    string
+	}{
+		{"empty", "", "application/json", ""},
+		{"json field", `{"name":"a","password":"p"}`, "application/json", `{"name":"a","password":"[REDACTED]"}`},
+		{"json nested field", `{"users":[{"token":"t"}]}`, "application/json; charset=utf-8", `{"users":[{"token":"[REDACTED]"}]}`},
+		{"json path", `{"credentials":{"key":"k"},"key":"kept"}`, "application/merge-patch+json", `{"credentials":{"key":"[REDACTED]"},"key":"kept"}`},
+		{"json without content type", `{"secret":"s"}`, "", `{"secret":"[REDACTED]"}`},
+		{"invalid json", `{"password":"p"`, "application/json", "<15 bytes, application/json>"},
+		{"xml", "<user><password>p</password></user>", "application/xml", "<35 bytes, application/xml>"},
+		{"binary", "\x00\x01\x02", "application/octet-stream", "<3 bytes, application/octet-stream>"},
+		{"text without content type", "password=p", "", "<10 bytes, unknown content type>"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			redacted := policy.RedactBody(test.body, test.contentType)
+			if redacted != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, redacted)
+			}
+		})
+	}
+}
+
+func TestRedactBodyTruncates(t *testing.T) {
+	policy := Policy{MaxBodyLength: 8}
+
+	redacted := policy.RedactBody(`["abcdefghij"]`, "application/json")
+	if redacted != `["abcdef... (6 bytes truncated)` {
+		t.Fatalf("unexpected truncated body %q", redacted)
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	headers := map[string]string{"authorization": "Bearer token", "Accept": "application/json"}
+
+	redacted := DefaultPolicy().RedactHeaders(headers)
+	if redacted["authorization"] != Placeholder || redacted["Accept"] != "application/json" {
+		t.Fatalf("unexpected redacted headers %+v", redacted)
+	}
+
+	if !strings.HasPrefix(headers["authorization"], "Bearer") {
+		t.Fatalf("expected the headers not to be modified")
+	}
+}
+
+func TestRedactURI(t *testing.T) {
+	tests := []struct {
+		uri      string
+		expected string
+	}{
+		{"/albums", "/albums"},
+		{"/albums?", "/albums?"},
+		{"/albums?limit=5", "/albums?limit=5"},
+		{"/albums?token=secret", "/albums?token=[REDACTED]"},
+		{"/albums?limit=5&Access_Token=a&token", "/albums?limit=5&Access_Token=[REDACTED]&token=[REDACTED]"},
+		{"/albums?api%5Fkey=k&name=a%20b", "/albums?api%5Fkey=[REDACTED]&name=a%20b"},
+	}
+
+	for _, test := range tests {
+		redacted := DefaultPolicy().RedactURI(test.uri)
+		if redacted != test.expected {
+			t.Fatalf("expected %q, got %q", test.expected, redacted)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package router
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/redact"
+)
+
+// Description:
+//
+//	The format of access log entries.
+type AccessLogFormat = string
+
+const (
+
+	// The Common Log Format, followed by the route, the latency in seconds and the request id.
+	AccessLogFormatCommon AccessLogFormat = "common"
+
+	// The Combined Log Format, followed by the route, the latency in seconds and the request id.
+	AccessLogFormatCombined AccessLogFormat = "combined"
+
+	// Structured entries, written via the logging facade with the "access" logger.
+	AccessLogFormatJSON AccessLogFormat = "json"
+)
+
+// Description:
+//
+//	Creates an observer which writes an access log entry per request.
+//	Sensitive query parameters of the logged URL are redacted.
+//
+// Parameters:
+//
+//	format 	The access log format.
+//	output 	The output for the common and combined formats. Unused for the json format.
+//	policy 	The redaction policy of the router.
+//
+// Returns:
+//
+//	The access log observer, or an error if the format is unknown.
+func AccessLog(format AccessLogFormat, output io.Writer, policy redact.Policy) (Observer, error) {
+	switch format {
+	case AccessLogFormatJSON:
+		accessLogger := logging.Named("access")
+
+		return func(exchange *Exchange) {
+			accessLogger.Log(logging.LevelInfo, "request",
+				logging.F("requestId", exchange.RequestID),
+				logging.F("method", exchange.Method),
+				logging.F("route", exchange.Route),
+				logging.F("path", exchange.Path),
+				logging.F("status", exchange.Status),
+				logging.F("bytes", exchange.Bytes),
+				logging.F("latencyMs", float64(exchange.Latency.Microseconds())/1000),
+				logging.F("clientIp", exchange.ClientIP),
+				logging.F("userAgent", exchange.UserAgent),
+			)
+		}, nil
+
+	case AccessLogFormatCommon, AccessLogFormatCombined:
+		var mutex sync.Mutex
+
+		return func(exchange *Exchange) {
+			line := formatCommonLogLine(exchange, policy.RedactURI(exchange.URL), format == AccessLogFormatCombined)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			io.WriteString(output, line)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("router: unknown access log format: %s", format)
+}
+
+// Description:
+//
+//	Formats an exchange in the Common or Combined Log Format.
+//	The route, latency and request id are appended to the standard fields.
+//
+// Parameters:
+//
+//	exchange 	The exchange to format.
+//	uri 		The redacted request URI.
+//	combined 	Whether to use the Combined Log Format.
+//
+// Returns:
+//
+//	The formatted line, including the trailing newline.
+func formatCommonLogLine(exchange *Exchange, uri string, combined bool) string {
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "%s - - [%s] \"%s %s %s\" %d %s",
+		orDash(exchange.ClientIP),
+		exchange.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		exchange.Method,
+		uri,
+		exchange.Protocol,
+		exchange.Status,
+		bytesOrDash(exchange.Bytes),
+	)
+
+	if combined {
+		fmt.Fprintf(builder, " %q %q", orDash(exchange.Referer), orDash(exchange.UserAgent))
+	}
+
+	fmt.Fprintf(builder, " %q %.6f %s\n", orDash(exchange.Route), exchange.Latency.Seconds(), exchange.RequestID)
+	return builder.String()
+}
+
+// Description:
+//
+//	Replaces empty values with a dash, as used by the Common Log Format.
+//
+// Parameters:
+//
+//	value The value.
+//
+// Returns:
+//
+//	The value, or a dash if it is empty.
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
+
+// Description:
+//
+//	Formats a byte count, as used by the Common Log Format.
+//
+// Parameters:
+//
+//	bytes The byte count.
+//
+// Returns:
+//
+//	The byte count, or a dash if it is zero.
+func bytesOrDash(bytes int) string {
+	if bytes == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%d", bytes)
+}
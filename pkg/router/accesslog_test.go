@@ -0,0 +1,51 @@
+package router
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/redact"
+)
+
+func TestAccessLogRedactsQueryParameters(t *testing.T) {
+	for _, format := range []AccessLogFormat{AccessLogFormatCommon, AccessLogFormatCombined} {
+		output := &bytes.Buffer{}
+
+		observer, err := AccessLog(format, output, redact.DefaultPolicy())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		observer(&Exchange{
+			Time:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			RequestID: "request",
+			Method:    "GET",
+			Route:     "/albums",
+			Path:      "/albums",
+			URL:       "/albums?limit=5&token=secret&API_KEY=key",
+			Protocol:  "HTTP/1.1",
+			Status:    200,
+			Bytes:     12,
+			ClientIP:  "192.0.2.1",
+		})
+
+		line := output.String()
+		if strings.Contains(line, "secret") || strings.Contains(line, "=key") {
+			t.Fatalf("expected query secrets to be redacted, got %s", line)
+		}
+
+		expected := `192.0.2.1 - - [02/Jan/2024:03:04:05 +0000] "GET /albums?limit=5&token=[REDACTED]&API_KEY=[REDACTED] HTTP/1.1" 200 12`
+		if !strings.HasPrefix(line, expected) {
+			t.Fatalf("expected %s, got %s", expected, line)
+		}
+	}
+}
+
+func TestAccessLogRejectsUnknownFormats(t *testing.T) {
+	_, err := AccessLog("apache", &bytes.Buffer{}, redact.DefaultPolicy())
+	if err == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+}
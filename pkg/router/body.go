@@ -0,0 +1,197 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gostream-official/albums/pkg/api"
+)
+
+// Description:
+//
+//	An error caused by a malformed request, answered with the given status code.
+type requestError struct {
+
+	// The status code of the error response.
+	statusCode int
+
+	// The error message.
+	message string
+}
+
+// Description:
+//
+//	A reader, which fails with api.ErrBodyTooLarge once more than the given number of bytes are read.
+type limitedReader struct {
+
+	// The underlying reader.
+	reader io.ReadCloser
+
+	// The number of bytes, which can still be read.
+	remaining int64
+}
+
+// Description:
+//
+//	Creates a request error.
+//
+// Parameters:
+//
+//	statusCode 	The status code of the error response.
+//	message 	The error message.
+//
+// Returns:
+//
+//	The created error.
+func newRequestError(statusCode int, message string) *requestError {
+	return &requestError{
+		statusCode: statusCode,
+		message:    message,
+	}
+}
+
+// Description:
+//
+//	Gets the error message.
+//
+// Returns:
+//
+//	The error message.
+func (err *requestError) Error() string {
+	return err.message
+}
+
+// Description:
+//
+//	Reads from the underlying reader.
+//
+// Parameters:
+//
+//	data The buffer to read into.
+//
+// Returns:
+//
+//	The number of read bytes, or an error if reading fails or the limit is exceeded.
+func (reader *limitedReader) Read(data []byte) (int, error) {
+	if reader.remaining < 0 {
+		return 0, api.ErrBodyTooLarge
+	}
+
+	// Reads one byte beyond the limit, to tell exhausted bodies from oversized ones.
+	if int64(len(data)) > reader.remaining+1 {
+		data = data[:reader.remaining+1]
+	}
+
+	count, err := reader.reader.Read(data)
+	reader.remaining -= int64(count)
+
+	if reader.remaining < 0 {
+		return count + int(reader.remaining), api.ErrBodyTooLarge
+	}
+
+	return count, err
+}
+
+// Description:
+//
+//	Closes the underlying reader.
+//
+// Returns:
+//
+//	An error if closing fails.
+func (reader *limitedReader) Close() error {
+	return reader.reader.Close()
+}
+
+// Description:
+//
+//	Gets the maximum size of request bodies of the given route.
+//
+// Parameters:
+//
+//	route The route.
+//
+// Returns:
+//
+//	The maximum body size in bytes, or zero if the body is not limited.
+func (router *GinRouter) bodyLimit(route *Route) int64 {
+	limit := route.bodyLimit
+	if limit == 0 {
+		limit = router.config.MaxBodySize
+	}
+
+	if limit < 0 {
+		return 0
+	}
+
+	return limit
+}
+
+// Description:
+//
+//	Opens the request body, decoding it according to its Content-Encoding header.
+//	Both the received and the decoded body are limited to the given size.
+//
+// Parameters:
+//
+//	request 		The request.
+//	limit 			The maximum body size in bytes, or zero if the body is not limited.
+//	maxDecodedSize 	The maximum size of a decoded body in bytes.
+//
+// Returns:
+//
+//	The body, or an error if the body is too large or its encoding is not supported.
+func openBody(request *http.Request, limit int64, maxDecodedSize int64) (io.ReadCloser, error) {
+	if limit > 0 && request.ContentLength > limit {
+		return nil, newRequestError(http.StatusRequestEntityTooLarge, "request body too large")
+	}
+
+	var body io.ReadCloser = request.Body
+	if limit > 0 {
+		body = &limitedReader{reader: body, remaining: limit}
+	}
+
+	encoding := request.Header.Get("Content-Encoding")
+
+	decoded, err := decodeBody(body, encoding, maxDecodedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && decoded != body {
+		decoded = &limitedReader{reader: decoded, remaining: limit}
+	}
+
+	return decoded, nil
+}
+
+// Description:
+//
+//	Reads an opened request body entirely.
+//
+// Parameters:
+//
+//	body 		The opened body.
+//	encoding 	The Content-Encoding header of the request.
+//
+// Returns:
+//
+//	The body, or an error if reading or decoding fails.
+func readBody(body io.Reader, encoding string) ([]byte, error) {
+	data, err := io.ReadAll(body)
+	if err == nil {
+		return data, nil
+	}
+
+	if errors.Is(err, api.ErrBodyTooLarge) {
+		return nil, newRequestError(http.StatusRequestEntityTooLarge, "request body too large")
+	}
+
+	var requestErr *requestError
+	if errors.As(err, &requestErr) || encoding == "" {
+		return nil, err
+	}
+
+	return nil, newRequestError(http.StatusBadRequest, "malformed request body: "+err.Error())
+}
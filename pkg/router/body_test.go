@@ -0,0 +1,189 @@
+package router
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/klauspost/compress/gzip"
+)
+
+// Description:
+//
+//	Creates a router with a body limit of 16 bytes,
+//	serving POST /read reading the body upfront and POST /stream streaming it.
+//
+// Returns:
+//
+//	The router.
+func newBodyLimitRouter() Router {
+	config := DefaultConfig()
+	config.MaxBodySize = 16
+
+	engine := New(config)
+
+	engine.Handle(http.MethodPost, "/read", func(request *api.APIRequest) *api.APIResponse {
+		return api.Text(http.StatusOK, request.Body)
+	})
+
+	engine.Handle(http.MethodPost, "/stream", func(request *api.APIRequest) *api.APIResponse {
+		data, err := io.ReadAll(request.BodyReader)
+		if errors.Is(err, api.ErrBodyTooLarge) {
+			return api.Text(http.StatusRequestEntityTooLarge, err.Error())
+		}
+
+		return api.Text(http.StatusOK, string(data))
+	}).StreamBody()
+
+	return engine
+}
+
+func TestOverLimitBodiesAreRejected(t *testing.T) {
+	engine := newBodyLimitRouter()
+
+	tests := []struct {
+		name          string
+		path          string
+		body          string
+		contentLength bool
+		status        int
+	}{
+		{name: "within limit", path: "/read", body: `"0123456789"`, contentLength: true, status: http.StatusOK},
+		{name: "within limit without content length", path: "/read", body: `"0123456789"`, status: http.StatusOK},
+		{name: "over limit", path: "/read", body: `"0123456789abcdef"`, contentLength: true, status: http.StatusRequestEntityTooLarge},
+		{name: "over limit without content length", path: "/read", body: `"0123456789abcdef"`, status: http.StatusRequestEntityTooLarge},
+		{name: "streamed within limit", path: "/stream", body: `"0123456789"`, contentLength: true, status: http.StatusOK},
+		{name: "streamed over limit", path: "/stream", body: `"0123456789abcdef"`, contentLength: true, status: http.StatusRequestEntityTooLarge},
+		{name: "streamed over limit without content length", path: "/stream", body: `"0123456789abcdef"`, status: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var body io.Reader = strings.NewReader(test.body)
+			if !test.contentLength {
+				// Hides the length of the body, as for chunked requests.
+				body = io.NopCloser(body)
+			}
+
+			request := httptest.NewRequest(http.MethodPost, test.path, body)
+			request.Header.Set("Content-Type", "application/json")
+
+			if test.contentLength != (request.ContentLength >= 0) {
+				t.Fatalf("unexpected content length %d", request.ContentLength)
+			}
+
+			recorder := httptest.NewRecorder()
+			engine.Handler().ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Fatalf("expected status %d, got %d: %s", test.status, recorder.Code, recorder.Body.String())
+			}
+
+			if test.status == http.StatusOK && recorder.Body.String() != test.body {
+				t.Errorf("expected body %q, got %q", test.body, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestOverLimitBodiesFailWithRequestID(t *testing.T) {
+	engine := newBodyLimitRouter()
+
+	recorder := send(engine, http.MethodPost, "/read", `"0123456789abcdef"`, map[string]string{
+		"Content-Type":  "application/json",
+		HeaderRequestID: "limit-1",
+	})
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413, got %d", recorder.Code)
+	}
+
+	if body := errorBody(t, recorder); body.RequestID != "limit-1" {
+		t.Errorf("expected request id limit-1, got %q", body.RequestID)
+	}
+}
+
+func TestStreamedResponsesAreFlushedIncrementally(t *testing.T) {
+	for _, encoding := range []string{EncodingIdentity, EncodingGzip} {
+		t.Run(encoding, func(t *testing.T) {
+			reader, writer := io.Pipe()
+			defer writer.Close()
+
+			engine := New(DefaultConfig())
+			engine.Handle(http.MethodGet, "/events", func(request *api.APIRequest) *api.APIResponse {
+				return api.Stream(http.StatusOK, "text/plain", reader, -1)
+			})
+
+			server := httptest.NewServer(engine.Handler())
+			defer server.Close()
+
+			// The response starts with the first line, while the handler is still streaming.
+			go writer.Write([]byte("first\n"))
+
+			request, _ := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+			request.Header.Set("Accept-Encoding", encoding)
+
+			// Responses buffered until the handler completes never arrive.
+			client := &http.Client{Timeout: 5 * time.Second}
+
+			response, err := client.Do(request)
+			if err != nil {
+				writer.Close()
+				t.Fatalf("request failed: %s", err)
+			}
+
+			defer response.Body.Close()
+
+			if response.ContentLength != -1 {
+				t.Errorf("expected no content length, got %d", response.ContentLength)
+			}
+
+			var body io.Reader = response.Body
+
+			if encoding == EncodingGzip {
+				if response.Header.Get("Content-Encoding") != EncodingGzip {
+					t.Fatalf("expected a gzip encoded stream, got %q", response.Header.Get("Content-Encoding"))
+				}
+
+				body, err = gzip.NewReader(body)
+				if err != nil {
+					t.Fatalf("failed to decompress the stream: %s", err)
+				}
+			}
+
+			lines := make(chan string)
+
+			go func() {
+				defer close(lines)
+
+				scanner := bufio.NewScanner(body)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}()
+
+			select {
+			case line := <-lines:
+				if line != "first" {
+					t.Fatalf("expected the first line, got %q", line)
+				}
+			case <-time.After(5 * time.Second):
+				writer.Close()
+				t.Fatal("the first line was not flushed before the body was complete")
+			}
+
+			writer.Write([]byte("second\n"))
+			writer.Close()
+
+			if line := <-lines; line != "second" {
+				t.Errorf("expected the second line, got %q", line)
+			}
+		})
+	}
+}
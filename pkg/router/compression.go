@@ -0,0 +1,566 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+
+	// The gzip content encoding.
+	EncodingGzip = "gzip"
+
+	// The deflate content encoding, i.e. zlib framed deflate data.
+	EncodingDeflate = "deflate"
+
+	// The zstd content encoding.
+	EncodingZstd = "zstd"
+
+	// The identity content encoding, i.e. no compression.
+	EncodingIdentity = "identity"
+)
+
+// Description:
+//
+//	The compression configuration of a router.
+type CompressionConfig struct {
+
+	// The response encodings, in order of preference, e.g. zstd, gzip and deflate.
+	// Negotiated via the Accept-Encoding header. If empty, responses are not compressed.
+	Encodings []string
+
+	// The minimum size of a response body in bytes to be compressed.
+	MinSize int
+
+	// The maximum size of a decompressed request body in bytes.
+	// Compressed request bodies exceeding this size are rejected with 413.
+	// If zero, compressed request bodies are rejected with 415.
+	MaxDecompressedSize int64
+}
+
+// Description:
+//
+//	A response writer which compresses the response body,
+//	once the body exceeds the minimum size or is flushed.
+type compressWriter struct {
+
+	// The wrapped response writer.
+	gin.ResponseWriter
+
+	// The negotiated content encoding.
+	encoding string
+
+	// The minimum size of a response body to be compressed.
+	minSize int
+
+	// Whether the request is a HEAD request, which is never compressed.
+	head bool
+
+	// The buffered body, until it is decided whether to compress.
+	buffer bytes.Buffer
+
+	// Whether it was decided whether to compress.
+	decided bool
+
+	// The encoder, or nil if the body is not compressed.
+	encoder *pooledEncoder
+}
+
+// Description:
+//
+//	A pooled encoder, which can be reset to write to another writer.
+type resettableEncoder interface {
+	io.WriteCloser
+
+	// Description:
+	//
+	//	Resets the encoder to write to the given writer.
+	//
+	// Parameters:
+	//
+	//	writer The writer.
+	Reset(writer io.Writer)
+
+	// Description:
+	//
+	//	Flushes pending data to the underlying writer.
+	//
+	// Returns:
+	//
+	//	An error if flushing fails.
+	Flush() error
+}
+
+// Description:
+//
+//	An encoder which returns itself to its pool when closed.
+type pooledEncoder struct {
+	resettableEncoder
+
+	// The pool of the encoder.
+	pool *sync.Pool
+}
+
+// Description:
+//
+//	A zstd decoded request body.
+//	Fails with api.ErrBodyTooLarge if the decoder memory limit is exceeded.
+type zstdBody struct {
+	io.ReadCloser
+}
+
+// Description:
+//
+//	The media type prefixes of response bodies, which are already compressed.
+var incompressibleMediaTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"application/zip",
+	"application/gzip",
+	"application/zstd",
+	"application/x-7z-compressed",
+}
+
+// Description:
+//
+//	The encoder pools, by content encoding.
+var encoderPools = map[string]*sync.Pool{
+	EncodingGzip: {New: func() interface{} {
+		return gzip.NewWriter(nil)
+	}},
+	EncodingDeflate: {New: func() interface{} {
+		return zlib.NewWriter(nil)
+	}},
+	EncodingZstd: {New: func() interface{} {
+		encoder, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return encoder
+	}},
+}
+
+// Description:
+//
+//	Creates the default compression configuration.
+//
+// Returns:
+//
+//	The default compression configuration.
+func DefaultCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		Encodings:           []string{EncodingZstd, EncodingGzip, EncodingDeflate},
+		MinSize:             1024,
+		MaxDecompressedSize: 10 << 20,
+	}
+}
+
+// Description:
+//
+//	Middleware compressing response bodies with the encoding negotiated via the Accept-Encoding header.
+//	Triggered by the gin framework.
+//
+// Parameters:
+//
+//	context The gin context.
+func (router *GinRouter) compress(context *gin.Context) {
+	encodings := router.config.Compression.Encodings
+	if len(encodings) == 0 {
+		context.Next()
+		return
+	}
+
+	// Responses vary by Accept-Encoding, even if this one is not compressed.
+	context.Writer.Header().Add("Vary", "Accept-Encoding")
+
+	encoding := negotiateEncoding(context.GetHeader("Accept-Encoding"), encodings)
+	if encoding == "" {
+		context.Next()
+		return
+	}
+
+	writer := &compressWriter{
+		ResponseWriter: context.Writer,
+		encoding:       encoding,
+		minSize:        router.config.Compression.MinSize,
+		head:           context.Request.Method == http.MethodHead,
+	}
+
+	context.Writer = writer
+	defer func() {
+		context.Writer = writer.ResponseWriter
+	}()
+
+	context.Next()
+
+	err := writer.finish()
+	if err != nil {
+		logger.Warnf("failed to complete compressed response: %s", err)
+	}
+}
+
+// Description:
+//
+//	Selects the response encoding for the given Accept-Encoding header.
+//	Encodings are ranked by quality, equal qualities by the order of supported encodings.
+//
+// Parameters:
+//
+//	acceptEncoding 	The Accept-Encoding header, e.g. gzip;q=0.8, zstd.
+//	supported 		The supported encodings, in order of preference.
+//
+// Returns:
+//
+//	The selected encoding, or an empty string if the response is not compressed.
+func negotiateEncoding(acceptEncoding string, supported []string) string {
+	if strings.TrimSpace(acceptEncoding) == "" || len(supported) == 0 {
+		return ""
+	}
+
+	qualities := make(map[string]float64)
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, parameters, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		quality := 1.0
+
+		if value, ok := strings.CutPrefix(strings.TrimSpace(parameters), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err == nil {
+				quality = parsed
+			}
+		}
+
+		qualities[name] = quality
+	}
+
+	candidates := make([]string, 0)
+
+	for _, encoding := range supported {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+
+		if ok && quality > 0 {
+			candidates = append(candidates, encoding)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return encodingQuality(qualities, candidates[i]) > encodingQuality(qualities, candidates[j])
+	})
+
+	return candidates[0]
+}
+
+// Description:
+//
+//	Gets the quality of an encoding, falling back to the wildcard quality.
+//
+// Parameters:
+//
+//	qualities 	The qualities of the Accept-Encoding header.
+//	encoding 	The encoding.
+//
+// Returns:
+//
+//	The quality.
+func encodingQuality(qualities map[string]float64, encoding string) float64 {
+	quality, ok := qualities[encoding]
+	if !ok {
+		return qualities["*"]
+	}
+
+	return quality
+}
+
+// Description:
+//
+//	Writes response body bytes.
+//	Buffers the bytes until the minimum size is reached.
+//
+// Parameters:
+//
+//	data The bytes to write.
+//
+// Returns:
+//
+//	The number of written bytes, or an error if writing fails.
+func (writer *compressWriter) Write(data []byte) (int, error) {
+	if writer.decided {
+		if writer.encoder != nil {
+			return writer.encoder.Write(data)
+		}
+
+		return writer.ResponseWriter.Write(data)
+	}
+
+	writer.buffer.Write(data)
+
+	if writer.buffer.Len() >= writer.minSize {
+		err := writer.decide(true)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(data), nil
+}
+
+// Description:
+//
+//	Writes a response body string.
+//
+// Parameters:
+//
+//	data The string to write.
+//
+// Returns:
+//
+//	The number of written bytes, or an error if writing fails.
+func (writer *compressWriter) WriteString(data string) (int, error) {
+	return writer.Write([]byte(data))
+}
+
+// Description:
+//
+//	Checks whether body bytes were written, including buffered ones.
+//
+// Returns:
+//
+//	Whether body bytes were written.
+func (writer *compressWriter) Written() bool {
+	return writer.buffer.Len() > 0 || writer.ResponseWriter.Written()
+}
+
+// Description:
+//
+//	Flushes buffered data to the client.
+//	Compresses streamed responses regardless of their size.
+func (writer *compressWriter) Flush() {
+	if !writer.decided {
+		err := writer.decide(true)
+		if err != nil {
+			logger.Warnf("failed to flush compressed response: %s", err)
+			return
+		}
+	}
+
+	if writer.encoder != nil {
+		writer.encoder.Flush()
+	}
+
+	writer.ResponseWriter.Flush()
+}
+
+// Description:
+//
+//	Decides whether to compress the response and writes the buffered body.
+//
+// Parameters:
+//
+//	compress Whether the body is large enough to be compressed.
+//
+// Returns:
+//
+//	An error if writing the buffered body fails.
+func (writer *compressWriter) decide(compress bool) error {
+	writer.decided = true
+
+	if compress && writer.compressible() {
+		header := writer.Header()
+		header.Set("Content-Encoding", writer.encoding)
+		header.Del("Content-Length")
+
+		pool := encoderPools[writer.encoding]
+		encoder := pool.Get().(resettableEncoder)
+		encoder.Reset(writer.ResponseWriter)
+
+		writer.encoder = &pooledEncoder{resettableEncoder: encoder, pool: pool}
+	}
+
+	if writer.buffer.Len() == 0 {
+		return nil
+	}
+
+	var err error
+
+	if writer.encoder != nil {
+		_, err = writer.encoder.Write(writer.buffer.Bytes())
+	} else {
+		_, err = writer.ResponseWriter.Write(writer.buffer.Bytes())
+	}
+
+	writer.buffer.Reset()
+	return err
+}
+
+// Description:
+//
+//	Checks whether the response can be compressed.
+//	Partial, empty, already encoded and already compressed responses are not.
+//
+// Returns:
+//
+//	Whether the response can be compressed.
+func (writer *compressWriter) compressible() bool {
+	if writer.head {
+		return false
+	}
+
+	switch writer.Status() {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+
+	header := writer.Header()
+
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+
+	for _, prefix := range incompressibleMediaTypes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Description:
+//
+//	Completes the response.
+//	Writes bodies below the minimum size uncompressed and closes the encoder.
+//
+// Returns:
+//
+//	An error if writing the response fails.
+func (writer *compressWriter) finish() error {
+	if !writer.decided {
+		return writer.decide(false)
+	}
+
+	if writer.encoder == nil {
+		return nil
+	}
+
+	return writer.encoder.Close()
+}
+
+// Description:
+//
+//	Closes the encoder and returns it to its pool.
+//
+// Returns:
+//
+//	An error if closing the encoder fails.
+func (encoder *pooledEncoder) Close() error {
+	err := encoder.resettableEncoder.Close()
+
+	encoder.resettableEncoder.Reset(nil)
+	encoder.pool.Put(encoder.resettableEncoder)
+
+	return err
+}
+
+// Description:
+//
+//	Wraps a request body in a decoder for the given content encoding.
+//	The decoded body is limited to the given size.
+//
+// Parameters:
+//
+//	body 		The request body.
+//	encoding 	The Content-Encoding header.
+//	maxSize 	The maximum size of the decoded body in bytes.
+//
+// Returns:
+//
+//	The decoded body, or the body itself if it is not encoded, or an error if the encoding is not supported or the body is malformed.
+func decodeBody(body io.ReadCloser, encoding string, maxSize int64) (io.ReadCloser, error) {
+	var decoded io.ReadCloser
+
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+	if encoding == "" || encoding == EncodingIdentity {
+		return body, nil
+	}
+
+	if maxSize <= 0 {
+		return nil, newRequestError(http.StatusUnsupportedMediaType, "compressed request bodies are not accepted")
+	}
+
+	switch encoding {
+	case EncodingGzip, "x-gzip":
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, newRequestError(http.StatusBadRequest, "malformed gzip request body")
+		}
+
+		decoded = reader
+
+	case EncodingDeflate:
+		reader, err := zlib.NewReader(body)
+		if err != nil {
+			return nil, newRequestError(http.StatusBadRequest, "malformed deflate request body")
+		}
+
+		decoded = reader
+
+	case EncodingZstd:
+		reader, err := zstd.NewReader(body, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(maxSize)))
+		if err != nil {
+			return nil, newRequestError(http.StatusBadRequest, "malformed zstd request body")
+		}
+
+		decoded = &zstdBody{ReadCloser: reader.IOReadCloser()}
+
+	default:
+		return nil, newRequestError(http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported content encoding: %s", encoding))
+	}
+
+	return &limitedReader{reader: decoded, remaining: maxSize}, nil
+}
+
+// Description:
+//
+//	Reads decoded body bytes.
+//	Frames requiring a window larger than the decoder memory limit cannot be decoded within the
+//	maximum decompressed size, so they are reported as too large rather than as malformed.
+//
+// Parameters:
+//
+//	data The buffer to read into.
+//
+// Returns:
+//
+//	The number of read bytes, or an error if decoding fails.
+func (body *zstdBody) Read(data []byte) (int, error) {
+	count, err := body.ReadCloser.Read(data)
+
+	if errors.Is(err, zstd.ErrWindowSizeExceeded) || errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrFrameSizeExceeded) {
+		return count, api.ErrBodyTooLarge
+	}
+
+	return count, err
+}
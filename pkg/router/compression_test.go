@@ -0,0 +1,277 @@
+package router
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Description:
+//
+//	Compresses data with the given content encoding.
+//
+// Parameters:
+//
+//	t 			The test.
+//	encoding 	The content encoding.
+//	data 		The data to compress.
+//
+// Returns:
+//
+//	The compressed data.
+func compressData(t *testing.T, encoding string, data []byte) []byte {
+	buffer := &bytes.Buffer{}
+
+	var writer io.WriteCloser
+
+	switch encoding {
+	case EncodingGzip:
+		writer = gzip.NewWriter(buffer)
+	case EncodingDeflate:
+		writer = zlib.NewWriter(buffer)
+	case EncodingZstd:
+		writer, _ = zstd.NewWriter(buffer)
+	default:
+		t.Fatalf("unsupported encoding %s", encoding)
+	}
+
+	writer.Write(data)
+	writer.Close()
+
+	return buffer.Bytes()
+}
+
+// Description:
+//
+//	Decompresses data with the given content encoding.
+//
+// Parameters:
+//
+//	t 			The test.
+//	encoding 	The content encoding.
+//	data 		The data to decompress.
+//
+// Returns:
+//
+//	The decompressed data.
+func decompressData(t *testing.T, encoding string, data []byte) []byte {
+	var reader io.Reader
+	var err error
+
+	switch encoding {
+	case EncodingGzip:
+		reader, err = gzip.NewReader(bytes.NewReader(data))
+	case EncodingDeflate:
+		reader, err = zlib.NewReader(bytes.NewReader(data))
+	case EncodingZstd:
+		reader, err = zstd.NewReader(bytes.NewReader(data))
+	}
+
+	if err != nil {
+		t.Fatalf("failed to decompress %s: %s", encoding, err)
+	}
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to decompress %s: %s", encoding, err)
+	}
+
+	return decompressed
+}
+
+// Description:
+//
+//	Creates a router serving a raw body of the given size on /data and echoing request bodies on /echo.
+//
+// Parameters:
+//
+//	config 	The router configuration.
+//	size 	The size of the served body.
+//
+// Returns:
+//
+//	The router.
+func newCompressionRouter(config Config, size int) Router {
+	engine := New(config)
+
+	engine.Handle(http.MethodGet, "/data", func(request *api.APIRequest) *api.APIResponse {
+		return api.Raw(http.StatusOK, "text/plain", []byte(strings.Repeat("a", size)))
+	})
+
+	engine.Handle(http.MethodPost, "/echo", func(request *api.APIRequest) *api.APIResponse {
+		return api.Raw(http.StatusOK, "text/plain", []byte(request.Body))
+	})
+
+	return engine
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{EncodingZstd, EncodingGzip, EncodingDeflate}
+
+	tests := []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{"", ""},
+		{"br", ""},
+		{"gzip", EncodingGzip},
+		{"GZIP", EncodingGzip},
+		{"gzip, deflate", EncodingGzip},
+		{"deflate, zstd", EncodingZstd},
+		{"gzip;q=0.5, deflate;q=0.8", EncodingDeflate},
+		{"gzip;q=0, deflate;q=0", ""},
+		{"*", EncodingZstd},
+		{"*;q=0.5, gzip", EncodingGzip},
+		{"zstd;q=0, *", EncodingGzip},
+		{"*;q=0", ""},
+		{"identity;q=0", ""},
+		{"identity;q=0, *", EncodingZstd},
+		{"identity, gzip;q=0.1", EncodingGzip},
+	}
+
+	for _, test := range tests {
+		if encoding := negotiateEncoding(test.acceptEncoding, supported); encoding != test.expected {
+			t.Fatalf("expected %q for %q, got %q", test.expected, test.acceptEncoding, encoding)
+		}
+	}
+}
+
+func TestResponsesAreCompressed(t *testing.T) {
+	engine := newCompressionRouter(DefaultConfig(), 4096)
+
+	for _, encoding := range []string{EncodingZstd, EncodingGzip, EncodingDeflate} {
+		recorder := send(engine, http.MethodGet, "/data", "", map[string]string{"Accept-Encoding": encoding})
+
+		if recorder.Header().Get("Content-Encoding") != encoding {
+			t.Fatalf("expected %s, got %q", encoding, recorder.Header().Get("Content-Encoding"))
+		}
+
+		if !strings.Contains(recorder.Header().Get("Vary"), "Accept-Encoding") {
+			t.Fatalf("expected Vary: Accept-Encoding, got %q", recorder.Header().Get("Vary"))
+		}
+
+		if recorder.Body.Len() >= 4096 || string(decompressData(t, encoding, recorder.Body.Bytes())) != strings.Repeat("a", 4096) {
+			t.Fatalf("expected the compressed body for %s", encoding)
+		}
+	}
+}
+
+func TestSmallResponsesAreNotCompressed(t *testing.T) {
+	config := DefaultConfig()
+	config.Compression.MinSize = 100
+
+	engine := newCompressionRouter(config, 99)
+
+	recorder := send(engine, http.MethodGet, "/data", "", map[string]string{"Accept-Encoding": "gzip"})
+	if recorder.Header().Get("Content-Encoding") != "" || recorder.Body.String() != strings.Repeat("a", 99) {
+		t.Fatalf("expected the uncompressed body, got %q encoded as %q", recorder.Body.String(), recorder.Header().Get("Content-Encoding"))
+	}
+
+	if !strings.Contains(recorder.Header().Get("Vary"), "Accept-Encoding") {
+		t.Fatalf("expected Vary: Accept-Encoding on uncompressed responses, got %q", recorder.Header().Get("Vary"))
+	}
+}
+
+func TestEncodedResponsesAreNotCompressedAgain(t *testing.T) {
+	compressed := compressData(t, EncodingGzip, []byte(strings.Repeat("b", 4096)))
+	image := bytes.Repeat([]byte{0x89}, 4096)
+
+	engine := New(DefaultConfig())
+
+	engine.Handle(http.MethodGet, "/encoded", func(request *api.APIRequest) *api.APIResponse {
+		response := api.Raw(http.StatusOK, "text/plain", compressed)
+		response.Headers = map[string]string{"Content-Encoding": EncodingGzip}
+
+		return response
+	})
+
+	engine.Handle(http.MethodGet, "/image", func(request *api.APIRequest) *api.APIResponse {
+		return api.Raw(http.StatusOK, "image/png", image)
+	})
+
+	recorder := send(engine, http.MethodGet, "/encoded", "", map[string]string{"Accept-Encoding": "zstd, gzip"})
+	if recorder.Header().Get("Content-Encoding") != EncodingGzip || !bytes.Equal(recorder.Body.Bytes(), compressed) {
+		t.Fatalf("expected the encoded body to be passed through, got %q", recorder.Header().Get("Content-Encoding"))
+	}
+
+	recorder = send(engine, http.MethodGet, "/image", "", map[string]string{"Accept-Encoding": "gzip"})
+	if recorder.Header().Get("Content-Encoding") != "" || !bytes.Equal(recorder.Body.Bytes(), image) {
+		t.Fatalf("expected images not to be compressed, got %q", recorder.Header().Get("Content-Encoding"))
+	}
+}
+
+func TestCompressedRequestBodies(t *testing.T) {
+	config := DefaultConfig()
+	config.Compression.MaxDecompressedSize = 4096
+
+	engine := newCompressionRouter(config, 0)
+
+	for _, encoding := range []string{EncodingGzip, EncodingDeflate, EncodingZstd} {
+		t.Run(encoding, func(t *testing.T) {
+			body := `"` + strings.Repeat("c", 4000) + `"`
+
+			recorder := send(engine, http.MethodPost, "/echo", string(compressData(t, encoding, []byte(body))), map[string]string{
+				"Content-Type":     "application/json",
+				"Content-Encoding": encoding,
+			})
+
+			if recorder.Code != http.StatusOK || recorder.Body.String() != body {
+				t.Fatalf("expected the decoded body, got %d", recorder.Code)
+			}
+
+			// A few hundred bytes inflating to 10 MiB, i.e. a decompression bomb.
+			bomb := compressData(t, encoding, []byte(`"`+strings.Repeat(" ", 10<<20)+`"`))
+
+			recorder = send(engine, http.MethodPost, "/echo", string(bomb), map[string]string{
+				"Content-Type":     "application/json",
+				"Content-Encoding": encoding,
+				HeaderRequestID:    "request-bomb",
+			})
+
+			if recorder.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("expected status 413 for a body inflating past the limit, got %d", recorder.Code)
+			}
+
+			if body := errorBody(t, recorder); body.RequestID != "request-bomb" {
+				t.Fatalf("expected the request id in the error body, got %+v", body)
+			}
+		})
+	}
+}
+
+func TestUnsupportedRequestEncodingsAreRejected(t *testing.T) {
+	engine := newCompressionRouter(DefaultConfig(), 0)
+
+	recorder := send(engine, http.MethodPost, "/echo", `"data"`, map[string]string{
+		"Content-Type":     "application/json",
+		"Content-Encoding": "br",
+		HeaderRequestID:    "request-br",
+	})
+
+	if recorder.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected status 415, got %d", recorder.Code)
+	}
+
+	if body := errorBody(t, recorder); body.RequestID != "request-br" {
+		t.Fatalf("expected the request id in the error body, got %+v", body)
+	}
+
+	config := DefaultConfig()
+	config.Compression.MaxDecompressedSize = 0
+
+	recorder = send(newCompressionRouter(config, 0), http.MethodPost, "/echo", string(compressData(t, EncodingGzip, []byte(`"data"`))), map[string]string{
+		"Content-Type":     "application/json",
+		"Content-Encoding": EncodingGzip,
+	})
+
+	if recorder.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected status 415 if compressed bodies are disabled, got %d", recorder.Code)
+	}
+}
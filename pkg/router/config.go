@@ -0,0 +1,50 @@
+package router
+
+import (
+	"github.com/gostream-official/albums/pkg/marshal"
+	"github.com/gostream-official/albums/pkg/redact"
+)
+
+// Description:
+//
+//	The configuration of a router.
+type Config struct {
+
+	// The redaction policy applied to request trace logs.
+	Redaction redact.Policy
+
+	// The networks (CIDR) or IP addresses of trusted proxies.
+	// The client IP is only taken from the X-Forwarded-For and X-Real-IP headers
+	// if the request was received from a trusted proxy. If empty, no proxy is trusted.
+	TrustedProxies []string
+
+	// The codecs used to decode request bodies and encode response bodies.
+	// Selected via the Content-Type and Accept headers. If nil, only JSON is supported.
+	Codecs *marshal.Codecs
+
+	// The compression of responses and decompression of request bodies.
+	Compression CompressionConfig
+
+	// The maximum size of request bodies in bytes, unless overridden per route, see Route.BodyLimit.
+	// Larger bodies are rejected with 413. If zero, request bodies are not limited.
+	MaxBodySize int64
+
+	// The CORS policy. Disabled if no origin is allowed.
+	CORS CORSConfig
+}
+
+// Description:
+//
+//	Creates the default router configuration.
+//
+// Returns:
+//
+//	The default router configuration.
+func DefaultConfig() Config {
+	return Config{
+		Redaction:   redact.DefaultPolicy(),
+		Codecs:      marshal.DefaultCodecs(),
+		Compression: DefaultCompressionConfig(),
+		MaxBodySize: 1 << 20,
+	}
+}
@@ -0,0 +1,211 @@
+package router
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Description:
+//
+//	The CORS (cross-origin resource sharing) configuration of a router.
+//	CORS is enabled if at least one origin is allowed.
+type CORSConfig struct {
+
+	// The allowed origins, e.g. https://editor.example.com.
+	// Supports wildcard patterns such as https://*.example.com, or * for any origin.
+	AllowedOrigins []string
+
+	// The methods allowed in cross-origin requests.
+	// If empty, the methods registered for the requested path are allowed.
+	AllowedMethods []string
+
+	// The request headers allowed in cross-origin requests, or * for any header.
+	AllowedHeaders []string
+
+	// The response headers exposed to cross-origin clients, e.g. ETag.
+	ExposedHeaders []string
+
+	// Whether cross-origin requests may include credentials, e.g. cookies or the Authorization header.
+	AllowCredentials bool
+
+	// The duration preflight responses may be cached by clients. Not sent if zero.
+	MaxAge time.Duration
+}
+
+// Description:
+//
+//	Checks whether the given origin is allowed.
+//
+// Parameters:
+//
+//	origin The Origin header, e.g. https://editor.example.com.
+//
+// Returns:
+//
+//	Whether the origin is allowed.
+func (config *CORSConfig) allowsOrigin(origin string) bool {
+	if config.allowsAnyOrigin() {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+
+	// Wildcards within patterns do not match the slashes of the scheme, so * is matched separately.
+	for _, pattern := range config.AllowedOrigins {
+		matched, err := path.Match(strings.ToLower(pattern), origin)
+		if err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Description:
+//
+//	Checks whether any origin is allowed, i.e. the * pattern is configured.
+//
+// Returns:
+//
+//	Whether any origin is allowed.
+func (config *CORSConfig) allowsAnyOrigin() bool {
+	for _, pattern := range config.AllowedOrigins {
+		if pattern == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Description:
+//
+//	Middleware applying the CORS policy.
+//	Answers valid preflight requests with 204 and adds the CORS headers to cross-origin responses.
+//	Requests of origins which are not allowed are served without CORS headers, so that browsers block them.
+//	Triggered by the gin framework.
+//
+// Parameters:
+//
+//	context The gin context.
+func (router *GinRouter) cors(context *gin.Context) {
+	config := &router.config.CORS
+	origin := context.GetHeader("Origin")
+
+	if len(config.AllowedOrigins) == 0 || origin == "" {
+		context.Next()
+		return
+	}
+
+	header := context.Writer.Header()
+	header.Add("Vary", "Origin")
+
+	if !config.allowsOrigin(origin) {
+		context.Next()
+		return
+	}
+
+	if config.allowsAnyOrigin() && !config.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if config.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	requestedMethod := context.GetHeader("Access-Control-Request-Method")
+
+	if context.Request.Method != http.MethodOptions || requestedMethod == "" {
+		if len(config.ExposedHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(config.ExposedHeaders, ", "))
+		}
+
+		context.Next()
+		return
+	}
+
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+
+	methods := config.AllowedMethods
+	if len(methods) == 0 {
+		methods = router.routeMethods[context.FullPath()]
+	}
+
+	if !containsFold(methods, requestedMethod) {
+		context.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+
+	header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+	requestedHeaders := context.GetHeader("Access-Control-Request-Headers")
+
+	if containsFold(config.AllowedHeaders, "*") && requestedHeaders != "" {
+		header.Set("Access-Control-Allow-Headers", requestedHeaders)
+	} else if len(config.AllowedHeaders) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(config.AllowedHeaders, ", "))
+	}
+
+	if config.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+	}
+
+	context.AbortWithStatus(http.StatusNoContent)
+}
+
+// Description:
+//
+//	Registers the automatic OPTIONS handler for the given path, unless it is already registered.
+//	Answers OPTIONS requests with the registered methods, unless an explicit OPTIONS route is registered.
+//
+// Parameters:
+//
+//	path The path template.
+func (router *GinRouter) registerOptions(path string) {
+	if _, ok := router.optionsRoutes[path]; ok {
+		return
+	}
+
+	router.optionsRoutes[path] = nil
+
+	router.engine.Handle(http.MethodOptions, path, func(context *gin.Context) {
+		if route := router.optionsRoutes[path]; route != nil {
+			router.internalRouteHandler(route, context)
+			return
+		}
+
+		allowed := append([]string{http.MethodOptions}, router.routeMethods[path]...)
+		context.Header("Allow", strings.Join(allowed, ", "))
+		context.Status(http.StatusNoContent)
+	})
+}
+
+// Description:
+//
+//	Checks whether a list contains the given value, ignoring case.
+//
+// Parameters:
+//
+//	values 	The list.
+//	value 	The value.
+//
+// Returns:
+//
+//	Whether the list contains the value.
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+
+	return false
+}
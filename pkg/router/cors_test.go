@@ -0,0 +1,261 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/api"
+)
+
+// Description:
+//
+//	Creates a router with the given CORS policy, serving GET and POST /albums.
+//
+// Parameters:
+//
+//	cors The CORS policy.
+//
+// Returns:
+//
+//	The router.
+func newCORSRouter(cors CORSConfig) Router {
+	config := DefaultConfig()
+	config.CORS = cors
+
+	engine := New(config)
+
+	handler := func(request *api.APIRequest) *api.APIResponse {
+		return api.Text(http.StatusOK, "albums")
+	}
+
+	engine.Handle(http.MethodGet, "/albums", handler)
+	engine.Handle(http.MethodPost, "/albums", handler)
+
+	return engine
+}
+
+// Description:
+//
+//	Checks whether the Vary headers of a response contain the given header name.
+//
+// Parameters:
+//
+//	header 	The response headers.
+//	name 	The header name.
+//
+// Returns:
+//
+//	Whether the response varies by the header.
+func variesBy(header http.Header, name string) bool {
+	for _, value := range header.Values("Vary") {
+		if value == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestCORSAllowOrigin(t *testing.T) {
+	tests := []struct {
+		name        string
+		cors        CORSConfig
+		origin      string
+		allowOrigin string
+		credentials string
+	}{
+		{
+			name:        "wildcard",
+			cors:        CORSConfig{AllowedOrigins: []string{"*"}},
+			origin:      "https://editor.example.com",
+			allowOrigin: "*",
+		},
+		{
+			name:        "wildcard with credentials echoes the origin",
+			cors:        CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			origin:      "https://editor.example.com",
+			allowOrigin: "https://editor.example.com",
+			credentials: "true",
+		},
+		{
+			name:        "pattern",
+			cors:        CORSConfig{AllowedOrigins: []string{"https://*.example.com"}},
+			origin:      "https://Editor.example.com",
+			allowOrigin: "https://Editor.example.com",
+		},
+		{
+			name:   "disallowed origin",
+			cors:   CORSConfig{AllowedOrigins: []string{"https://*.example.com"}},
+			origin: "https://example.org",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := send(newCORSRouter(test.cors), http.MethodGet, "/albums", "", map[string]string{
+				"Origin": test.origin,
+			})
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", recorder.Code)
+			}
+
+			header := recorder.Header()
+
+			if header.Get("Access-Control-Allow-Origin") != test.allowOrigin {
+				t.Errorf("expected allowed origin %q, got %q", test.allowOrigin, header.Get("Access-Control-Allow-Origin"))
+			}
+
+			if header.Get("Access-Control-Allow-Credentials") != test.credentials {
+				t.Errorf("expected allowed credentials %q, got %q", test.credentials, header.Get("Access-Control-Allow-Credentials"))
+			}
+
+			if !variesBy(header, "Origin") {
+				t.Errorf("expected the response to vary by Origin, got %v", header.Values("Vary"))
+			}
+		})
+	}
+}
+
+func TestCORSExposedHeaders(t *testing.T) {
+	engine := newCORSRouter(CORSConfig{
+		AllowedOrigins: []string{"*"},
+		ExposedHeaders: []string{"ETag", "X-Request-ID"},
+	})
+
+	recorder := send(engine, http.MethodGet, "/albums", "", map[string]string{"Origin": "https://editor.example.com"})
+
+	if exposed := recorder.Header().Get("Access-Control-Expose-Headers"); exposed != "ETag, X-Request-ID" {
+		t.Errorf("expected exposed headers ETag, X-Request-ID, got %q", exposed)
+	}
+
+	recorder = send(engine, http.MethodGet, "/albums", "", nil)
+
+	if recorder.Header().Get("Access-Control-Allow-Origin") != "" || variesBy(recorder.Header(), "Origin") {
+		t.Errorf("expected no CORS headers for same-origin requests, got %v", recorder.Header())
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	tests := []struct {
+		name           string
+		cors           CORSConfig
+		method         string
+		requestHeaders string
+		allowMethods   string
+		allowHeaders   string
+	}{
+		{
+			name:         "registered methods",
+			cors:         CORSConfig{AllowedOrigins: []string{"*"}},
+			method:       http.MethodPost,
+			allowMethods: "GET, POST",
+		},
+		{
+			name:   "unregistered method",
+			cors:   CORSConfig{AllowedOrigins: []string{"*"}},
+			method: http.MethodDelete,
+		},
+		{
+			name:         "configured methods",
+			cors:         CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}},
+			method:       http.MethodGet,
+			allowMethods: "GET",
+		},
+		{
+			name:   "disallowed method",
+			cors:   CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}},
+			method: http.MethodPost,
+		},
+		{
+			name:           "wildcard headers echo the requested headers",
+			cors:           CORSConfig{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"*"}},
+			method:         http.MethodPost,
+			requestHeaders: "Content-Type, X-Api-Key",
+			allowMethods:   "GET, POST",
+			allowHeaders:   "Content-Type, X-Api-Key",
+		},
+		{
+			name:           "configured headers",
+			cors:           CORSConfig{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"Content-Type", "Authorization"}},
+			method:         http.MethodPost,
+			requestHeaders: "X-Api-Key",
+			allowMethods:   "GET, POST",
+			allowHeaders:   "Content-Type, Authorization",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			headers := map[string]string{
+				"Origin":                        "https://editor.example.com",
+				"Access-Control-Request-Method": test.method,
+			}
+
+			if test.requestHeaders != "" {
+				headers["Access-Control-Request-Headers"] = test.requestHeaders
+			}
+
+			recorder := send(newCORSRouter(test.cors), http.MethodOptions, "/albums", "", headers)
+
+			if recorder.Code != http.StatusNoContent {
+				t.Fatalf("expected status 204, got %d", recorder.Code)
+			}
+
+			header := recorder.Header()
+
+			if header.Get("Access-Control-Allow-Methods") != test.allowMethods {
+				t.Errorf("expected allowed methods %q, got %q", test.allowMethods, header.Get("Access-Control-Allow-Methods"))
+			}
+
+			if header.Get("Access-Control-Allow-Headers") != test.allowHeaders {
+				t.Errorf("expected allowed headers %q, got %q", test.allowHeaders, header.Get("Access-Control-Allow-Headers"))
+			}
+
+			for _, name := range []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"} {
+				if !variesBy(header, name) {
+					t.Errorf("expected the preflight to vary by %s, got %v", name, header.Values("Vary"))
+				}
+			}
+		})
+	}
+}
+
+func TestCORSPreflightMaxAge(t *testing.T) {
+	engine := newCORSRouter(CORSConfig{AllowedOrigins: []string{"*"}, MaxAge: 10 * time.Minute})
+
+	recorder := send(engine, http.MethodOptions, "/albums", "", map[string]string{
+		"Origin":                        "https://editor.example.com",
+		"Access-Control-Request-Method": http.MethodGet,
+	})
+
+	if maxAge := recorder.Header().Get("Access-Control-Max-Age"); maxAge != "600" {
+		t.Errorf("expected max age 600, got %q", maxAge)
+	}
+}
+
+func TestAutomaticOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		cors    CORSConfig
+		headers map[string]string
+	}{
+		{name: "without CORS", headers: nil},
+		{name: "without preflight", cors: CORSConfig{AllowedOrigins: []string{"*"}}, headers: map[string]string{"Origin": "https://editor.example.com"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := send(newCORSRouter(test.cors), http.MethodOptions, "/albums", "", test.headers)
+
+			if recorder.Code != http.StatusNoContent {
+				t.Fatalf("expected status 204, got %d", recorder.Code)
+			}
+
+			if allow := recorder.Header().Get("Allow"); allow != "OPTIONS, GET, POST" {
+				t.Errorf("expected allowed methods OPTIONS, GET, POST, got %q", allow)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package router
+
+// Description:
+//
+//	The documentation of a route, used to generate API specifications such as OpenAPI.
+type RouteDoc struct {
+
+	// The unique id of the operation, e.g. getalbums. Derived from the method and path if empty.
+	OperationID string
+
+	// A short summary of the route.
+	Summary string
+
+	// A detailed description of the route.
+	Description string
+
+	// The tags grouping the route, e.g. albums.
+	Tags []string
+
+	// A value of the request body type, or nil if the route takes no body.
+	Request interface{}
+
+	// The path parameters. Parameters of the path template, which are not documented, are strings.
+	Path []ParameterDoc
+
+	// The query parameters.
+	Query []ParameterDoc
+
+	// The documented responses.
+	Responses []ResponseDoc
+
+	// Whether the route requires an authenticated principal.
+	Secured bool
+
+	// The scopes required by the route.
+	Scopes []string
+
+	// Whether the route is excluded from API specifications.
+	Hidden bool
+}
+
+// Description:
+//
+//	The documentation of a path or query parameter.
+type ParameterDoc struct {
+
+	// The name of the parameter.
+	Name string
+
+	// A description of the parameter.
+	Description string
+
+	// A value of the parameter type, e.g. 0 for integers. Strings if nil.
+	Type interface{}
+
+	// The format of the parameter, e.g. uuid or date-time.
+	Format string
+
+	// Whether the parameter is required. Path parameters are always required.
+	Required bool
+}
+
+// Description:
+//
+//	The documentation of a response.
+type ResponseDoc struct {
+
+	// The status code of the response.
+	StatusCode int
+
+	// A description of the response.
+	Description string
+
+	// A value of the response body type, or nil if the response has no body.
+	Body interface{}
+}
+
+// Description:
+//
+//	Documents this route.
+//	Replaces the previous documentation, except for the security requirements and the visibility.
+//
+// Example:
+//
+//	route.Document(router.RouteDoc{
+//		Summary:   "Get an album",
+//		Responses: []router.ResponseDoc{{StatusCode: 200, Description: "The album.", Body: models.AlbumInfo{}}},
+//	})
+//
+// Parameters:
+//
+//	doc The route documentation.
+//
+// Returns:
+//
+//	The route, for chaining.
+func (route *Route) Document(doc RouteDoc) *Route {
+	doc.Secured = route.Doc.Secured
+	doc.Scopes = route.Doc.Scopes
+	doc.Hidden = route.Doc.Hidden
+
+	route.Doc = doc
+	return route
+}
+
+// Description:
+//
+//	Documents that this route requires an authenticated principal with the given scopes.
+//	Does not enforce the requirement, which is up to the route middleware.
+//
+// Parameters:
+//
+//	scopes The required scopes.
+//
+// Returns:
+//
+//	The route, for chaining.
+func (route *Route) Secured(scopes ...string) *Route {
+	route.Doc.Secured = true
+	route.Doc.Scopes = append(route.Doc.Scopes, scopes...)
+	return route
+}
+
+// Description:
+//
+//	Excludes this route from API specifications, e.g. operational endpoints.
+//
+// Returns:
+//
+//	The route, for chaining.
+func (route *Route) Hidden() *Route {
+	route.Doc.Hidden = true
+	return route
+}
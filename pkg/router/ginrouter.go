@@ -1,14 +1,29 @@
 package router
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/marshal"
+	"github.com/gostream-official/albums/pkg/parallel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+
+	// The size of the chunks streamed response bodies are read and flushed in.
+	streamChunkSize = 32 << 10
 )
 
 // Description:
@@ -16,10 +31,43 @@ import (
 //	Implementation of the Router interface for gin.
 type GinRouter struct {
 
+	// The root route group. Its middleware is applied globally.
+	*RouteGroup
+
 	// The gin engine.
 	engine *gin.Engine
+
+	// The router configuration.
+	config Config
+
+	// The registered shutdown hooks.
+	shutdownHooks []ShutdownHook
+
+	// The registered drain hooks.
+	drainHooks []DrainHook
+
+	// The registered panic hooks.
+	panicHooks []PanicHook
+
+	// The registered exchange observers.
+	observers []Observer
+
+	// The registered methods, by path template.
+	routeMethods map[string][]string
+
+	// The explicitly registered OPTIONS routes, by path template.
+	// Paths without explicit OPTIONS route are mapped to nil.
+	optionsRoutes map[string]*Route
+
+	// The registered routes, in order of registration.
+	routes []*Route
 }
 
+// Description:
+//
+//	The logger of this package.
+var logger = logging.Named("router")
+
 // Description:
 //
 //	Package initializer.
@@ -32,132 +80,266 @@ func init() {
 //
 //	Creates a new gin router.
 //
+// Parameters:
+//
+//	config The router configuration.
+//
 // Returns:
 //
 //	The created gin router.
-func NewGinRouter() *GinRouter {
+func NewGinRouter(config Config) *GinRouter {
 	engine := gin.New()
 
 	engine.RedirectTrailingSlash = true
 	engine.RedirectFixedPath = true
 
-	return &GinRouter{
-		engine: engine,
+	// Only headers set by trusted proxies are used to determine the client IP.
+	engine.RemoteIPHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
+	err := engine.SetTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		logger.Errorf("invalid trusted proxies, trusting none: %s", err)
+		engine.SetTrustedProxies(nil)
+	}
+
+	if config.Codecs == nil {
+		config.Codecs = marshal.NewCodecs(marshal.JSONCodec{})
+	}
+
+	encodings := make([]string, 0)
+	for _, encoding := range config.Compression.Encodings {
+		if _, ok := encoderPools[encoding]; !ok {
+			logger.Errorf("unsupported response encoding, ignoring: %s", encoding)
+			continue
+		}
+
+		encodings = append(encodings, encoding)
+	}
+
+	config.Compression.Encodings = encodings
+
+	router := &GinRouter{
+		engine:        engine,
+		config:        config,
+		routeMethods:  make(map[string][]string),
+		optionsRoutes: make(map[string]*Route),
 	}
+
+	engine.Use(router.observe, router.cors, router.compress)
+
+	router.RouteGroup = newRouteGroup(router.register)
+	return router
 }
 
 // Description:
 //
-//	Registers a new HTTP handler function for the given method and path.
-//	Paths can include wildcards and path variables.
+//	Registers a route with the gin engine.
+//	OPTIONS requests are answered automatically for every registered path, see registerOptions.
 //
 // Parameters:
 //
-//	method 	The http method to handle.
-//	path   	The path to handle.
-//	handler	The handler responsible for handling the request.
-func (router *GinRouter) Handle(method string, path string, handler RouterHandlerFunc) {
-	router.engine.Handle(method, path, func(context *gin.Context) {
-		internalRouteHandler(path, context, handler)
+//	route The route to register.
+func (router *GinRouter) register(route *Route) {
+	router.routes = append(router.routes, route)
+	router.registerOptions(route.Path)
+
+	if route.Method == http.MethodOptions {
+		router.optionsRoutes[route.Path] = route
+		return
+	}
+
+	router.routeMethods[route.Path] = append(router.routeMethods[route.Path], route.Method)
+
+	router.engine.Handle(route.Method, route.Path, func(context *gin.Context) {
+		router.internalRouteHandler(route, context)
 	})
 }
 
 // Description:
 //
-//	Registers a new HTTP handler function for the given method and path.
-//	Paths can include wildcards and path variables.
-//
-//	This method allows object injection for the router handler.
+//	Registers a hook which is called for every panic recovered by the router.
+//	Useful for tests asserting that no panic escapes a handler.
 //
 // Parameters:
 //
-//	method 	The http method to handle.
-//	path   	The path to handle.
-//	handler	The handler responsible for handling the request.
+//	hook The panic hook to register.
+func (router *GinRouter) OnPanic(hook PanicHook) {
+	router.panicHooks = append(router.panicHooks, hook)
+}
+
+// Description:
 //
-// Returns:
+//	Registers a hook which is executed during graceful shutdown,
+//	after all in-flight requests were drained.
+//	Hooks are executed in reverse order of registration.
 //
-//	The router injector which allows object injection for the registered endpoint.
-func (router *GinRouter) HandleWith(method string, path string, handler RouterInjectionHandlerFunc) *RouterInjector {
-	injector := &RouterInjector{}
-
-	router.engine.Handle(method, path, func(context *gin.Context) {
-		internalRouteInjectionHandler(path, context, handler, injector)
-	})
+// Parameters:
+//
+//	hook The shutdown hook to register.
+func (router *GinRouter) OnShutdown(hook ShutdownHook) {
+	router.shutdownHooks = append(router.shutdownHooks, hook)
+}
 
-	return injector
+// Description:
+//
+//	Registers a hook which is called as soon as a shutdown signal is received,
+//	before the drain delay elapses and connections are drained.
+//
+// Parameters:
+//
+//	hook The drain hook to register.
+func (router *GinRouter) OnDrain(hook DrainHook) {
+	router.drainHooks = append(router.drainHooks, hook)
 }
 
 // Description:
 //
 //	Starts the HTTP server for this router and listens to all registered routes.
+//	Serves all configured listeners, each with the same timeouts.
+//	Blocks until a listener fails or a SIGINT or SIGTERM signal is received.
+//	On signal, the server stops accepting connections, drains in-flight requests
+//	and runs all shutdown hooks within the configured shutdown timeout.
+//
+// Parameters:
+//
+//	config The server configuration.
 //
 // Returns:
 //
-//	An error if serving the router fails.
-func (router *GinRouter) Run(port uint16) error {
-	portFmt := fmt.Sprintf(":%d", port)
-
-	// server := &http.Server{
-	// 	Addr:    portFmt,
-	// 	Handler: router.engine,
-	// }
-
-	// // Setting this to false apparently reduces memory usage.
-	// // However, setting this to true apparently is the standard and improves performance.
-	// server.SetKeepAlivesEnabled(true)
+//	An error if serving the router fails or the shutdown is not graceful.
+func (router *GinRouter) Run(config ServerConfig) error {
+	return serve(config, router.engine, router.drainHooks, router.shutdownHooks)
+}
 
-	// return server.ListenAndServe()
+// Description:
+//
+//	Gets the HTTP handler serving all registered routes.
+//	Can be used to serve this router on a listener of another router.
+//
+// Returns:
+//
+//	The HTTP handler.
+func (router *GinRouter) Handler() http.Handler {
+	return router.engine
+}
 
-	return router.engine.Run(portFmt)
+// Description:
+//
+//	Gets all registered routes, in order of registration.
+//
+// Returns:
+//
+//	The registered routes.
+func (router *GinRouter) Routes() []*Route {
+	return router.routes
 }
 
 // Description:
 //
 //	Internal handler method for incoming requests.
 //	Triggered by the gin framework.
+//	Panics in request transformation or the handler are recovered.
 //
 // Parameters:
 //
-//	pathHandle 	The registered path handle.
+//	route 		The registered route.
 //	context 	The internal gin context.
-//	handler 	The registered handler function.
-func internalRouteHandler(pathHandle string, context *gin.Context, handler RouterHandlerFunc) {
-	request := context.Request
+func (router *GinRouter) internalRouteHandler(route *Route, context *gin.Context) {
+	state := getRequestState(context)
+	requestID := state.id
 
-	internalRequest, err := transformRequest(pathHandle, request)
+	requestLogger := logging.Named("request").With(
+		logging.F("requestId", requestID),
+		logging.F("method", route.Method),
+		logging.F("route", route.Path),
+	).With(spanFields(trace.SpanFromContext(context.Request.Context()))...)
+
+	var internalRequest *api.APIRequest
+	defer recoverPanic(requestID, requestLogger, &internalRequest, context, router.panicHooks)
+
+	internalRequest, err := transformRequest(route.Path, context.Request)
 
 	if err != nil {
-		panic("router: cannot transform request")
+		requestLogger.Errorf("failed to transform request: %s", err)
+		context.AbortWithStatusJSON(http.StatusInternalServerError, internalErrorBody(requestID))
+		return
 	}
 
-	internalResponse := handler(internalRequest)
-	applyResponse(internalResponse, context)
-}
+	body, err := openBody(context.Request, router.bodyLimit(route), router.config.Compression.MaxDecompressedSize)
+	if err == nil {
+		defer body.Close()
 
-// Description:
-//
-//	Internal handler method for incoming requests.
-//	Allows object injection for the route handler.
-//	Triggered by the gin framework.
-//
-// Parameters:
-//
-//	pathHandle 	The registered path handle.
-//	context 	The internal gin context.
-//	handler 	The registered handler function.
-//	injector	The router injector.
-func internalRouteInjectionHandler(pathHandle string, context *gin.Context, handler RouterInjectionHandlerFunc, injector *RouterInjector) {
-	request := context.Request
+		if route.streamBody {
+			internalRequest.BodyReader = body
+		} else {
+			var data []byte
+			data, err = readBody(body, context.GetHeader("Content-Encoding"))
+			internalRequest.Body = string(data)
+		}
+	}
 
-	internalRequest, err := transformRequest(pathHandle, request)
+	var requestErr *requestError
+	if errors.As(err, &requestErr) {
+		requestLogger.Warnf("rejected request: %s", requestErr)
+		context.AbortWithStatusJSON(requestErr.statusCode, api.ErrorResponseBody{
+			Message:   requestErr.message,
+			RequestID: requestID,
+		})
+		return
+	}
 
 	if err != nil {
-		panic("router: cannot transform request")
+		requestLogger.Errorf("failed to read request body: %s", err)
+		context.AbortWithStatusJSON(http.StatusInternalServerError, internalErrorBody(requestID))
+		return
 	}
 
-	internalResponse := handler(internalRequest, injector.Injector)
-	applyResponse(internalResponse, context)
+	hasBody := internalRequest.Body != "" || (route.streamBody && context.Request.ContentLength != 0)
+
+	codec, ok := router.config.Codecs.ForContentType(context.GetHeader("Content-Type"))
+	if !ok && hasBody {
+		requestLogger.Warnf("unsupported content type: %s", context.GetHeader("Content-Type"))
+		context.AbortWithStatusJSON(http.StatusUnsupportedMediaType, api.ErrorResponseBody{
+			Message:   "unsupported content type",
+			RequestID: requestID,
+		})
+		return
+	}
+
+	// The response codec is negotiated before serving, so that requests with side effects are not
+	// processed if their response cannot be encoded. Safe requests are served regardless, as their
+	// handlers may respond with raw bodies, e.g. metrics or documentation pages.
+	responseCodec, acceptable := router.config.Codecs.Negotiate(context.GetHeader("Accept"))
+	if !acceptable && !isSafeMethod(route.Method) {
+		requestLogger.Warnf("unsupported accept header: %s", context.GetHeader("Accept"))
+		context.Header("Vary", "Accept")
+		context.AbortWithStatusJSON(http.StatusNotAcceptable, notAcceptableBody(requestID))
+		return
+	}
+
+	internalRequest.Codec = codec
+	internalRequest.RequestID = requestID
+	internalRequest.Trace = state.trace
+	internalRequest.ClientIP = context.ClientIP()
+	internalRequest.Context = parallel.WithID(context.Request.Context(), requestID)
+	internalRequest.Logger = requestLogger
+
+	if requestLogger.Enabled(logging.LevelTrace) {
+		policy := router.config.Redaction
+
+		requestLogger.Log(logging.LevelTrace, "request received",
+			logging.F("path", internalRequest.Path),
+			logging.F("headers", policy.RedactHeaders(internalRequest.Headers)),
+			logging.F("query", policy.RedactQueryParameters(internalRequest.QueryParameters)),
+			logging.F("body", policy.RedactBody(internalRequest.Body, context.GetHeader("Content-Type"))),
+		)
+	}
+
+	state.request = internalRequest
+
+	internalResponse := route.serve(internalRequest)
+	state.response = internalResponse
+
+	applyResponse(internalResponse, context, responseCodec, requestID)
 }
 
 // Description:
@@ -171,19 +353,22 @@ func internalRouteInjectionHandler(pathHandle string, context *gin.Context, hand
 //
 // Returns:
 //
-//	The transformed request, or an error, if the request could not be transformed.
+//	The transformed request without body, or an error, if the request could not be transformed.
 func transformRequest(pathHandle string, request *http.Request) (*api.APIRequest, error) {
 	result := api.APIRequest{
-		Url:             request.URL.String(),
-		Path:            request.URL.Path,
-		Method:          request.Method,
-		Headers:         make(map[string]string),
-		PathParameters:  make(map[string]string),
-		QueryParameters: make(map[string]string),
+		Url:                       request.URL.String(),
+		Path:                      request.URL.Path,
+		Method:                    request.Method,
+		Headers:                   make(map[string]string),
+		MultiValueHeaders:         make(map[string][]string),
+		PathParameters:            make(map[string]string),
+		QueryParameters:           make(map[string]string),
+		MultiValueQueryParameters: make(map[string][]string),
 	}
 
 	for key, values := range request.Header {
 		result.Headers[key] = strings.Join(values, ",")
+		result.MultiValueHeaders[key] = append([]string{}, values...)
 	}
 
 	pathParameters, err := extractPathParameters(pathHandle, request.URL.Path)
@@ -193,21 +378,16 @@ func transformRequest(pathHandle string, request *http.Request) (*api.APIRequest
 
 	result.PathParameters = pathParameters
 
-	queryParameters, err := extractQueryParameters(request.URL.String())
+	queryParameters, multiValueQueryParameters, err := extractQueryParameters(request.URL.String())
 	if err != nil {
 		return nil, err
 	}
 
 	result.QueryParameters = queryParameters
+	result.MultiValueQueryParameters = multiValueQueryParameters
 
-	defer request.Body.Close()
-
-	body, err := io.ReadAll(request.Body)
-	if err != nil {
-		return nil, err
-	}
+	result.Client = extractClientIdentity(request.TLS)
 
-	result.Body = string(body)
 	return &result, nil
 }
 
@@ -262,13 +442,14 @@ func extractPathParameters(handle string, path string) (map[string]string, error
 //
 // Returns:
 //
-//	A key-value map of the extracted query parameters.
-func extractQueryParameters(path string) (map[string]string, error) {
+//	A key-value map of the extracted query parameters, with repeated values joined by commas,
+//	and a map holding every value of each query parameter.
+func extractQueryParameters(path string) (map[string]string, map[string][]string, error) {
 	parameters := make(map[string]string)
 
 	parsedURL, err := url.Parse(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	query := parsedURL.Query()
@@ -277,7 +458,7 @@ func extractQueryParameters(path string) (map[string]string, error) {
 		parameters[key] = strings.Join(values, ",")
 	}
 
-	return parameters, nil
+	return parameters, query, nil
 }
 
 // Description:
@@ -288,7 +469,9 @@ func extractQueryParameters(path string) (map[string]string, error) {
 //
 //	response 	The response to apply.
 //	context 	The gin context.
-func applyResponse(response *api.APIResponse, context *gin.Context) {
+//	codec 		The codec negotiated via the Accept header for structured bodies, or nil if none is acceptable.
+//	requestID 	The id of the request.
+func applyResponse(response *api.APIResponse, context *gin.Context, codec marshal.Codec, requestID string) {
 	for key, value := range response.Headers {
 		context.Header(key, value)
 	}
@@ -298,5 +481,173 @@ func applyResponse(response *api.APIResponse, context *gin.Context) {
 		return
 	}
 
-	context.JSON(response.StatusCode, response.Body)
+	switch body := response.Body.(type) {
+	case *api.RawBody:
+		context.Data(response.StatusCode, body.ContentType, body.Data)
+
+	case *api.StreamBody:
+		if closer, ok := body.Reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+
+		writeStream(response.StatusCode, body, context)
+
+	case *api.FileBody:
+		serveFile(body, context)
+
+	default:
+		encodeBody(response, context, codec, requestID)
+	}
+}
+
+// Description:
+//
+//	Writes a streamed body, flushing every chunk read from its reader to the client,
+//	so that the body is never buffered as a whole.
+//
+// Parameters:
+//
+//	statusCode 	The response status code.
+//	body 		The streamed body.
+//	context 	The gin context.
+func writeStream(statusCode int, body *api.StreamBody, context *gin.Context) {
+	header := context.Writer.Header()
+	header.Set("Content-Type", body.ContentType)
+
+	if body.ContentLength >= 0 {
+		header.Set("Content-Length", strconv.FormatInt(body.ContentLength, 10))
+	}
+
+	context.Status(statusCode)
+
+	buffer := make([]byte, streamChunkSize)
+
+	for {
+		count, err := body.Reader.Read(buffer)
+
+		if count > 0 {
+			_, writeErr := context.Writer.Write(buffer[:count])
+			if writeErr != nil {
+				logger.Warnf("failed to write streamed response body: %s", writeErr)
+				return
+			}
+
+			context.Writer.Flush()
+		}
+
+		if err == io.EOF {
+			return
+		}
+
+		if err != nil {
+			logger.Warnf("failed to read streamed response body: %s", err)
+			return
+		}
+	}
+}
+
+// Description:
+//
+//	Encodes a structured response body with the codec negotiated via the Accept header.
+//	Responds with 406 if no codec is acceptable, or with 500 if encoding fails.
+//
+// Parameters:
+//
+//	response 	The response to encode.
+//	context 	The gin context.
+//	codec 		The negotiated codec, or nil if none is acceptable.
+//	requestID 	The id of the request.
+func encodeBody(response *api.APIResponse, context *gin.Context, codec marshal.Codec, requestID string) {
+	context.Writer.Header().Add("Vary", "Accept")
+
+	if codec == nil {
+		context.JSON(http.StatusNotAcceptable, notAcceptableBody(requestID))
+		return
+	}
+
+	data, err := codec.Marshal(response.Body)
+	if err != nil {
+		logger.Errorf("failed to encode response body as %s: %s", codec.MediaTypes()[0], err)
+		context.JSON(http.StatusInternalServerError, internalErrorBody(requestID))
+		return
+	}
+
+	context.Data(response.StatusCode, codec.MediaTypes()[0], data)
+}
+
+// Description:
+//
+//	Serves a file body, supporting range and conditional requests.
+//	Responds with 404 if the file does not exist.
+//
+// Parameters:
+//
+//	body 	The file body.
+//	context The gin context.
+func serveFile(body *api.FileBody, context *gin.Context) {
+	file, err := os.Open(body.Path)
+	if err != nil {
+		logger.Warnf("cannot open file %s: %s", body.Path, err)
+		context.Status(http.StatusNotFound)
+		return
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		context.Status(http.StatusNotFound)
+		return
+	}
+
+	if body.ContentType != "" {
+		context.Header("Content-Type", body.ContentType)
+	}
+
+	name := filepath.Base(body.Path)
+
+	if body.Name != "" {
+		name = body.Name
+		context.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": body.Name}))
+	}
+
+	http.ServeContent(context.Writer, context.Request, name, info.ModTime(), file)
+}
+
+// Description:
+//
+//	Checks whether a method is safe, i.e. has no side effects.
+//
+// Parameters:
+//
+//	method The HTTP method.
+//
+// Returns:
+//
+//	Whether the method is GET, HEAD or OPTIONS.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	return false
+}
+
+// Description:
+//
+//	Creates the response body for requests without acceptable media type.
+//
+// Parameters:
+//
+//	requestID The id of the request.
+//
+// Returns:
+//
+//	The error response body.
+func notAcceptableBody(requestID string) api.ErrorResponseBody {
+	return api.ErrorResponseBody{
+		Message:   "none of the accepted media types is supported",
+		RequestID: requestID,
+	}
 }
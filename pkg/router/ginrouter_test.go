@@ -0,0 +1,236 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gostream-official/albums/pkg/api"
+)
+
+// Description:
+//
+//	Sends a request to the router.
+//
+// Parameters:
+//
+//	engine 	The router.
+//	method 	The request method.
+//	path 	The request path.
+//	body 	The request body.
+//	headers The request headers.
+//
+// Returns:
+//
+//	The recorded response.
+func send(engine Router, method string, path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	recorder := httptest.NewRecorder()
+	engine.Handler().ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+// Description:
+//
+//	Decodes an error response body.
+//
+// Parameters:
+//
+//	t 			The test.
+//	recorder 	The recorded response.
+//
+// Returns:
+//
+//	The error response body.
+func errorBody(t *testing.T, recorder *httptest.ResponseRecorder) api.ErrorResponseBody {
+	body := api.ErrorResponseBody{}
+
+	err := json.Unmarshal(recorder.Body.Bytes(), &body)
+	if err != nil {
+		t.Fatalf("expected a JSON error body, got %q", recorder.Body.String())
+	}
+
+	return body
+}
+
+func TestUnacceptableWritesAreNotServed(t *testing.T) {
+	engine := New(DefaultConfig())
+	served := false
+
+	engine.Handle(http.MethodPost, "/items", func(request *api.APIRequest) *api.APIResponse {
+		served = true
+		return &api.APIResponse{StatusCode: http.StatusCreated, Body: map[string]string{"id": "1"}}
+	})
+
+	recorder := send(engine, http.MethodPost, "/items", `{}`, map[string]string{
+		"Accept":        "text/csv",
+		"Content-Type":  "application/json",
+		HeaderRequestID: "request-1",
+	})
+
+	if recorder.Code != http.StatusNotAcceptable {
+		t.Fatalf("expected status 406, got %d", recorder.Code)
+	}
+
+	if served {
+		t.Fatalf("expected the handler not to be served")
+	}
+
+	if body := errorBody(t, recorder); body.RequestID != "request-1" {
+		t.Fatalf("expected the request id in the error body, got %+v", body)
+	}
+}
+
+func TestUnacceptableReadsServeRawBodies(t *testing.T) {
+	engine := New(DefaultConfig())
+
+	engine.Handle(http.MethodGet, "/page", func(request *api.APIRequest) *api.APIResponse {
+		return &api.APIResponse{StatusCode: http.StatusOK, Body: &api.RawBody{ContentType: "text/html", Data: []byte("<html></html>")}}
+	})
+
+	engine.Handle(http.MethodGet, "/items", func(request *api.APIRequest) *api.APIResponse {
+		return &api.APIResponse{StatusCode: http.StatusOK, Body: []string{"a"}}
+	})
+
+	recorder := send(engine, http.MethodGet, "/page", "", map[string]string{"Accept": "text/html"})
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "<html></html>" {
+		t.Fatalf("expected the raw body, got %d: %q", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = send(engine, http.MethodGet, "/items", "", map[string]string{"Accept": "text/html", HeaderRequestID: "request-2"})
+	if recorder.Code != http.StatusNotAcceptable {
+		t.Fatalf("expected status 406, got %d", recorder.Code)
+	}
+
+	if body := errorBody(t, recorder); body.RequestID != "request-2" {
+		t.Fatalf("expected the request id in the error body, got %+v", body)
+	}
+}
+
+func TestResponsesAreNegotiated(t *testing.T) {
+	engine := New(DefaultConfig())
+
+	engine.Handle(http.MethodGet, "/items", func(request *api.APIRequest) *api.APIResponse {
+		return &api.APIResponse{StatusCode: http.StatusOK, Body: []string{"a"}}
+	})
+
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"application/xml", "application/xml"},
+		{"text/html;q=0.9, application/yaml", "application/yaml"},
+	}
+
+	for _, test := range tests {
+		recorder := send(engine, http.MethodGet, "/items", "", map[string]string{"Accept": test.accept})
+
+		if recorder.Code != http.StatusOK || !strings.HasPrefix(recorder.Header().Get("Content-Type"), test.contentType) {
+			t.Fatalf("expected %s for %q, got %d with %s", test.contentType, test.accept, recorder.Code, recorder.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestUnencodableBodiesFailWithRequestID(t *testing.T) {
+	engine := New(DefaultConfig())
+
+	engine.Handle(http.MethodGet, "/items", func(request *api.APIRequest) *api.APIResponse {
+		return &api.APIResponse{StatusCode: http.StatusOK, Body: map[string]string{"id": "1"}}
+	})
+
+	recorder := send(engine, http.MethodGet, "/items", "", map[string]string{"Accept": "application/xml", HeaderRequestID: "request-3"})
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", recorder.Code)
+	}
+
+	if body := errorBody(t, recorder); body.RequestID != "request-3" {
+		t.Fatalf("expected the request id in the error body, got %+v", body)
+	}
+}
+
+func TestParameterErrorsAreBadRequests(t *testing.T) {
+	engine := New(DefaultConfig())
+
+	engine.Handle(http.MethodGet, "/albums/:id/tracks", func(request *api.APIRequest) *api.APIResponse {
+		id, err := request.PathUUID("id")
+		if err != nil {
+			return &api.APIResponse{StatusCode: http.StatusBadRequest, Body: err}
+		}
+
+		limit, err := request.QueryInt("limit", 10)
+		if err != nil {
+			return &api.APIResponse{StatusCode: http.StatusBadRequest, Body: err}
+		}
+
+		trackIDs, err := request.QueryUUIDs("trackId")
+		if err != nil {
+			return &api.APIResponse{StatusCode: http.StatusBadRequest, Body: err}
+		}
+
+		page, err := request.HeaderInt("X-Page", 1)
+		if err != nil {
+			return &api.APIResponse{StatusCode: http.StatusBadRequest, Body: err}
+		}
+
+		return api.Text(http.StatusOK, fmt.Sprintf("%s %d %d %d", id, limit, len(trackIDs), page))
+	})
+
+	const albumID = "0b6d8a1c-6f2e-4f5a-9d43-2c1e8f7a9b10"
+	const trackID = "5f0c2a9e-3b7d-4e1a-8c6f-9d2b4a7e1c03"
+
+	tests := []struct {
+		name     string
+		path     string
+		headers  map[string]string
+		body     string
+		location string
+		param    string
+	}{
+		{name: "defaults", path: "/albums/" + albumID + "/tracks", body: albumID + " 10 0 1"},
+		{name: "all values", path: "/albums/" + albumID + "/tracks?limit=5&trackId=" + trackID + "&trackId=" + trackID, headers: map[string]string{"x-page": "3"}, body: albumID + " 5 2 3"},
+		{name: "malformed path", path: "/albums/album-1/tracks", location: api.ParameterLocationPath, param: "id"},
+		{name: "malformed query", path: "/albums/" + albumID + "/tracks?limit=five", location: api.ParameterLocationQuery, param: "limit"},
+		{name: "empty query", path: "/albums/" + albumID + "/tracks?limit=", location: api.ParameterLocationQuery, param: "limit"},
+		{name: "malformed repeated query", path: "/albums/" + albumID + "/tracks?trackId=" + trackID + "&trackId=track-2", location: api.ParameterLocationQuery, param: "trackId"},
+		{name: "malformed header", path: "/albums/" + albumID + "/tracks", headers: map[string]string{"X-Page": "last"}, location: api.ParameterLocationHeader, param: "X-Page"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := send(engine, http.MethodGet, test.path, "", test.headers)
+
+			if test.location == "" {
+				if recorder.Code != http.StatusOK || recorder.Body.String() != test.body {
+					t.Fatalf("expected %q, got %d: %s", test.body, recorder.Code, recorder.Body.String())
+				}
+
+				return
+			}
+
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d: %s", recorder.Code, recorder.Body.String())
+			}
+
+			body := api.ParameterError{}
+
+			err := json.Unmarshal(recorder.Body.Bytes(), &body)
+			if err != nil {
+				t.Fatalf("expected a parameter error body, got %q", recorder.Body.String())
+			}
+
+			if body.Location != test.location || body.Name != test.param || body.Message == "" {
+				t.Errorf("expected an error of %s parameter %s, got %+v", test.location, test.param, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+
+	// The first file descriptor passed by systemd socket activation.
+	systemdListenFdsStart = 3
+)
+
+// Description:
+//
+//	The configuration of a single listener.
+//
+//	Supported address formats:
+//	  - host:port, tcp://host:port 	TCP socket, e.g. 127.0.0.1:9871 or :9871
+//	  - unix:///path/to/socket 		Unix domain socket
+//	  - fd://3 						An inherited file descriptor
+//	  - systemd://, systemd://name 	A socket passed via systemd socket activation,
+//									either the first one or the one with the given name
+type ListenerConfig struct {
+
+	// The name of the listener, used for logging.
+	Name string
+
+	// The address to listen on.
+	Address string
+
+	// The TLS configuration. If nil, the listener serves plain HTTP.
+	TLS *TLSConfig
+
+	// The router served on this listener.
+	// If nil, the router which is run serves this listener.
+	Router Router
+}
+
+// Description:
+//
+//	Creates a network listener for the given address.
+//
+// Parameters:
+//
+//	address The listener address. See ListenerConfig for supported formats.
+//
+// Returns:
+//
+//	The created listener, or an error if the address is invalid or binding fails.
+func listen(address string) (net.Listener, error) {
+	scheme, target, found := strings.Cut(address, "://")
+
+	if !found {
+		return net.Listen("tcp", address)
+	}
+
+	switch scheme {
+	case "tcp", "tcp4", "tcp6":
+		return net.Listen(scheme, target)
+
+	case "unix":
+		// Remove stale sockets left behind by a previous, unclean shutdown.
+		info, err := os.Lstat(target)
+		if err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(target)
+		}
+
+		return net.Listen("unix", target)
+
+	case "fd":
+		fd, err := strconv.Atoi(target)
+		if err != nil || fd < 0 {
+			return nil, fmt.Errorf("router: invalid file descriptor: %s", target)
+		}
+
+		return listenFileDescriptor(uintptr(fd), address)
+
+	case "systemd":
+		fd, err := findSystemdFileDescriptor(target)
+		if err != nil {
+			return nil, err
+		}
+
+		return listenFileDescriptor(fd, address)
+	}
+
+	return nil, fmt.Errorf("router: unsupported listener address: %s", address)
+}
+
+// Description:
+//
+//	Creates a network listener from an inherited file descriptor.
+//
+// Parameters:
+//
+//	fd 		The file descriptor.
+//	name 	The name of the file, used for error messages.
+//
+// Returns:
+//
+//	The created listener, or an error if the file descriptor is not a listening socket.
+func listenFileDescriptor(fd uintptr, name string) (net.Listener, error) {
+	file := os.NewFile(fd, name)
+	if file == nil {
+		return nil, fmt.Errorf("router: invalid file descriptor: %d", fd)
+	}
+
+	// net.FileListener duplicates the file descriptor.
+	defer file.Close()
+
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("router: cannot listen on file descriptor %d: %s", fd, err)
+	}
+
+	return listener, nil
+}
+
+// Description:
+//
+//	Finds a file descriptor passed via systemd socket activation.
+//	See sd_listen_fds(3) for the protocol.
+//
+// Parameters:
+//
+//	name The name of the socket (FileDescriptorName=), or empty for the first socket.
+//
+// Returns:
+//
+//	The file descriptor, or an error if no matching socket was passed.
+func findSystemdFileDescriptor(name string) (uintptr, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return 0, fmt.Errorf("router: no sockets passed by systemd")
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return 0, fmt.Errorf("router: no sockets passed by systemd")
+	}
+
+	if name == "" {
+		return systemdListenFdsStart, nil
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	for index := 0; index < count && index < len(names); index++ {
+		if names[index] == name {
+			return uintptr(systemdListenFdsStart + index), nil
+		}
+	}
+
+	return 0, fmt.Errorf("router: no socket named %s passed by systemd", name)
+}
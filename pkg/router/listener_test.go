@@ -0,0 +1,228 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/api"
+)
+
+// Description:
+//
+//	Creates an HTTP client sending all requests to the given Unix domain socket.
+//
+// Parameters:
+//
+//	path The path of the socket.
+//
+// Returns:
+//
+//	The created client.
+func unixClient(path string) *http.Client {
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
+
+// Description:
+//
+//	Creates a router answering GET /name with the given name.
+//
+// Parameters:
+//
+//	name The name to answer with.
+//
+// Returns:
+//
+//	The router.
+func newNamedRouter(name string) Router {
+	engine := New(DefaultConfig())
+	engine.Handle(http.MethodGet, "/name", func(request *api.APIRequest) *api.APIResponse {
+		return api.Text(http.StatusOK, name)
+	})
+
+	return engine
+}
+
+func TestFindSystemdFileDescriptor(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+
+	tests := []struct {
+		name    string
+		pid     string
+		fds     string
+		names   string
+		socket  string
+		fd      uintptr
+		invalid bool
+	}{
+		{name: "first socket", pid: pid, fds: "1", fd: 3},
+		{name: "first of many sockets", pid: pid, fds: "3", names: "http:admin:metrics", fd: 3},
+		{name: "named socket", pid: pid, fds: "3", names: "http:admin:metrics", socket: "admin", fd: 4},
+		{name: "last named socket", pid: pid, fds: "3", names: "http:admin:metrics", socket: "metrics", fd: 5},
+		{name: "unknown name", pid: pid, fds: "3", names: "http:admin:metrics", socket: "grpc", invalid: true},
+		{name: "name beyond passed sockets", pid: pid, fds: "1", names: "http:admin", socket: "admin", invalid: true},
+		{name: "name without names", pid: pid, fds: "2", socket: "admin", invalid: true},
+		{name: "other process", pid: strconv.Itoa(os.Getpid() + 1), fds: "1", invalid: true},
+		{name: "missing pid", fds: "1", invalid: true},
+		{name: "malformed pid", pid: "self", fds: "1", invalid: true},
+		{name: "missing fds", pid: pid, invalid: true},
+		{name: "no fds", pid: pid, fds: "0", invalid: true},
+		{name: "malformed fds", pid: pid, fds: "many", invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("LISTEN_PID", test.pid)
+			t.Setenv("LISTEN_FDS", test.fds)
+			t.Setenv("LISTEN_FDNAMES", test.names)
+
+			fd, err := findSystemdFileDescriptor(test.socket)
+
+			if test.invalid {
+				if err == nil {
+					t.Fatalf("expected an error, got file descriptor %d", fd)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected file descriptor %d, got error: %s", test.fd, err)
+			}
+
+			if fd != test.fd {
+				t.Errorf("expected file descriptor %d, got %d", test.fd, fd)
+			}
+		})
+	}
+}
+
+func TestListenAddresses(t *testing.T) {
+	inherited, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+
+	defer inherited.Close()
+
+	file, err := inherited.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatalf("failed to get file descriptor: %s", err)
+	}
+
+	defer file.Close()
+
+	tests := []struct {
+		name    string
+		address string
+		network string
+		invalid bool
+	}{
+		{name: "host and port", address: "127.0.0.1:0", network: "tcp"},
+		{name: "tcp scheme", address: "tcp://127.0.0.1:0", network: "tcp"},
+		{name: "unix socket", address: "unix://" + filepath.Join(t.TempDir(), "albums.sock"), network: "unix"},
+		{name: "file descriptor", address: fmt.Sprintf("fd://%d", file.Fd()), network: "tcp"},
+		{name: "malformed file descriptor", address: "fd://three", invalid: true},
+		{name: "unsupported scheme", address: "udp://127.0.0.1:0", invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			listener, err := listen(test.address)
+
+			if test.invalid {
+				if err == nil {
+					listener.Close()
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to listen: %s", err)
+			}
+
+			defer listener.Close()
+
+			if network := listener.Addr().Network(); network != test.network {
+				t.Errorf("expected network %s, got %s", test.network, network)
+			}
+		})
+	}
+}
+
+func TestServeNamedListeners(t *testing.T) {
+	directory := t.TempDir()
+	publicSocket := filepath.Join(directory, "public.sock")
+	adminSocket := filepath.Join(directory, "admin.sock")
+
+	config := ServerConfig{
+		Listeners: []ListenerConfig{
+			{Name: "public", Address: "unix://" + publicSocket},
+			{Name: "admin", Address: "unix://" + adminSocket, Router: newNamedRouter("admin")},
+		},
+		ShutdownTimeout: 5 * time.Second,
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- serve(config, newNamedRouter("public").Handler(), nil, nil)
+	}()
+
+	expected := map[string]string{publicSocket: "public", adminSocket: "admin"}
+
+	for socket, name := range expected {
+		client := unixClient(socket)
+		deadline := time.Now().Add(5 * time.Second)
+
+		for {
+			response, err := client.Get("http://albums/name")
+
+			if err == nil {
+				body, _ := io.ReadAll(response.Body)
+				response.Body.Close()
+
+				if string(body) != name {
+					t.Errorf("expected listener %s to serve router %s, got %q", socket, name, body)
+				}
+
+				break
+			}
+
+			if time.Now().After(deadline) {
+				t.Fatalf("listener %s is not serving: %s", socket, err)
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// Both listeners serve, so the shutdown signal is handled by the server.
+	syscall.Kill(os.Getpid(), syscall.SIGTERM)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected a graceful shutdown, got %s", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the server did not shut down")
+	}
+}
@@ -0,0 +1,257 @@
+package router
+
+import (
+	"strings"
+
+	"github.com/gostream-official/albums/pkg/api"
+)
+
+// Description:
+//
+//	Function definition for router middleware.
+//	A middleware may inspect or modify the request, call the next handler in the chain,
+//	and inspect or modify the response. It may also return a response without calling next,
+//	which short-circuits the remaining chain.
+//
+//	Middleware is executed in the following order:
+//	  - global middleware registered via Router.Use, in order of registration
+//	  - group middleware, from the outermost to the innermost group, in order of registration
+//	  - route middleware registered via Route.Use, in order of registration
+//
+//	Middleware added after a route was registered still applies to that route.
+type Middleware = func(request *api.APIRequest, next RouterHandlerFunc) *api.APIResponse
+
+// Description:
+//
+//	A registered route.
+type Route struct {
+
+	// The http method of the route.
+	Method string
+
+	// The path template of the route, including the group prefixes.
+	Path string
+
+	// The documentation of the route.
+	Doc RouteDoc
+
+	// The handler responsible for handling the request.
+	handler RouterHandlerFunc
+
+	// The group the route was registered on.
+	group *RouteGroup
+
+	// The route middleware.
+	middleware []Middleware
+
+	// The maximum size of request bodies in bytes.
+	// Zero applies the default of the router, negative values disable the limit.
+	bodyLimit int64
+
+	// Whether the request body is streamed to the handler instead of being read upfront.
+	streamBody bool
+}
+
+// Description:
+//
+//	A group of routes sharing a path prefix and middleware.
+type RouteGroup struct {
+
+	// The path prefix of the group, including the prefixes of the parent groups.
+	prefix string
+
+	// The parent group, nil for the root group.
+	parent *RouteGroup
+
+	// The group middleware.
+	middleware []Middleware
+
+	// Registers a route with the underlying router implementation.
+	register func(route *Route)
+}
+
+// Description:
+//
+//	Creates a new root route group.
+//
+// Parameters:
+//
+//	register Registers a route with the underlying router implementation.
+//
+// Returns:
+//
+//	The created root group.
+func newRouteGroup(register func(route *Route)) *RouteGroup {
+	return &RouteGroup{
+		register: register,
+	}
+}
+
+// Description:
+//
+//	Adds middleware to this route.
+//
+// Parameters:
+//
+//	middleware The middleware to add.
+//
+// Returns:
+//
+//	The route, for chaining.
+func (route *Route) Use(middleware ...Middleware) *Route {
+	route.middleware = append(route.middleware, middleware...)
+	return route
+}
+
+// Description:
+//
+//	Overrides the maximum size of request bodies of this route.
+//	Larger bodies are rejected with 413.
+//
+// Parameters:
+//
+//	limit The maximum body size in bytes. Negative values disable the limit.
+//
+// Returns:
+//
+//	The route, for chaining.
+func (route *Route) BodyLimit(limit int64) *Route {
+	route.bodyLimit = limit
+	return route
+}
+
+// Description:
+//
+//	Streams the request body of this route to the handler via APIRequest.BodyReader,
+//	instead of reading it into APIRequest.Body upfront.
+//	Used for large bodies, which are decoded incrementally, see api.DecodeEach.
+//
+// Returns:
+//
+//	The route, for chaining.
+func (route *Route) StreamBody() *Route {
+	route.streamBody = true
+	return route
+}
+
+// Description:
+//
+//	Handles a request by running the middleware chain and the route handler.
+//
+// Parameters:
+//
+//	request The incoming request.
+//
+// Returns:
+//
+//	The response.
+func (route *Route) serve(request *api.APIRequest) *api.APIResponse {
+	chain := make([]Middleware, 0)
+
+	groups := make([]*RouteGroup, 0)
+	for group := route.group; group != nil; group = group.parent {
+		groups = append(groups, group)
+	}
+
+	for index := len(groups) - 1; index >= 0; index-- {
+		chain = append(chain, groups[index].middleware...)
+	}
+
+	chain = append(chain, route.middleware...)
+
+	handler := route.handler
+
+	for index := len(chain) - 1; index >= 0; index-- {
+		middleware := chain[index]
+		next := handler
+
+		handler = func(request *api.APIRequest) *api.APIResponse {
+			return middleware(request, next)
+		}
+	}
+
+	return handler(request)
+}
+
+// Description:
+//
+//	Adds middleware to this group.
+//	The middleware applies to all routes of this group and its sub groups.
+//
+// Parameters:
+//
+//	middleware The middleware to add.
+func (group *RouteGroup) Use(middleware ...Middleware) {
+	group.middleware = append(group.middleware, middleware...)
+}
+
+// Description:
+//
+//	Creates a sub group with the given path prefix.
+//
+// Parameters:
+//
+//	prefix 		The path prefix, relative to this group.
+//	middleware 	The group middleware.
+//
+// Returns:
+//
+//	The created group.
+func (group *RouteGroup) Group(prefix string, middleware ...Middleware) *RouteGroup {
+	return &RouteGroup{
+		prefix:     joinPaths(group.prefix, prefix),
+		parent:     group,
+		middleware: middleware,
+		register:   group.register,
+	}
+}
+
+// Description:
+//
+//	Registers a new HTTP handler function for the given method and path.
+//	Paths can include wildcards and path variables.
+//
+// Parameters:
+//
+//	method 	The http method to handle.
+//	path   	The path to handle, relative to this group.
+//	handler	The handler responsible for handling the request.
+//
+// Returns:
+//
+//	The registered route.
+func (group *RouteGroup) Handle(method string, path string, handler RouterHandlerFunc) *Route {
+	route := &Route{
+		Method:  method,
+		Path:    joinPaths(group.prefix, path),
+		handler: handler,
+		group:   group,
+	}
+
+	group.register(route)
+	return route
+}
+
+// Description:
+//
+//	Joins two path segments, ensuring exactly one slash between them.
+//
+// Parameters:
+//
+//	prefix 	The path prefix.
+//	path 	The path.
+//
+// Returns:
+//
+//	The joined path.
+func joinPaths(prefix string, path string) string {
+	if prefix == "" {
+		return path
+	}
+
+	if path == "" || path == "/" {
+		return prefix
+	}
+
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
@@ -0,0 +1,92 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gostream-official/albums/pkg/api"
+)
+
+// Description:
+//
+//	Creates middleware recording its execution before and after calling the next handler.
+//
+// Parameters:
+//
+//	name 	The name recorded for the middleware.
+//	calls 	The recorded calls.
+//
+// Returns:
+//
+//	The middleware.
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(request *api.APIRequest, next RouterHandlerFunc) *api.APIResponse {
+		*calls = append(*calls, name)
+		response := next(request)
+		*calls = append(*calls, "/"+name)
+
+		return response
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	calls := make([]string, 0)
+
+	engine := New(DefaultConfig())
+	engine.Use(recordingMiddleware("global", &calls))
+
+	outer := engine.Group("/albums", recordingMiddleware("outer", &calls))
+	inner := outer.Group("/:id", recordingMiddleware("inner", &calls))
+
+	inner.Handle(http.MethodGet, "/tracks", func(request *api.APIRequest) *api.APIResponse {
+		calls = append(calls, "handler")
+		return api.Text(http.StatusOK, "tracks")
+	}).Use(recordingMiddleware("route", &calls))
+
+	// Middleware added after registration still applies, after the existing middleware of its level.
+	outer.Use(recordingMiddleware("late", &calls))
+
+	recorder := send(engine, http.MethodGet, "/albums/1/tracks", "", nil)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+
+	expected := "global outer late inner route handler /route /inner /late /outer /global"
+
+	if order := strings.Join(calls, " "); order != expected {
+		t.Errorf("expected order %q, got %q", expected, order)
+	}
+}
+
+func TestMiddlewareAbort(t *testing.T) {
+	calls := make([]string, 0)
+
+	abort := func(request *api.APIRequest, next RouterHandlerFunc) *api.APIResponse {
+		calls = append(calls, "abort")
+		return api.Text(http.StatusForbidden, "forbidden")
+	}
+
+	engine := New(DefaultConfig())
+	engine.Use(recordingMiddleware("global", &calls))
+
+	group := engine.Group("/albums", abort)
+
+	group.Handle(http.MethodGet, "", func(request *api.APIRequest) *api.APIResponse {
+		calls = append(calls, "handler")
+		return api.Text(http.StatusOK, "albums")
+	}).Use(recordingMiddleware("route", &calls))
+
+	recorder := send(engine, http.MethodGet, "/albums", "", nil)
+
+	if recorder.Code != http.StatusForbidden || recorder.Body.String() != "forbidden" {
+		t.Fatalf("expected the abort response, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	expected := "global abort /global"
+
+	if order := strings.Join(calls, " "); order != expected {
+		t.Errorf("expected order %q, got %q", expected, order)
+	}
+}
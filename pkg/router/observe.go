@@ -0,0 +1,187 @@
+package router
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostream-official/albums/pkg/api"
+)
+
+const (
+
+	// The gin context key holding the request state.
+	contextKeyRequestState = "router.requestState"
+)
+
+// Description:
+//
+//	A completed request-response exchange.
+//	Passed to observers after the response was written.
+type Exchange struct {
+
+	// The time the request was received.
+	Time time.Time
+
+	// The id of the request.
+	RequestID string
+
+	// The http method.
+	Method string
+
+	// The path template of the matched route, e.g. /albums/:id.
+	// Empty if no route matched.
+	Route string
+
+	// The requested path.
+	Path string
+
+	// The requested URL, including the unredacted query.
+	URL string
+
+	// The protocol, e.g. HTTP/1.1.
+	Protocol string
+
+	// The response status code.
+	Status int
+
+	// The number of response body bytes written.
+	Bytes int
+
+	// The duration between receiving the request and writing the response.
+	Latency time.Duration
+
+	// The client IP, honouring the headers of trusted proxies.
+	ClientIP string
+
+	// The user agent header.
+	UserAgent string
+
+	// The referer header.
+	Referer string
+
+	// The transformed request. Nil if no route matched or transformation failed.
+	Request *api.APIRequest
+
+	// The handler response. Nil if no route matched or the handler failed.
+	Response *api.APIResponse
+}
+
+// Description:
+//
+//	Function definition for exchange observers.
+//	Observers are called once per request, after the response was written,
+//	for both matched and unmatched routes.
+type Observer = func(exchange *Exchange)
+
+// Description:
+//
+//	The state of a request, shared between the observing middleware and the route handler.
+type requestState struct {
+
+	// The id of the request.
+	id string
+
+	// The W3C trace context, may be nil.
+	trace *api.TraceContext
+
+	// The transformed request, set by the route handler.
+	request *api.APIRequest
+
+	// The handler response, set by the route handler.
+	response *api.APIResponse
+}
+
+// Description:
+//
+//	Registers an observer which is called once per completed request.
+//
+// Parameters:
+//
+//	observer The observer to register.
+func (router *GinRouter) Observe(observer Observer) {
+	router.observers = append(router.observers, observer)
+}
+
+// Description:
+//
+//	The gin middleware preceding all routes.
+//	Resolves the request id and notifies the observers once the response was written.
+//
+// Parameters:
+//
+//	context The gin context.
+func (router *GinRouter) observe(context *gin.Context) {
+	start := time.Now()
+
+	requestID, trace := resolveRequestID(context.Request)
+	context.Header(HeaderRequestID, requestID)
+
+	state := &requestState{
+		id:    requestID,
+		trace: trace,
+	}
+
+	context.Set(contextKeyRequestState, state)
+
+	span := startServerSpan(context, requestID, router.config.Redaction)
+	context.Next()
+	endServerSpan(span, context.Writer.Status())
+
+	if len(router.observers) == 0 {
+		return
+	}
+
+	bytes := context.Writer.Size()
+	if bytes < 0 {
+		bytes = 0
+	}
+
+	exchange := &Exchange{
+		Time:      start,
+		RequestID: requestID,
+		Method:    context.Request.Method,
+		Route:     context.FullPath(),
+		Path:      context.Request.URL.Path,
+		URL:       context.Request.URL.RequestURI(),
+		Protocol:  context.Request.Proto,
+		Status:    context.Writer.Status(),
+		Bytes:     bytes,
+		Latency:   time.Since(start),
+		ClientIP:  context.ClientIP(),
+		UserAgent: context.Request.UserAgent(),
+		Referer:   context.Request.Referer(),
+		Request:   state.request,
+		Response:  state.response,
+	}
+
+	for _, observer := range router.observers {
+		observer(exchange)
+	}
+}
+
+// Description:
+//
+//	Gets the request state of the given gin context.
+//
+// Parameters:
+//
+//	context The gin context.
+//
+// Returns:
+//
+//	The request state.
+func getRequestState(context *gin.Context) *requestState {
+	value, ok := context.Get(contextKeyRequestState)
+
+	if ok {
+		return value.(*requestState)
+	}
+
+	// Only reached if the observing middleware was bypassed.
+	requestID, trace := resolveRequestID(context.Request)
+
+	return &requestState{
+		id:    requestID,
+		trace: trace,
+	}
+}
@@ -0,0 +1,78 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/logging"
+)
+
+// Description:
+//
+//	Function definition for panic hooks.
+//	Panic hooks are called for every panic recovered by the router.
+//	The request is nil if the panic occurred before the request was transformed.
+type PanicHook = func(request *api.APIRequest, recovered interface{}, stack []byte)
+
+// Description:
+//
+//	Recovers from a panic in request transformation or a handler.
+//	Logs the panic with its stack trace and responds with a JSON 500 body.
+//	Must be deferred.
+//
+// Parameters:
+//
+//	requestID 		The id of the request.
+//	requestLogger 	The request scoped logger.
+//	request 		A pointer to the transformed request, which may be nil.
+//	context 		The internal gin context.
+//	hooks 			The registered panic hooks.
+func recoverPanic(requestID string, requestLogger *logging.Logger, request **api.APIRequest, context *gin.Context, hooks []PanicHook) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	// http.ErrAbortHandler is used to deliberately abort a response and must not be recovered.
+	if recovered == http.ErrAbortHandler {
+		panic(recovered)
+	}
+
+	stack := debug.Stack()
+	requestLogger.Log(logging.LevelError, "recovered from panic",
+		logging.F("panic", fmt.Sprintf("%v", recovered)),
+		logging.F("stack", string(stack)),
+	)
+
+	for _, hook := range hooks {
+		hook(*request, recovered, stack)
+	}
+
+	if context.Writer.Written() {
+		context.Abort()
+		return
+	}
+
+	context.AbortWithStatusJSON(http.StatusInternalServerError, internalErrorBody(requestID))
+}
+
+// Description:
+//
+//	Creates the error response body for internal server errors.
+//
+// Parameters:
+//
+//	requestID The id of the failed request.
+//
+// Returns:
+//
+//	The error response body.
+func internalErrorBody(requestID string) api.ErrorResponseBody {
+	return api.ErrorResponseBody{
+		Message:   "internal server error",
+		RequestID: requestID,
+	}
+}
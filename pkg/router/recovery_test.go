@@ -0,0 +1,85 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gostream-official/albums/pkg/api"
+)
+
+func TestPanicsAreRecovered(t *testing.T) {
+	engine := New(DefaultConfig())
+
+	var hookRequest *api.APIRequest
+	var hookRecovered interface{}
+	var hookStack []byte
+
+	engine.OnPanic(func(request *api.APIRequest, recovered interface{}, stack []byte) {
+		hookRequest = request
+		hookRecovered = recovered
+		hookStack = stack
+	})
+
+	engine.Handle(http.MethodGet, "/panic", func(request *api.APIRequest) *api.APIResponse {
+		panic("handler failed")
+	})
+
+	engine.Handle(http.MethodGet, "/items", func(request *api.APIRequest) *api.APIResponse {
+		return &api.APIResponse{StatusCode: http.StatusOK, Body: []string{"a"}}
+	})
+
+	recorder := send(engine, http.MethodGet, "/panic", "", map[string]string{HeaderRequestID: "request-1"})
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", recorder.Code)
+	}
+
+	body := errorBody(t, recorder)
+	if body.RequestID != "request-1" || body.Message != "internal server error" {
+		t.Fatalf("expected an internal error body with the request id, got %+v", body)
+	}
+
+	if hookRecovered != "handler failed" || len(hookStack) == 0 {
+		t.Fatalf("expected the panic hook to receive the panic and stack, got %v", hookRecovered)
+	}
+
+	if hookRequest == nil || hookRequest.RequestID != "request-1" {
+		t.Fatalf("expected the panic hook to receive the request, got %+v", hookRequest)
+	}
+
+	recorder = send(engine, http.MethodGet, "/items", "", nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected the router to keep serving, got status %d", recorder.Code)
+	}
+}
+
+func TestPanicsInMiddlewareAreRecovered(t *testing.T) {
+	engine := New(DefaultConfig())
+	hooks := 0
+
+	engine.OnPanic(func(request *api.APIRequest, recovered interface{}, stack []byte) {
+		hooks++
+	})
+
+	group := engine.Group("/albums", func(request *api.APIRequest, next RouterHandlerFunc) *api.APIResponse {
+		panic("middleware failed")
+	})
+
+	group.Handle(http.MethodGet, "", func(request *api.APIRequest) *api.APIResponse {
+		return &api.APIResponse{StatusCode: http.StatusOK}
+	})
+
+	for index := 0; index < 2; index++ {
+		recorder := send(engine, http.MethodGet, "/albums", "", map[string]string{HeaderRequestID: "request-2"})
+		if recorder.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status 500, got %d", recorder.Code)
+		}
+
+		if body := errorBody(t, recorder); body.RequestID != "request-2" {
+			t.Fatalf("expected the request id in the error body, got %+v", body)
+		}
+	}
+
+	if hooks != 2 {
+		t.Fatalf("expected the panic hook to fire for every panic, fired %d times", hooks)
+	}
+}
@@ -0,0 +1,142 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"github.com/gostream-official/albums/pkg/parallel"
+)
+
+const (
+
+	// The header holding the request id.
+	HeaderRequestID = "X-Request-ID"
+
+	// The header holding the W3C trace context.
+	HeaderTraceParent = "traceparent"
+
+	// The maximum length of accepted request ids.
+	maxRequestIDLength = 128
+)
+
+// Description:
+//
+//	Resolves the id of an incoming request.
+//	Uses the X-Request-ID header if it holds a valid id, the trace id of the traceparent header
+//	otherwise, and generates a new id if neither is present.
+//
+// Parameters:
+//
+//	request The incoming request.
+//
+// Returns:
+//
+//	The request id and the parsed trace context, which may be nil.
+func resolveRequestID(request *http.Request) (string, *api.TraceContext) {
+	trace := parseTraceParent(request.Header.Get(HeaderTraceParent))
+	requestID := request.Header.Get(HeaderRequestID)
+
+	if isValidRequestID(requestID) {
+		return requestID, trace
+	}
+
+	if trace != nil {
+		return trace.TraceID, trace
+	}
+
+	return parallel.NewID(), trace
+}
+
+// Description:
+//
+//	Checks whether a client provided request id is safe to use in logs and headers.
+//
+// Parameters:
+//
+//	id The request id.
+//
+// Returns:
+//
+//	True, if the id is valid.
+func isValidRequestID(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, char := range id {
+		isAlphanumeric := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+
+		if !isAlphanumeric && !strings.ContainsRune("-_.:+/=~", char) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Description:
+//
+//	Parses a W3C traceparent header.
+//
+// Parameters:
+//
+//	header The header value.
+//
+// Returns:
+//
+//	The parsed trace context, or nil if the header is absent or invalid.
+func parseTraceParent(header string) *api.TraceContext {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+
+	if len(parts) < 4 || !isHex(parts[0], 2) || parts[0] == "ff" {
+		return nil
+	}
+
+	// Version 00 defines exactly four fields, future versions may append more.
+	if parts[0] == "00" && len(parts) != 4 {
+		return nil
+	}
+
+	traceID, parentID, flags := parts[1], parts[2], parts[3]
+
+	if !isHex(traceID, 32) || !isHex(parentID, 16) || !isHex(flags, 2) {
+		return nil
+	}
+
+	if strings.Trim(traceID, "0") == "" || strings.Trim(parentID, "0") == "" {
+		return nil
+	}
+
+	return &api.TraceContext{
+		TraceID:  traceID,
+		ParentID: parentID,
+		Flags:    flags,
+	}
+}
+
+// Description:
+//
+//	Checks whether a string consists of lowercase hex characters of the given length.
+//
+// Parameters:
+//
+//	value 	The string to check.
+//	length 	The expected length.
+//
+// Returns:
+//
+//	True, if the string is valid.
+func isHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+
+	for _, char := range value {
+		if !(char >= '0' && char <= '9') && !(char >= 'a' && char <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,102 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gostream-official/albums/pkg/api"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const parentID = "00f067aa0ba902b7"
+
+	tests := []struct {
+		name   string
+		header string
+		valid  bool
+	}{
+		{name: "valid", header: "00-" + traceID + "-" + parentID + "-01", valid: true},
+		{name: "surrounding whitespace", header: " 00-" + traceID + "-" + parentID + "-00 ", valid: true},
+		{name: "future version with more fields", header: "01-" + traceID + "-" + parentID + "-01-extra", valid: true},
+		{name: "empty", header: ""},
+		{name: "missing fields", header: "00-" + traceID + "-" + parentID},
+		{name: "version 00 with more fields", header: "00-" + traceID + "-" + parentID + "-01-extra"},
+		{name: "forbidden version", header: "ff-" + traceID + "-" + parentID + "-01"},
+		{name: "malformed version", header: "zz-" + traceID + "-" + parentID + "-01"},
+		{name: "long version", header: "000-" + traceID + "-" + parentID + "-01"},
+		{name: "all zero trace id", header: "00-" + strings.Repeat("0", 32) + "-" + parentID + "-01"},
+		{name: "all zero parent id", header: "00-" + traceID + "-" + strings.Repeat("0", 16) + "-01"},
+		{name: "uppercase trace id", header: "00-" + strings.ToUpper(traceID) + "-" + parentID + "-01"},
+		{name: "short trace id", header: "00-" + traceID[1:] + "-" + parentID + "-01"},
+		{name: "short parent id", header: "00-" + traceID + "-" + parentID[1:] + "-01"},
+		{name: "malformed flags", header: "00-" + traceID + "-" + parentID + "-0x"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trace := parseTraceParent(test.header)
+
+			if !test.valid {
+				if trace != nil {
+					t.Errorf("expected %q to be rejected, got %+v", test.header, trace)
+				}
+
+				return
+			}
+
+			if trace == nil {
+				t.Fatalf("expected %q to be parsed", test.header)
+			}
+
+			if trace.TraceID != traceID || trace.ParentID != parentID {
+				t.Errorf("expected trace %s and parent %s, got %+v", traceID, parentID, trace)
+			}
+		})
+	}
+}
+
+func TestRequestIDs(t *testing.T) {
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name      string
+		headers   map[string]string
+		requestID string
+	}{
+		{name: "inbound request id", headers: map[string]string{HeaderRequestID: "client-42"}, requestID: "client-42"},
+		{name: "inbound request id before trace", headers: map[string]string{HeaderRequestID: "client-42", HeaderTraceParent: traceParent}, requestID: "client-42"},
+		{name: "trace id", headers: map[string]string{HeaderTraceParent: traceParent}, requestID: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{name: "invalid request id falls back to the trace id", headers: map[string]string{HeaderRequestID: "<script>", HeaderTraceParent: traceParent}, requestID: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{name: "overlong request id", headers: map[string]string{HeaderRequestID: strings.Repeat("a", maxRequestIDLength+1)}},
+		{name: "generated", headers: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handled := ""
+
+			engine := New(DefaultConfig())
+			engine.Handle(http.MethodGet, "/albums", func(request *api.APIRequest) *api.APIResponse {
+				handled = request.RequestID
+				return api.Text(http.StatusOK, "albums")
+			})
+
+			recorder := send(engine, http.MethodGet, "/albums", "", test.headers)
+			echoed := recorder.Header().Get(HeaderRequestID)
+
+			if echoed != handled {
+				t.Errorf("expected the handled request id %q to be echoed, got %q", handled, echoed)
+			}
+
+			if test.requestID != "" && echoed != test.requestID {
+				t.Errorf("expected request id %q, got %q", test.requestID, echoed)
+			}
+
+			if test.requestID == "" && (!isValidRequestID(echoed) || echoed == test.headers[HeaderRequestID]) {
+				t.Errorf("expected a generated request id, got %q", echoed)
+			}
+		})
+	}
+}
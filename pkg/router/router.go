@@ -1,6 +1,10 @@
 package router
 
-import "github.com/gostream-official/albums/pkg/api"
+import (
+	"net/http"
+
+	"github.com/gostream-official/albums/pkg/api"
+)
 
 // Description:
 //
@@ -9,33 +13,44 @@ type RouterHandlerFunc = func(request *api.APIRequest) *api.APIResponse
 
 // Description:
 //
-//	Function definition for router endpoint handlers, which support object injection.
-type RouterInjectionHandlerFunc = func(request *api.APIRequest, injector interface{}) *api.APIResponse
+//	Function definition for router endpoint handlers, which receive a typed dependency.
+//
+// Type Parameters:
+//
+//	T The type of the injected dependency.
+type RouterInjectionHandlerFunc[T any] func(request *api.APIRequest, dependency T) *api.APIResponse
 
 // Description:
 //
-//	The router interface.
-type Router interface {
+//	Anything routes can be registered with, i.e. a router or a route group.
+type Registrar interface {
 
 	// Description:
 	//
 	//	Registers a new HTTP handler function for the given method and path.
-	//	Paths can include wildcards and path variables.
 	//
 	// Parameters:
 	//
 	//	method 	The http method to handle.
 	//	path   	The path to handle.
 	//	handler	The handler responsible for handling the request.
-	Handle(method string, path string, handler RouterHandlerFunc)
+	//
+	// Returns:
+	//
+	//	The registered route.
+	Handle(method string, path string, handler RouterHandlerFunc) *Route
+}
+
+// Description:
+//
+//	The router interface.
+type Router interface {
 
 	// Description:
 	//
 	//	Registers a new HTTP handler function for the given method and path.
 	//	Paths can include wildcards and path variables.
 	//
-	//	This method allows object injection for the router handler.
-	//
 	// Parameters:
 	//
 	//	method 	The http method to handle.
@@ -44,47 +59,165 @@ type Router interface {
 	//
 	// Returns:
 	//
-	//	The router injector which allows object injection for the registered endpoint.
-	HandleWith(method string, path string, handler RouterInjectionHandlerFunc) *RouterInjector
+	//	The registered route, which allows adding route middleware.
+	Handle(method string, path string, handler RouterHandlerFunc) *Route
+
+	// Description:
+	//
+	//	Adds global middleware, which applies to all routes.
+	//
+	// Parameters:
+	//
+	//	middleware The middleware to add.
+	Use(middleware ...Middleware)
+
+	// Description:
+	//
+	//	Creates a route group with the given path prefix.
+	//
+	// Parameters:
+	//
+	//	prefix 		The path prefix.
+	//	middleware 	The group middleware.
+	//
+	// Returns:
+	//
+	//	The created group.
+	Group(prefix string, middleware ...Middleware) *RouteGroup
+
+	// Description:
+	//
+	//	Registers an observer which is called once per completed request,
+	//	after the response was written. Used for access logs and metrics.
+	//
+	// Parameters:
+	//
+	//	observer The observer to register.
+	Observe(observer Observer)
+
+	// Description:
+	//
+	//	Registers a hook which is called for every panic recovered by the router.
+	//	Useful for tests asserting that no panic escapes a handler.
+	//
+	// Parameters:
+	//
+	//	hook The panic hook to register.
+	OnPanic(hook PanicHook)
+
+	// Description:
+	//
+	//	Registers a hook which is executed during graceful shutdown,
+	//	after all in-flight requests were drained.
+	//	Hooks are executed in reverse order of registration.
+	//
+	// Parameters:
+	//
+	//	hook The shutdown hook to register.
+	OnShutdown(hook ShutdownHook)
+
+	// Description:
+	//
+	//	Registers a hook which is called as soon as a shutdown signal is received,
+	//	before the drain delay elapses and connections are drained.
+	//	Used to fail readiness checks, so that no new traffic is routed to the service.
+	//
+	// Parameters:
+	//
+	//	hook The drain hook to register.
+	OnDrain(hook DrainHook)
 
 	// Description:
 	//
 	//	Starts the HTTP server for this router and listens to all registered routes.
+	//	Serves all configured listeners, each with the same timeouts.
+	//	Blocks until a listener fails or a SIGINT or SIGTERM signal is received.
+	//	On signal, the server stops accepting connections, drains in-flight requests
+	//	and runs all shutdown hooks within the configured shutdown timeout.
+	//
+	// Parameters:
+	//
+	//	config The server configuration.
 	//
 	// Returns:
 	//
-	//	An error if serving the router fails.
-	Run(port uint16) error
-}
+	//	An error if serving the router fails or the shutdown is not graceful.
+	Run(config ServerConfig) error
 
-// Description:
-//
-//	The router injector.
-//	Responsible for object injection for route handlers.
-type RouterInjector struct {
+	// Description:
+	//
+	//	Gets the HTTP handler serving all registered routes.
+	//	Can be used to serve this router on a listener of another router.
+	//
+	// Returns:
+	//
+	//	The HTTP handler.
+	Handler() http.Handler
 
-	// The object to inject.
-	Injector interface{}
+	// Description:
+	//
+	//	Gets all registered routes, in order of registration.
+	//	Used to generate API specifications from the route documentation.
+	//
+	// Returns:
+	//
+	//	The registered routes.
+	Routes() []*Route
 }
 
 // Description:
 //
-//	Creates the default router.
+//	Creates the default router with the default configuration.
 //
 // Returns:
 //
 //	The default router.
 func Default() Router {
-	return NewGinRouter()
+	return New(DefaultConfig())
+}
+
+// Description:
+//
+//	Creates the default router with the given configuration.
+//
+// Parameters:
+//
+//	config The router configuration.
+//
+// Returns:
+//
+//	The created router.
+func New(config Config) Router {
+	return NewGinRouter(config)
 }
 
 // Description:
 //
-//	Injects the given object to the endpoint this method is called on.
+//	Registers a new HTTP handler function for the given method and path,
+//	which receives the given dependency on every request.
+//	The dependency type is checked at compile time.
+//
+// Example:
+//
+//	router.HandleWith(engine, "GET", "/albums/:id", getalbum.Handler, injector)
 //
 // Parameters:
 //
-//	object The object to inject.
-func (handler *RouterInjector) Inject(object interface{}) {
-	handler.Injector = object
+//	registrar 	The router or route group to register the route with.
+//	method 		The http method to handle.
+//	path 		The path to handle.
+//	handler 	The handler responsible for handling the request.
+//	dependency 	The dependency passed to the handler.
+//
+// Type Parameters:
+//
+//	T The type of the injected dependency.
+//
+// Returns:
+//
+//	The registered route, which allows adding route middleware.
+func HandleWith[T any](registrar Registrar, method string, path string, handler RouterInjectionHandlerFunc[T], dependency T) *Route {
+	return registrar.Handle(method, path, func(request *api.APIRequest) *api.APIResponse {
+		return handler(request, dependency)
+	})
 }
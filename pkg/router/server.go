@@ -0,0 +1,268 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/logging"
+)
+
+// Description:
+//
+//	Function definition for shutdown hooks.
+//	Shutdown hooks are executed after the HTTP server stopped accepting connections
+//	and all in-flight requests were drained.
+type ShutdownHook = func(ctx context.Context) error
+
+// Description:
+//
+//	Function definition for drain hooks.
+//	Drain hooks are called when a shutdown signal is received, while all listeners still serve requests.
+type DrainHook = func()
+
+// Description:
+//
+//	The configuration of the HTTP server started by a router.
+//	Zero durations disable the respective timeout.
+type ServerConfig struct {
+
+	// The port to listen on.
+	// Only used if no listeners are configured.
+	Port uint16
+
+	// The listeners to serve on.
+	// If empty, a single TCP listener on all interfaces is created for the configured port.
+	Listeners []ListenerConfig
+
+	// The maximum duration for reading an entire request, including the body.
+	ReadTimeout time.Duration
+
+	// The maximum duration for reading the request headers.
+	ReadHeaderTimeout time.Duration
+
+	// The maximum duration before timing out writes of a response.
+	WriteTimeout time.Duration
+
+	// The maximum duration to wait for the next request on keep-alive connections.
+	IdleTimeout time.Duration
+
+	// The maximum duration for draining in-flight requests and running shutdown hooks.
+	ShutdownTimeout time.Duration
+
+	// The duration between receiving a shutdown signal and draining the listeners.
+	// Requests are still served meanwhile, giving load balancers time to observe failing readiness checks.
+	DrainDelay time.Duration
+
+	// The TLS configuration of the default listener. If nil, the server listens on plain HTTP.
+	// Only used if no listeners are configured.
+	TLS *TLSConfig
+}
+
+// Description:
+//
+//	A running HTTP server bound to a single listener.
+type boundServer struct {
+
+	// The listener configuration.
+	config ListenerConfig
+
+	// The network listener.
+	listener net.Listener
+
+	// The HTTP server.
+	server *http.Server
+}
+
+// Description:
+//
+//	Serves the given handler on all configured listeners until a SIGINT or SIGTERM signal is received,
+//	or any of the listeners fails. Drains all listeners and runs the shutdown hooks afterwards.
+//
+// Parameters:
+//
+//	config 		The server configuration.
+//	handler 	The handler served on listeners which do not specify their own router.
+//	drainHooks 	The drain hooks, called on signal before the drain delay.
+//	hooks 		The shutdown hooks, executed in reverse order.
+//
+// Returns:
+//
+//	An error if serving fails or the shutdown is not graceful.
+func serve(config ServerConfig, handler http.Handler, drainHooks []DrainHook, hooks []ShutdownHook) error {
+	listeners := config.Listeners
+
+	if len(listeners) == 0 {
+		listeners = []ListenerConfig{{
+			Name:    "default",
+			Address: fmt.Sprintf(":%d", config.Port),
+			TLS:     config.TLS,
+		}}
+	}
+
+	signalContext, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	servers := make([]*boundServer, 0, len(listeners))
+
+	closeAll := func() {
+		for _, bound := range servers {
+			bound.listener.Close()
+		}
+	}
+
+	for _, listenerConfig := range listeners {
+		bound, err := bind(signalContext, config, listenerConfig, handler)
+		if err != nil {
+			closeAll()
+			return err
+		}
+
+		servers = append(servers, bound)
+	}
+
+	serveErr := make(chan error, len(servers))
+
+	for _, bound := range servers {
+		logger.Log(logging.LevelInfo, "listening", logging.F("address", bound.listener.Addr().String()), logging.F("listener", bound.config.Name))
+
+		go func(bound *boundServer) {
+			var err error
+
+			if bound.server.TLSConfig != nil {
+				err = bound.server.ServeTLS(bound.listener, "", "")
+			} else {
+				err = bound.server.Serve(bound.listener)
+			}
+
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serveErr <- fmt.Errorf("router: listener %s failed: %w", bound.config.Name, err)
+			}
+		}(bound)
+	}
+
+	errs := make([]error, 0)
+
+	select {
+	case err := <-serveErr:
+		errs = append(errs, err)
+	case <-signalContext.Done():
+		logger.Infof("received shutdown signal, draining connections ...")
+	}
+
+	stop()
+
+	for _, hook := range drainHooks {
+		hook()
+	}
+
+	if len(errs) == 0 && config.DrainDelay > 0 {
+		logger.Infof("delaying connection draining by %s ...", config.DrainDelay)
+
+		select {
+		case err := <-serveErr:
+			errs = append(errs, err)
+		case <-time.After(config.DrainDelay):
+		}
+	}
+
+	shutdownContext := context.Background()
+
+	if config.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		shutdownContext, cancel = context.WithTimeout(shutdownContext, config.ShutdownTimeout)
+		defer cancel()
+	}
+
+	var group sync.WaitGroup
+	var mutex sync.Mutex
+
+	for _, bound := range servers {
+		group.Add(1)
+
+		go func(bound *boundServer) {
+			defer group.Done()
+
+			err := bound.server.Shutdown(shutdownContext)
+			if err != nil {
+				mutex.Lock()
+				errs = append(errs, fmt.Errorf("router: failed to drain listener %s: %w", bound.config.Name, err))
+				mutex.Unlock()
+			}
+		}(bound)
+	}
+
+	group.Wait()
+
+	for index := len(hooks) - 1; index >= 0; index-- {
+		err := hooks[index](shutdownContext)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("router: shutdown hook failed: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Description:
+//
+//	Binds a listener and creates the HTTP server serving it.
+//
+// Parameters:
+//
+//	ctx 			The context controlling the lifetime of background tasks, e.g. certificate reloading.
+//	config 			The server configuration.
+//	listenerConfig 	The listener configuration.
+//	handler 		The handler served if the listener does not specify its own router.
+//
+// Returns:
+//
+//	The bound server, or an error if binding fails.
+func bind(ctx context.Context, config ServerConfig, listenerConfig ListenerConfig, handler http.Handler) (*boundServer, error) {
+	if listenerConfig.Name == "" {
+		listenerConfig.Name = listenerConfig.Address
+	}
+
+	if listenerConfig.Router != nil {
+		handler = listenerConfig.Router.Handler()
+	}
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+	}
+
+	// Setting this to false apparently reduces memory usage.
+	// However, setting this to true apparently is the standard and improves performance.
+	server.SetKeepAlivesEnabled(true)
+
+	if listenerConfig.TLS != nil {
+		reloader, err := newCertificateReloader(*listenerConfig.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		server.TLSConfig = reloader.tlsConfig()
+		go reloader.watch(ctx)
+	}
+
+	listener, err := listen(listenerConfig.Address)
+	if err != nil {
+		return nil, fmt.Errorf("router: cannot listen on %s: %w", listenerConfig.Address, err)
+	}
+
+	return &boundServer{
+		config:   listenerConfig,
+		listener: listener,
+		server:   server,
+	}, nil
+}
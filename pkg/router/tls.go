@@ -0,0 +1,283 @@
+package router
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/api"
+)
+
+// Description:
+//
+//	The TLS configuration of the HTTP server started by a router.
+type TLSConfig struct {
+
+	// The path of the PEM encoded server certificate (chain).
+	CertFile string
+
+	// The path of the PEM encoded server private key.
+	KeyFile string
+
+	// The path of the PEM encoded certificate authorities used to verify client certificates.
+	// If set, clients must present a certificate signed by one of these authorities (mutual TLS).
+	ClientCAFile string
+
+	// The interval in which the certificate files are checked for changes.
+	// Changed files are reloaded without restarting the server. Zero disables hot reload.
+	ReloadInterval time.Duration
+}
+
+// Description:
+//
+//	Holds the currently active certificate material and reloads it on file change.
+type certificateReloader struct {
+
+	// The TLS configuration.
+	config TLSConfig
+
+	// Guards the fields below.
+	mutex sync.RWMutex
+
+	// The currently active server certificate.
+	certificate *tls.Certificate
+
+	// The currently active client certificate authorities.
+	clientCAs *x509.CertPool
+
+	// The modification times of the loaded files.
+	modTimes map[string]time.Time
+}
+
+// Description:
+//
+//	Creates a new certificate reloader and loads the initial certificate material.
+//
+// Parameters:
+//
+//	config The TLS configuration.
+//
+// Returns:
+//
+//	The created reloader, or an error if the certificate material cannot be loaded.
+func newCertificateReloader(config TLSConfig) (*certificateReloader, error) {
+	reloader := &certificateReloader{
+		config:   config,
+		modTimes: make(map[string]time.Time),
+	}
+
+	err := reloader.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+// Description:
+//
+//	Loads the certificate material from disk and replaces the active material.
+//
+// Returns:
+//
+//	An error if the certificate material cannot be loaded. The active material is kept in this case.
+func (reloader *certificateReloader) load() error {
+	modTimes := make(map[string]time.Time)
+
+	for _, path := range reloader.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("router: cannot stat certificate file: %s", err)
+		}
+
+		modTimes[path] = info.ModTime()
+	}
+
+	certificate, err := tls.LoadX509KeyPair(reloader.config.CertFile, reloader.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("router: cannot load server certificate: %s", err)
+	}
+
+	var clientCAs *x509.CertPool
+
+	if reloader.config.ClientCAFile != "" {
+		bytes, err := os.ReadFile(reloader.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("router: cannot read client certificate authorities: %s", err)
+		}
+
+		clientCAs = x509.NewCertPool()
+
+		if !clientCAs.AppendCertsFromPEM(bytes) {
+			return fmt.Errorf("router: no valid client certificate authorities found")
+		}
+	}
+
+	reloader.mutex.Lock()
+	defer reloader.mutex.Unlock()
+
+	reloader.certificate = &certificate
+	reloader.clientCAs = clientCAs
+	reloader.modTimes = modTimes
+
+	return nil
+}
+
+// Description:
+//
+//	Gets all files the certificate material is loaded from.
+//
+// Returns:
+//
+//	The file paths.
+func (reloader *certificateReloader) files() []string {
+	files := []string{reloader.config.CertFile, reloader.config.KeyFile}
+
+	if reloader.config.ClientCAFile != "" {
+		files = append(files, reloader.config.ClientCAFile)
+	}
+
+	return files
+}
+
+// Description:
+//
+//	Checks whether any of the certificate files changed since they were loaded.
+//
+// Returns:
+//
+//	True, if at least one file changed.
+func (reloader *certificateReloader) changed() bool {
+	reloader.mutex.RLock()
+	defer reloader.mutex.RUnlock()
+
+	for _, path := range reloader.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		if !info.ModTime().Equal(reloader.modTimes[path]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Description:
+//
+//	Periodically checks the certificate files for changes and reloads them.
+//	Blocks until the given context is done.
+//
+// Parameters:
+//
+//	ctx The context controlling the lifetime of the watcher.
+func (reloader *certificateReloader) watch(ctx context.Context) {
+	if reloader.config.ReloadInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(reloader.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !reloader.changed() {
+			continue
+		}
+
+		err := reloader.load()
+		if err != nil {
+			logger.Warnf("failed to reload certificates, keeping active certificates: %s", err)
+			continue
+		}
+
+		logger.Infof("reloaded certificates")
+	}
+}
+
+// Description:
+//
+//	Creates the server TLS configuration.
+//	The certificate and client certificate authorities are resolved per handshake,
+//	so that reloaded material is picked up by new connections.
+//
+// Returns:
+//
+//	The server TLS configuration.
+func (reloader *certificateReloader) tlsConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			reloader.mutex.RLock()
+			defer reloader.mutex.RUnlock()
+
+			return reloader.certificate, nil
+		},
+	}
+
+	if reloader.config.ClientCAFile == "" {
+		return base
+	}
+
+	base.ClientAuth = tls.RequireAndVerifyClientCert
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		reloader.mutex.RLock()
+		defer reloader.mutex.RUnlock()
+
+		config := base.Clone()
+		config.GetConfigForClient = nil
+		config.ClientCAs = reloader.clientCAs
+
+		return config, nil
+	}
+
+	return base
+}
+
+// Description:
+//
+//	Extracts the verified client identity from the TLS connection state.
+//
+// Parameters:
+//
+//	state The TLS connection state, may be nil.
+//
+// Returns:
+//
+//	The verified client identity, or nil if the client did not present a verified certificate.
+func extractClientIdentity(state *tls.ConnectionState) *api.ClientIdentity {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	certificate := state.VerifiedChains[0][0]
+	fingerprint := sha256.Sum256(certificate.Raw)
+
+	identity := &api.ClientIdentity{
+		CommonName:    certificate.Subject.CommonName,
+		Organizations: certificate.Subject.Organization,
+		DNSNames:      certificate.DNSNames,
+		URIs:          make([]string, 0, len(certificate.URIs)),
+		SerialNumber:  certificate.SerialNumber.String(),
+		Fingerprint:   hex.EncodeToString(fingerprint[:]),
+	}
+
+	for _, uri := range certificate.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+
+	return identity
+}
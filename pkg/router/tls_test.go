@@ -0,0 +1,323 @@
+package router
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/api"
+)
+
+// Description:
+//
+//	A certificate authority issuing test certificates.
+type testAuthority struct {
+
+	// The certificate of the authority.
+	certificate *x509.Certificate
+
+	// The private key of the authority.
+	key *ecdsa.PrivateKey
+
+	// The PEM encoded certificate of the authority.
+	pem []byte
+}
+
+// Description:
+//
+//	Creates a self-signed certificate authority.
+//
+// Parameters:
+//
+//	t 		The test.
+//	name 	The common name of the authority.
+//
+// Returns:
+//
+//	The created authority.
+func newTestAuthority(t *testing.T, name string) *testAuthority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	data, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate authority: %s", err)
+	}
+
+	certificate, _ := x509.ParseCertificate(data)
+
+	return &testAuthority{
+		certificate: certificate,
+		key:         key,
+		pem:         pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: data}),
+	}
+}
+
+// Description:
+//
+//	Issues a certificate for localhost.
+//
+// Parameters:
+//
+//	t 		The test.
+//	name 	The common name of the certificate.
+//	usage 	The extended key usage, i.e. server or client authentication.
+//
+// Returns:
+//
+//	The PEM encoded certificate and private key.
+func (authority *testAuthority) issue(t *testing.T, name string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+
+	data, err := x509.CreateCertificate(rand.Reader, template, authority.certificate, &key.PublicKey, authority.key)
+	if err != nil {
+		t.Fatalf("failed to issue certificate: %s", err)
+	}
+
+	keyData, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to encode key: %s", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: data}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyData})
+}
+
+// Description:
+//
+//	Writes a file, failing the test on error.
+//
+// Parameters:
+//
+//	t 		The test.
+//	path 	The path of the file.
+//	data 	The file content.
+func writeTestFile(t *testing.T, path string, data []byte) {
+	err := os.WriteFile(path, data, 0600)
+	if err != nil {
+		t.Fatalf("failed to write %s: %s", path, err)
+	}
+}
+
+// Description:
+//
+//	Serves the given handler via TLS on a local port until the test ends.
+//
+// Parameters:
+//
+//	t 		The test.
+//	config 	The TLS configuration.
+//	handler The handler.
+//
+// Returns:
+//
+//	The address of the server.
+func serveTLS(t *testing.T, config TLSConfig, handler http.Handler) string {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	bound, err := bind(ctx, ServerConfig{}, ListenerConfig{Name: "tls", Address: "127.0.0.1:0", TLS: &config}, handler)
+	if err != nil {
+		t.Fatalf("failed to bind: %s", err)
+	}
+
+	go bound.server.ServeTLS(bound.listener, "", "")
+
+	t.Cleanup(func() {
+		cancel()
+		bound.server.Close()
+	})
+
+	return bound.listener.Addr().String()
+}
+
+// Description:
+//
+//	Gets the common name of the certificate served at the given address.
+//
+// Parameters:
+//
+//	t 		The test.
+//	address The address of the server.
+//	roots 	The trusted certificate authorities.
+//
+// Returns:
+//
+//	The common name of the served certificate.
+func servedCommonName(t *testing.T, address string, roots *x509.CertPool) string {
+	connection, err := tls.Dial("tcp", address, &tls.Config{RootCAs: roots, ServerName: "localhost"})
+	if err != nil {
+		t.Fatalf("handshake failed: %s", err)
+	}
+
+	defer connection.Close()
+
+	return connection.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestCertificatesAreReloaded(t *testing.T) {
+	authority := newTestAuthority(t, "test ca")
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.certificate)
+
+	directory := t.TempDir()
+	config := TLSConfig{
+		CertFile:       filepath.Join(directory, "tls.crt"),
+		KeyFile:        filepath.Join(directory, "tls.key"),
+		ReloadInterval: 10 * time.Millisecond,
+	}
+
+	certificate, key := authority.issue(t, "first", x509.ExtKeyUsageServerAuth)
+	writeTestFile(t, config.CertFile, certificate)
+	writeTestFile(t, config.KeyFile, key)
+
+	address := serveTLS(t, config, http.NotFoundHandler())
+
+	if name := servedCommonName(t, address, roots); name != "first" {
+		t.Fatalf("expected certificate first, got %s", name)
+	}
+
+	certificate, key = authority.issue(t, "second", x509.ExtKeyUsageServerAuth)
+	writeTestFile(t, config.CertFile, certificate)
+	writeTestFile(t, config.KeyFile, key)
+
+	// Files may be rewritten within the resolution of modification times.
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(config.CertFile, future, future)
+	os.Chtimes(config.KeyFile, future, future)
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for servedCommonName(t, address, roots) != "second" {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the rewritten certificate to be served after the reload interval")
+		}
+
+		time.Sleep(config.ReloadInterval)
+	}
+}
+
+func TestClientCertificatesAreVerified(t *testing.T) {
+	authority := newTestAuthority(t, "test ca")
+	clientAuthority := newTestAuthority(t, "client ca")
+	unknownAuthority := newTestAuthority(t, "unknown ca")
+
+	directory := t.TempDir()
+	config := TLSConfig{
+		CertFile:     filepath.Join(directory, "tls.crt"),
+		KeyFile:      filepath.Join(directory, "tls.key"),
+		ClientCAFile: filepath.Join(directory, "ca.crt"),
+	}
+
+	certificate, key := authority.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	writeTestFile(t, config.CertFile, certificate)
+	writeTestFile(t, config.KeyFile, key)
+	writeTestFile(t, config.ClientCAFile, clientAuthority.pem)
+
+	engine := New(DefaultConfig())
+	engine.Handle(http.MethodGet, "/whoami", func(request *api.APIRequest) *api.APIResponse {
+		if request.Client == nil {
+			return api.Text(http.StatusUnauthorized, "anonymous")
+		}
+
+		return api.Text(http.StatusOK, request.Client.CommonName)
+	})
+
+	address := serveTLS(t, config, engine.Handler())
+
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.certificate)
+
+	tests := []struct {
+		name      string
+		authority *testAuthority
+		accepted  bool
+	}{
+		{name: "trusted client", authority: clientAuthority, accepted: true},
+		{name: "untrusted client", authority: unknownAuthority},
+		{name: "server authority", authority: authority},
+		{name: "no client certificate"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tlsConfig := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+
+			if test.authority != nil {
+				certificate, key := test.authority.issue(t, "client", x509.ExtKeyUsageClientAuth)
+
+				pair, err := tls.X509KeyPair(certificate, key)
+				if err != nil {
+					t.Fatalf("failed to load client certificate: %s", err)
+				}
+
+				tlsConfig.Certificates = []tls.Certificate{pair}
+			}
+
+			client := &http.Client{
+				Timeout:   5 * time.Second,
+				Transport: &http.Transport{TLSClientConfig: tlsConfig},
+			}
+
+			response, err := client.Get("https://" + address + "/whoami")
+
+			if !test.accepted {
+				if err == nil {
+					response.Body.Close()
+					t.Fatalf("expected the client certificate to be rejected, got status %d", response.StatusCode)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("request failed: %s", err)
+			}
+
+			defer response.Body.Close()
+			body, _ := io.ReadAll(response.Body)
+
+			if response.StatusCode != http.StatusOK || string(body) != "client" {
+				t.Errorf("expected the client identity, got status %d: %s", response.StatusCode, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gostream-official/albums/pkg/logging"
+	"github.com/gostream-official/albums/pkg/redact"
+	"github.com/gostream-official/albums/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Description:
+//
+//	Starts the server span of a request, continuing the incoming W3C trace context.
+//	The span is attached to the context of the gin request, so that it is the parent
+//	of all spans created by handlers and stores. Sensitive query parameters of the target are redacted,
+//	as spans are exported to third-party backends.
+//
+// Parameters:
+//
+//	context 	The gin context.
+//	requestID 	The id of the request.
+//	policy 		The redaction policy of the router.
+//
+// Returns:
+//
+//	The started span.
+func startServerSpan(context *gin.Context, requestID string, policy redact.Policy) trace.Span {
+	request := context.Request
+	ctx := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+
+	name := "HTTP " + request.Method
+	route := context.FullPath()
+
+	if route != "" {
+		name = request.Method + " " + route
+	}
+
+	ctx, span := tracing.Tracer("router").Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.method", request.Method),
+			attribute.String("http.route", route),
+			attribute.String("http.target", policy.RedactURI(request.URL.RequestURI())),
+			attribute.String("http.request_id", requestID),
+		),
+	)
+
+	context.Request = request.WithContext(ctx)
+	return span
+}
+
+// Description:
+//
+//	Ends the server span of a request.
+//	Server errors mark the span as failed.
+//
+// Parameters:
+//
+//	span 	The server span.
+//	status 	The response status code.
+func endServerSpan(span trace.Span, status int) {
+	span.SetAttributes(attribute.Int("http.status_code", status))
+
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+
+	span.End()
+}
+
+// Description:
+//
+//	Gets the logging fields correlating log entries with the current span.
+//
+// Parameters:
+//
+//	span The current span.
+//
+// Returns:
+//
+//	The trace and span id fields, or no fields if the span is not recorded.
+func spanFields(span trace.Span) []logging.Field {
+	spanContext := span.SpanContext()
+
+	if !spanContext.IsValid() || !span.IsRecording() {
+		return nil
+	}
+
+	return []logging.Field{
+		logging.F("traceId", spanContext.TraceID().String()),
+		logging.F("spanId", spanContext.SpanID().String()),
+	}
+}
@@ -0,0 +1,43 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gostream-official/albums/pkg/api"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestServerSpansRedactQueryParameters(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	engine := New(DefaultConfig())
+	engine.Handle(http.MethodGet, "/albums", func(request *api.APIRequest) *api.APIResponse {
+		return &api.APIResponse{StatusCode: http.StatusOK, Body: []string{}}
+	})
+
+	send(engine, http.MethodGet, "/albums?limit=5&access_token=secret", "", nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected one server span, got %d", len(spans))
+	}
+
+	for _, attribute := range spans[0].Attributes() {
+		if attribute.Key == "http.target" {
+			if target := attribute.Value.AsString(); target != "/albums?limit=5&access_token=[REDACTED]" {
+				t.Fatalf("expected a redacted target, got %s", target)
+			}
+
+			return
+		}
+	}
+
+	t.Fatalf("expected an http.target attribute")
+}
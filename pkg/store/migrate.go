@@ -0,0 +1,172 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+
+	// The collection recording applied migrations.
+	migrationsCollection = "migrations"
+)
+
+// Description:
+//
+//	A schema or data migration of a database.
+//	Migrations are applied once, in increasing order of their versions.
+type Migration struct {
+
+	// The version of the migration. Must be unique and greater than zero.
+	Version int
+
+	// A short description of the migration.
+	Description string
+
+	// Applies the migration. Must be idempotent, as a failed run is retried on the next start.
+	Apply func(ctx context.Context, database *mongo.Database) error
+}
+
+// Description:
+//
+//	The record of an applied migration.
+type migrationRecord struct {
+
+	// The version of the migration.
+	Version int `bson:"_id"`
+
+	// The description of the migration.
+	Description string `bson:"description"`
+
+	// The time the migration was applied.
+	AppliedAt time.Time `bson:"appliedAt"`
+}
+
+// Description:
+//
+//	Applies all migrations of the given database which were not applied yet.
+//	Each applied migration is recorded in the migrations collection.
+//	Safe to run from several replicas at once, as migrations are idempotent
+//	and a migration recorded by another replica is treated as applied.
+//
+// Parameters:
+//
+//	ctx 		The context bounding the migrations.
+//	database 	The name of the database to migrate.
+//	migrations 	The known migrations, in any order.
+//
+// Returns:
+//
+//	An error if a migration fails.
+func (instance *MongoInstance) Migrate(ctx context.Context, database string, migrations []Migration) error {
+	databaseRef := instance.Client.Database(database)
+
+	current, err := instance.MigrationVersion(ctx, database)
+	if err != nil {
+		return err
+	}
+
+	sorted := append([]Migration{}, migrations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for _, migration := range sorted {
+		if migration.Version <= current {
+			continue
+		}
+
+		err := migration.Apply(ctx, databaseRef)
+		if err != nil {
+			return fmt.Errorf("store: migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+
+		_, err = databaseRef.Collection(migrationsCollection).InsertOne(ctx, migrationRecord{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now().UTC(),
+		})
+
+		// Replicas starting concurrently may apply the same migration; as migrations are
+		// idempotent, a record inserted by another replica means the migration is applied.
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("store: cannot record migration %d: %w", migration.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// Description:
+//
+//	Gets the version of the latest applied migration of the given database.
+//
+// Parameters:
+//
+//	ctx 		The request context.
+//	database 	The name of the database.
+//
+// Returns:
+//
+//	The version, zero if no migration was applied, or an error if the query fails.
+func (instance *MongoInstance) MigrationVersion(ctx context.Context, database string) (int, error) {
+	collection := instance.Client.Database(database).Collection(migrationsCollection)
+	options := options.FindOne().SetSort(bson.M{"_id": -1})
+
+	var record migrationRecord
+
+	err := collection.FindOne(ctx, bson.M{}, options).Decode(&record)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("store: cannot read migration version: %w", err)
+	}
+
+	return record.Version, nil
+}
+
+// Description:
+//
+//	Checks whether all given migrations were applied to the given database.
+//
+// Parameters:
+//
+//	ctx 		The request context.
+//	database 	The name of the database.
+//	migrations 	The known migrations.
+//
+// Returns:
+//
+//	An error if a migration is pending or the version cannot be read.
+func (instance *MongoInstance) CheckMigrations(ctx context.Context, database string, migrations []Migration) error {
+	latest := 0
+
+	for _, migration := range migrations {
+		if migration.Version > latest {
+			latest = migration.Version
+		}
+	}
+
+	current, err := instance.MigrationVersion(ctx, database)
+	if err != nil {
+		return err
+	}
+
+	if current < latest {
+		return fmt.Errorf("store: migrations pending, database is at version %d of %d", current, latest)
+	}
+
+	return nil
+}
@@ -2,11 +2,17 @@ package store
 
 import (
 	"context"
+	"time"
 
+	"github.com/gostream-official/albums/pkg/parallel"
 	"github.com/gostream-official/albums/pkg/store/query"
+	"github.com/gostream-official/albums/pkg/tracing"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Description:
@@ -17,6 +23,12 @@ type MongoInstance struct {
 
 	// The MongoDB client.
 	Client *mongo.Client
+
+	// The registered operation observers.
+	observers []OperationObserver
+
+	// The connection pool counters.
+	pool *poolCounters
 }
 
 // Description:
@@ -27,6 +39,9 @@ type MongoStore[T interface{}] struct {
 
 	// The MongoDB collection.
 	Collection *mongo.Collection
+
+	// The mongo instance, used for notifying operation observers.
+	instance *MongoInstance
 }
 
 // Description:
@@ -43,7 +58,8 @@ type MongoStore[T interface{}] struct {
 //	The created mongo instance, or an error, if the connection fails.
 func NewMongoInstance(uri string) (*MongoInstance, error) {
 	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
-	opts := options.Client().ApplyURI(uri).SetServerAPIOptions(serverAPI)
+	pool := &poolCounters{}
+	opts := options.Client().ApplyURI(uri).SetServerAPIOptions(serverAPI).SetPoolMonitor(newPoolMonitor(pool))
 
 	ctx := context.Background()
 	client, err := mongo.Connect(ctx, opts)
@@ -59,9 +75,41 @@ func NewMongoInstance(uri string) (*MongoInstance, error) {
 
 	return &MongoInstance{
 		Client: client,
+		pool:   pool,
 	}, nil
 }
 
+// Description:
+//
+//	Disconnects the mongo instance.
+//	Closes all idle connections and waits for in-use connections to be returned.
+//
+// Parameters:
+//
+//	ctx The context bounding the disconnect.
+//
+// Returns:
+//
+//	An error if disconnecting fails.
+func (instance *MongoInstance) Disconnect(ctx context.Context) error {
+	return instance.Client.Disconnect(ctx)
+}
+
+// Description:
+//
+//	Checks whether the primary of the mongo instance is reachable.
+//
+// Parameters:
+//
+//	ctx The context bounding the ping.
+//
+// Returns:
+//
+//	An error if the primary cannot be reached.
+func (instance *MongoInstance) Ping(ctx context.Context) error {
+	return instance.Client.Ping(ctx, readpref.Primary())
+}
+
 // Description:
 //
 //	Creates a new mongo store.
@@ -85,6 +133,7 @@ func NewMongoStore[T interface{}](instance *MongoInstance, database string, coll
 
 	return &MongoStore[T]{
 		Collection: collectionRef,
+		instance:   instance,
 	}
 }
 
@@ -94,14 +143,23 @@ func NewMongoStore[T interface{}](instance *MongoInstance, database string, coll
 //
 // Parameters:
 //
-//	item The item to create.
+//	ctx 	The request context.
+//	item 	The item to create.
 //
 // Returns:
 //
 //	An error if creation fails.
-func (store *MongoStore[T]) CreateItem(item interface{}) error {
-	ctx := context.Background()
-	_, err := store.Collection.InsertOne(ctx, item)
+func (store *MongoStore[T]) CreateItem(ctx context.Context, item interface{}) error {
+	options := options.InsertOne()
+
+	comment := requestComment(ctx)
+	if comment != "" {
+		options.SetComment(comment)
+	}
+
+	ctx, finish := store.begin(ctx, "insert")
+	_, err := store.Collection.InsertOne(ctx, item, options)
+	finish(err)
 
 	if err != nil {
 		return err
@@ -116,14 +174,15 @@ func (store *MongoStore[T]) CreateItem(item interface{}) error {
 //
 // Parameters:
 //
-//	filter The filter used for searching the documents to update.
-//	update The update operator used for updating the filtered documents.
+//	ctx 	The request context.
+//	filter 	The filter used for searching the documents to update.
+//	update 	The update operator used for updating the filtered documents.
 //
 // Returns:
 //
 //	The number of modified documents.
 //	An error if the update fails.
-func (store *MongoStore[T]) UpdateItem(filter *query.Filter, update *query.Update) (int64, error) {
+func (store *MongoStore[T]) UpdateItem(ctx context.Context, filter *query.Filter, update *query.Update) (int64, error) {
 	var query bson.M
 	var updateQuery bson.M
 
@@ -139,8 +198,16 @@ func (store *MongoStore[T]) UpdateItem(filter *query.Filter, update *query.Updat
 		updateQuery = update.Root.Compile()
 	}
 
-	ctx := context.Background()
-	result, err := store.Collection.UpdateOne(ctx, query, updateQuery)
+	options := options.Update()
+
+	comment := requestComment(ctx)
+	if comment != "" {
+		options.SetComment(comment)
+	}
+
+	ctx, finish := store.begin(ctx, "update")
+	result, err := store.Collection.UpdateOne(ctx, query, updateQuery, options)
+	finish(err)
 
 	if err != nil {
 		return 0, err
@@ -155,13 +222,14 @@ func (store *MongoStore[T]) UpdateItem(filter *query.Filter, update *query.Updat
 //
 // Parameters:
 //
-//	The query filter to use.
+//	ctx 	The request context.
+//	filter 	The query filter to use.
 //
 // Returns:
 //
 //	An array of all items matching the given query filter.
 //	An error if the query fails.
-func (store *MongoStore[T]) FindItems(filter *query.Filter) ([]T, error) {
+func (store *MongoStore[T]) FindItems(ctx context.Context, filter *query.Filter) ([]T, error) {
 	items := make([]T, 0)
 
 	var query bson.M
@@ -172,11 +240,18 @@ func (store *MongoStore[T]) FindItems(filter *query.Filter) ([]T, error) {
 		query = filter.Root.Compile()
 	}
 
-	ctx := context.Background()
 	options := options.Find().SetLimit(int64(filter.Limit))
 
+	comment := requestComment(ctx)
+	if comment != "" {
+		options.SetComment(comment)
+	}
+
+	ctx, finish := store.begin(ctx, "find")
+
 	cursor, err := store.Collection.Find(ctx, query, options)
 	if err != nil {
+		finish(err)
 		return nil, err
 	}
 
@@ -187,12 +262,20 @@ func (store *MongoStore[T]) FindItems(filter *query.Filter) ([]T, error) {
 		err := cursor.Decode(&item)
 
 		if err != nil {
+			finish(err)
 			return nil, err
 		}
 
 		items = append(items, item)
 	}
 
+	err = cursor.Err()
+	finish(err)
+
+	if err != nil {
+		return nil, err
+	}
+
 	return items, nil
 }
 
@@ -202,18 +285,26 @@ func (store *MongoStore[T]) FindItems(filter *query.Filter) ([]T, error) {
 //
 // Parameters:
 //
-//	The ID of the document to delete.
+//	ctx The request context.
+//	id 	The ID of the document to delete.
 //
 // Returns:
 //
 //	The number of deleted documents.
 //	An error if the request fails.
-func (store MongoStore[T]) DeleteItem(id string) (int64, error) {
-	ctx := context.Background()
+func (store MongoStore[T]) DeleteItem(ctx context.Context, id string) (int64, error) {
+	options := options.Delete()
+
+	comment := requestComment(ctx)
+	if comment != "" {
+		options.SetComment(comment)
+	}
 
+	ctx, finish := store.begin(ctx, "delete")
 	result, err := store.Collection.DeleteOne(ctx, bson.M{
 		"_id": id,
-	})
+	}, options)
+	finish(err)
 
 	if err != nil {
 		return 0, err
@@ -221,3 +312,59 @@ func (store MongoStore[T]) DeleteItem(id string) (int64, error) {
 
 	return result.DeletedCount, nil
 }
+
+// Description:
+//
+//	Begins a store operation.
+//	Starts a client span and returns a function which ends the span
+//	and notifies the operation observers of the mongo instance.
+//
+// Parameters:
+//
+//	ctx 	The request context.
+//	name 	The name of the operation.
+//
+// Returns:
+//
+//	The context holding the span, and the function finishing the operation with its error.
+func (store MongoStore[T]) begin(ctx context.Context, name string) (context.Context, func(err error)) {
+	start := time.Now()
+	collection := store.Collection.Name()
+
+	ctx, span := tracing.Tracer("store").Start(ctx, "mongo."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.name", store.Collection.Database().Name()),
+			attribute.String("db.mongodb.collection", collection),
+			attribute.String("db.operation", name),
+		),
+	)
+
+	return ctx, func(err error) {
+		tracing.End(span, err)
+		notifyObservers(store.instance, collection, name, start, err)
+	}
+}
+
+// Description:
+//
+//	Creates the comment attached to outbound commands, so that database logs
+//	and profiler entries can be correlated with the originating request.
+//
+// Parameters:
+//
+//	ctx The request context.
+//
+// Returns:
+//
+//	The comment, or an empty string if the context does not hold a request id.
+func requestComment(ctx context.Context) string {
+	id := parallel.IDFromContext(ctx)
+
+	if id == "" {
+		return ""
+	}
+
+	return "requestId:" + id
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// Description:
+//
+//	A completed store operation.
+//	Passed to operation observers after the operation finished.
+type Operation struct {
+
+	// The name of the collection.
+	Collection string
+
+	// The name of the operation, e.g. find or insert.
+	Name string
+
+	// The duration of the operation.
+	Duration time.Duration
+
+	// The error of the operation, nil if it succeeded.
+	Err error
+}
+
+// Description:
+//
+//	Function definition for operation observers.
+//	Observers are called once per store operation.
+type OperationObserver = func(operation *Operation)
+
+// Description:
+//
+//	A snapshot of the connection pool of a mongo instance, summed over all servers.
+type PoolStats struct {
+
+	// The number of open connections.
+	Open int64
+
+	// The number of connections currently checked out.
+	InUse int64
+}
+
+// Description:
+//
+//	The connection pool counters of a mongo instance.
+//	Updated by the pool monitor of the client.
+type poolCounters struct {
+
+	// The number of open connections.
+	open atomic.Int64
+
+	// The number of connections currently checked out.
+	inUse atomic.Int64
+}
+
+// Description:
+//
+//	Registers an observer which is called for every operation of the stores of this instance.
+//	Observers must be registered before the instance is used concurrently.
+//
+// Parameters:
+//
+//	observer The observer to register.
+func (instance *MongoInstance) Observe(observer OperationObserver) {
+	instance.observers = append(instance.observers, observer)
+}
+
+// Description:
+//
+//	Gets a snapshot of the connection pool.
+//
+// Returns:
+//
+//	The connection pool statistics. Zero if the instance was not created via NewMongoInstance.
+func (instance *MongoInstance) PoolStats() PoolStats {
+	if instance.pool == nil {
+		return PoolStats{}
+	}
+
+	return PoolStats{
+		Open:  instance.pool.open.Load(),
+		InUse: instance.pool.inUse.Load(),
+	}
+}
+
+// Description:
+//
+//	Creates a pool monitor updating the given counters.
+//
+// Parameters:
+//
+//	counters The counters to update.
+//
+// Returns:
+//
+//	The pool monitor, to be passed to the client options.
+func newPoolMonitor(counters *poolCounters) *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(poolEvent *event.PoolEvent) {
+			switch poolEvent.Type {
+			case event.ConnectionCreated:
+				counters.open.Add(1)
+			case event.ConnectionClosed:
+				counters.open.Add(-1)
+			case event.GetSucceeded:
+				counters.inUse.Add(1)
+			case event.ConnectionReturned:
+				counters.inUse.Add(-1)
+			}
+		},
+	}
+}
+
+// Description:
+//
+//	Notifies all observers of the given instance about a finished operation.
+//
+// Parameters:
+//
+//	instance 	The mongo instance, may be nil.
+//	collection 	The name of the collection.
+//	name 		The name of the operation.
+//	start 		The start time of the operation.
+//	err 		The error of the operation.
+func notifyObservers(instance *MongoInstance, collection string, name string, start time.Time, err error) {
+	if instance == nil || len(instance.observers) == 0 {
+		return
+	}
+
+	operation := &Operation{
+		Collection: collection,
+		Name:       name,
+		Duration:   time.Since(start),
+		Err:        err,
+	}
+
+	for _, observer := range instance.observers {
+		observer(operation)
+	}
+}
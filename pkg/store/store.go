@@ -0,0 +1,75 @@
+package store
+
+import (
+	"context"
+
+	"github.com/gostream-official/albums/pkg/store/query"
+)
+
+// Description:
+//
+//	A store of documents.
+//	Implemented by MongoStore, and by fakes in tests.
+//
+// Type Parameters:
+//
+//	T The type of document stored.
+type Store[T interface{}] interface {
+
+	// Description:
+	//
+	//	Creates a new item.
+	//
+	// Parameters:
+	//
+	//	ctx 	The request context.
+	//	item 	The item to create.
+	//
+	// Returns:
+	//
+	//	An error if creation fails.
+	CreateItem(ctx context.Context, item interface{}) error
+
+	// Description:
+	//
+	//	Updates a single item.
+	//
+	// Parameters:
+	//
+	//	ctx 	The request context.
+	//	filter 	The filter used for searching the documents to update.
+	//	update 	The update operator used for updating the filtered documents.
+	//
+	// Returns:
+	//
+	//	The number of modified documents, or an error if the update fails.
+	UpdateItem(ctx context.Context, filter *query.Filter, update *query.Update) (int64, error)
+
+	// Description:
+	//
+	//	Queries items in the store.
+	//
+	// Parameters:
+	//
+	//	ctx 	The request context.
+	//	filter 	The query filter to use.
+	//
+	// Returns:
+	//
+	//	All items matching the given query filter, or an error if the query fails.
+	FindItems(ctx context.Context, filter *query.Filter) ([]T, error)
+
+	// Description:
+	//
+	//	Deletes an item by its ID.
+	//
+	// Parameters:
+	//
+	//	ctx The request context.
+	//	id 	The ID of the document to delete.
+	//
+	// Returns:
+	//
+	//	The number of deleted documents, or an error if the request fails.
+	DeleteItem(ctx context.Context, id string) (int64, error)
+}
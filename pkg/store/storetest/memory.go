@@ -0,0 +1,652 @@
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/store/query"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Description:
+//
+//	An in-memory store for tests.
+//	Items are kept as bson documents and matched against the compiled filters,
+//	supporting equality (including array membership), $ne, $lt, $lte, $gt, $gte, $and and $or,
+//	and $set updates of top level and dotted fields.
+//
+// Type Parameters:
+//
+//	T The type of document stored.
+type MemoryStore[T interface{}] struct {
+
+	// Guards the documents and counters.
+	mutex sync.Mutex
+
+	// The stored documents, in insertion order.
+	documents []bson.M
+
+	// The number of calls per operation, e.g. "find".
+	calls map[string]int
+
+	// The error returned by every operation, if set.
+	err error
+}
+
+// Description:
+//
+//	Creates an in-memory store holding the given items.
+//
+// Parameters:
+//
+//	items The initial items.
+//
+// Type Parameters:
+//
+//	T The type of document stored.
+//
+// Returns:
+//
+//	The created store.
+func NewMemoryStore[T interface{}](items ...T) *MemoryStore[T] {
+	store := &MemoryStore[T]{
+		calls: make(map[string]int),
+	}
+
+	for _, item := range items {
+		err := store.CreateItem(context.Background(), item)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	store.calls = make(map[string]int)
+	return store
+}
+
+// Description:
+//
+//	Makes every subsequent operation fail with the given error.
+//
+// Parameters:
+//
+//	err The error, or nil to let operations succeed again.
+func (store *MemoryStore[T]) Fail(err error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.err = err
+}
+
+// Description:
+//
+//	Gets the number of calls of an operation.
+//
+// Parameters:
+//
+//	operation The operation, one of "insert", "update", "find" and "delete".
+//
+// Returns:
+//
+//	The number of calls.
+func (store *MemoryStore[T]) Calls(operation string) int {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.calls[operation]
+}
+
+// Description:
+//
+//	Gets all stored items.
+//
+// Returns:
+//
+//	The stored items, in insertion order.
+func (store *MemoryStore[T]) Items() []T {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	items, err := decodeAll[T](store.documents)
+	if err != nil {
+		panic(err)
+	}
+
+	return items
+}
+
+// Description:
+//
+//	Creates a new item.
+//
+// Parameters:
+//
+//	ctx 	The request context.
+//	item 	The item to create.
+//
+// Returns:
+//
+//	An error if the item cannot be encoded, its id exists or the store fails.
+func (store *MemoryStore[T]) CreateItem(ctx context.Context, item interface{}) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.calls["insert"]++
+	if store.err != nil {
+		return store.err
+	}
+
+	document, err := toDocument(item)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range store.documents {
+		if _, ok := document["_id"]; ok && reflect.DeepEqual(existing["_id"], document["_id"]) {
+			return fmt.Errorf("storetest: duplicate key %v", document["_id"])
+		}
+	}
+
+	store.documents = append(store.documents, document)
+	return nil
+}
+
+// Description:
+//
+//	Updates the first item matching the filter.
+//
+// Parameters:
+//
+//	ctx 	The request context.
+//	filter 	The filter used for searching the document to update.
+//	update 	The update operator.
+//
+// Returns:
+//
+//	The number of modified documents, or an error if the store fails.
+func (store *MemoryStore[T]) UpdateItem(ctx context.Context, filter *query.Filter, update *query.Update) (int64, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.calls["update"]++
+	if store.err != nil {
+		return 0, store.err
+	}
+
+	for _, document := range store.documents {
+		if !matches(document, compileFilter(filter)) {
+			continue
+		}
+
+		if update.Root == nil {
+			return 0, nil
+		}
+
+		for operator, fields := range update.Root.Compile() {
+			if operator != "$set" {
+				return 0, fmt.Errorf("storetest: unsupported update operator %s", operator)
+			}
+
+			set, err := toDocument(fields)
+			if err != nil {
+				return 0, err
+			}
+
+			for key, value := range set {
+				setField(document, key, value)
+			}
+		}
+
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+// Description:
+//
+//	Queries items in the store.
+//
+// Parameters:
+//
+//	ctx 	The request context.
+//	filter 	The query filter to use.
+//
+// Returns:
+//
+//	All items matching the filter, up to its limit, or an error if the store fails.
+func (store *MemoryStore[T]) FindItems(ctx context.Context, filter *query.Filter) ([]T, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.calls["find"]++
+	if store.err != nil {
+		return nil, store.err
+	}
+
+	compiled := compileFilter(filter)
+	matched := make([]bson.M, 0)
+
+	for _, document := range store.documents {
+		if filter.Limit > 0 && len(matched) == int(filter.Limit) {
+			break
+		}
+
+		if matches(document, compiled) {
+			matched = append(matched, document)
+		}
+	}
+
+	return decodeAll[T](matched)
+}
+
+// Description:
+//
+//	Deletes an item by its ID.
+//
+// Parameters:
+//
+//	ctx The request context.
+//	id 	The ID of the document to delete.
+//
+// Returns:
+//
+//	The number of deleted documents, or an error if the store fails.
+func (store *MemoryStore[T]) DeleteItem(ctx context.Context, id string) (int64, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.calls["delete"]++
+	if store.err != nil {
+		return 0, store.err
+	}
+
+	for index, document := range store.documents {
+		if document["_id"] == id {
+			store.documents = append(store.documents[:index], store.documents[index+1:]...)
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// Description:
+//
+//	Compiles the root of a filter.
+//
+// Parameters:
+//
+//	filter The filter.
+//
+// Returns:
+//
+//	The compiled filter, empty if the filter has no root.
+func compileFilter(filter *query.Filter) bson.M {
+	if filter.Root == nil {
+		return bson.M{}
+	}
+
+	return filter.Root.Compile()
+}
+
+// Description:
+//
+//	Converts a value into a bson document, the way the driver would store it.
+//
+// Parameters:
+//
+//	value The value to convert.
+//
+// Returns:
+//
+//	The document, or an error if the value cannot be encoded.
+func toDocument(value interface{}) (bson.M, error) {
+	data, err := bson.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	document := bson.M{}
+	err = bson.Unmarshal(data, &document)
+
+	return document, err
+}
+
+// Description:
+//
+//	Decodes bson documents into items.
+//
+// Parameters:
+//
+//	documents The documents to decode.
+//
+// Type Parameters:
+//
+//	T The type of the items.
+//
+// Returns:
+//
+//	The decoded items, or an error if a document cannot be decoded.
+func decodeAll[T interface{}](documents []bson.M) ([]T, error) {
+	items := make([]T, 0, len(documents))
+
+	for _, document := range documents {
+		data, err := bson.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+
+		var item T
+		err = bson.Unmarshal(data, &item)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// Description:
+//
+//	Checks whether a document matches a compiled filter.
+//
+// Parameters:
+//
+//	document 	The document.
+//	filter 		The compiled filter.
+//
+// Returns:
+//
+//	Whether the document matches.
+func matches(document bson.M, filter bson.M) bool {
+	for key, condition := range filter {
+		switch key {
+		case "$and":
+			for _, sub := range condition.([]bson.M) {
+				if !matches(document, sub) {
+					return false
+				}
+			}
+
+		case "$or":
+			any := false
+			for _, sub := range condition.([]bson.M) {
+				if matches(document, sub) {
+					any = true
+					break
+				}
+			}
+
+			if !any {
+				return false
+			}
+
+		default:
+			if !matchesField(getField(document, key), condition) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Description:
+//
+//	Checks whether a field value matches a condition.
+//	A plain condition matches equal values and arrays containing it.
+//
+// Parameters:
+//
+//	value 		The field value, nil if the field is missing.
+//	condition 	The condition, a plain value or an operator document.
+//
+// Returns:
+//
+//	Whether the value matches.
+func matchesField(value interface{}, condition interface{}) bool {
+	operators, ok := condition.(bson.M)
+	if !ok {
+		return equals(value, condition)
+	}
+
+	for operator, operand := range operators {
+		var result bool
+
+		switch operator {
+		case "$eq":
+			result = equals(value, operand)
+		case "$ne":
+			result = !equals(value, operand)
+		case "$lt":
+			result = compare(value, operand) < 0
+		case "$lte":
+			result = compare(value, operand) <= 0
+		case "$gt":
+			result = compare(value, operand) > 0
+		case "$gte":
+			result = compare(value, operand) >= 0
+		default:
+			panic("storetest: unsupported filter operator " + operator)
+		}
+
+		if !result {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Description:
+//
+//	Checks whether a field value equals an operand.
+//	Arrays equal operands they contain, nil equals missing fields.
+//
+// Parameters:
+//
+//	value 	The field value.
+//	operand The operand.
+//
+// Returns:
+//
+//	Whether the value equals the operand.
+func equals(value interface{}, operand interface{}) bool {
+	if array, ok := value.(bson.A); ok {
+		for _, element := range array {
+			if equals(element, operand) {
+				return true
+			}
+		}
+	}
+
+	operand = normalize(operand)
+
+	if value == nil || operand == nil {
+		return value == nil && operand == nil
+	}
+
+	return compare(value, operand) == 0 || reflect.DeepEqual(value, operand)
+}
+
+// Description:
+//
+//	Compares two scalar values of the same kind.
+//
+// Parameters:
+//
+//	value 	The field value.
+//	operand The operand.
+//
+// Returns:
+//
+//	A negative number, zero or a positive number, if the value is less than, equal to or greater than the operand.
+//	Values of different or unsupported kinds are never equal.
+func compare(value interface{}, operand interface{}) int {
+	operand = normalize(operand)
+
+	switch left := value.(type) {
+	case string:
+		if right, ok := operand.(string); ok {
+			return strings.Compare(left, right)
+		}
+
+	case int32, int64, float64:
+		leftNumber, _ := number(left)
+		rightNumber, ok := number(operand)
+
+		if ok {
+			switch {
+			case leftNumber < rightNumber:
+				return -1
+			case leftNumber > rightNumber:
+				return 1
+			default:
+				return 0
+			}
+		}
+
+	case bool:
+		if right, ok := operand.(bool); ok && left == right {
+			return 0
+		}
+
+	default:
+		leftTime, leftOk := timeOf(value)
+		rightTime, rightOk := timeOf(operand)
+
+		if leftOk && rightOk {
+			return leftTime.Compare(rightTime)
+		}
+	}
+
+	return 1
+}
+
+// Description:
+//
+//	Converts an operand into the representation of a decoded document value.
+//
+// Parameters:
+//
+//	operand The operand.
+//
+// Returns:
+//
+//	The converted operand, or the operand itself if it cannot be encoded.
+func normalize(operand interface{}) interface{} {
+	document, err := toDocument(bson.M{"v": operand})
+	if err != nil {
+		return operand
+	}
+
+	return document["v"]
+}
+
+// Description:
+//
+//	Converts a decoded bson number to a float.
+//
+// Parameters:
+//
+//	value The value.
+//
+// Returns:
+//
+//	The number, and whether the value is a number.
+func number(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case int32:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case float64:
+		return number, true
+	}
+
+	return 0, false
+}
+
+// Description:
+//
+//	Converts a decoded bson datetime to a time.
+//
+// Parameters:
+//
+//	value The value.
+//
+// Returns:
+//
+//	The time, and whether the value is a datetime.
+func timeOf(value interface{}) (time.Time, bool) {
+	type datetime interface {
+		Time() time.Time
+	}
+
+	if converted, ok := value.(datetime); ok {
+		return converted.Time(), true
+	}
+
+	return time.Time{}, false
+}
+
+// Description:
+//
+//	Gets a possibly dotted field of a document.
+//
+// Parameters:
+//
+//	document 	The document.
+//	key 		The field, e.g. stats.popularity.
+//
+// Returns:
+//
+//	The field value, or nil if the field is missing.
+func getField(document bson.M, key string) interface{} {
+	parts := strings.Split(key, ".")
+
+	var current interface{} = document
+	for _, part := range parts {
+		nested, ok := current.(bson.M)
+		if !ok {
+			return nil
+		}
+
+		current = nested[part]
+	}
+
+	return current
+}
+
+// Description:
+//
+//	Sets a possibly dotted field of a document, creating missing parents.
+//
+// Parameters:
+//
+//	document 	The document.
+//	key 		The field, e.g. stats.popularity.
+//	value 		The value.
+func setField(document bson.M, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+
+	current := document
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := current[part].(bson.M)
+		if !ok {
+			nested = bson.M{}
+			current[part] = nested
+		}
+
+		current = nested
+	}
+
+	current[parts[len(parts)-1]] = value
+}
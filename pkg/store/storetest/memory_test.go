@@ -0,0 +1,107 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gostream-official/albums/pkg/store/query"
+)
+
+// Description:
+//
+//	A document used to test the memory store.
+type document struct {
+
+	// The id of the document.
+	ID string `bson:"_id"`
+
+	// The tags of the document.
+	Tags []string `bson:"tags"`
+
+	// The nested statistics of the document.
+	Stats stats `bson:"stats"`
+
+	// The revocation time, or nil.
+	RevokedAt *time.Time `bson:"revokedAt,omitempty"`
+}
+
+// Description:
+//
+//	Nested statistics of a test document.
+type stats struct {
+
+	// A counter.
+	Count int `bson:"count"`
+}
+
+func TestFindItemsMatchesFilters(t *testing.T) {
+	revoked := time.Unix(100, 0)
+	store := NewMemoryStore(
+		document{ID: "a", Tags: []string{"x"}, Stats: stats{Count: 1}},
+		document{ID: "b", Tags: []string{"y"}, Stats: stats{Count: 2}},
+		document{ID: "c", Tags: []string{"x", "y"}, Stats: stats{Count: 3}, RevokedAt: &revoked},
+	)
+
+	tests := []struct {
+		name     string
+		filter   query.Filter
+		expected []string
+	}{
+		{"all", query.Filter{}, []string{"a", "b", "c"}},
+		{"limit", query.Filter{Limit: 2}, []string{"a", "b"}},
+		{"eq", query.Filter{Root: query.FilterOperatorEq{Key: "_id", Value: "b"}}, []string{"b"}},
+		{"array membership", query.Filter{Root: query.FilterOperatorEq{Key: "tags", Value: "x"}}, []string{"a", "c"}},
+		{"missing field", query.Filter{Root: query.FilterOperatorEq{Key: "revokedAt", Value: nil}}, []string{"a", "b"}},
+		{"dotted gt", query.Filter{Root: query.FilterOperatorGt{Key: "stats.count", Value: 1}}, []string{"b", "c"}},
+		{"or", query.Filter{Root: query.FilterOperatorOr{Or: []query.IQuery{
+			query.FilterOperatorEq{Key: "_id", Value: "a"},
+			query.FilterOperatorEq{Key: "_id", Value: "c"},
+		}}}, []string{"a", "c"}},
+		{"and", query.Filter{Root: query.FilterOperatorAnd{And: []query.IQuery{
+			query.FilterOperatorEq{Key: "tags", Value: "y"},
+			query.FilterOperatorNeq{Key: "_id", Value: "c"},
+		}}}, []string{"b"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, err := store.FindItems(context.Background(), &test.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			ids := make([]string, 0)
+			for _, item := range items {
+				ids = append(ids, item.ID)
+			}
+
+			if len(ids) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, ids)
+			}
+
+			for index := range ids {
+				if ids[index] != test.expected[index] {
+					t.Fatalf("expected %v, got %v", test.expected, ids)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateItemSetsDottedFields(t *testing.T) {
+	store := NewMemoryStore(document{ID: "a", Stats: stats{Count: 1}})
+
+	count, err := store.UpdateItem(context.Background(),
+		&query.Filter{Root: query.FilterOperatorEq{Key: "_id", Value: "a"}},
+		&query.Update{Root: query.UpdateOperatorSet{Set: map[string]interface{}{"stats.count": 5}}},
+	)
+
+	if err != nil || count != 1 {
+		t.Fatalf("expected 1 modified item, got %d (%v)", count, err)
+	}
+
+	if items := store.Items(); items[0].Stats.Count != 5 {
+		t.Fatalf("expected count 5, got %d", items[0].Stats.Count)
+	}
+}
@@ -0,0 +1,176 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Description:
+//
+//	The span exporter used for traces.
+type Exporter = string
+
+const (
+
+	// Spans are not recorded. The global tracer provider remains a no-op.
+	ExporterNone Exporter = "none"
+
+	// Spans are written to stdout, one JSON object per span.
+	ExporterStdout Exporter = "stdout"
+
+	// Spans are exported via OTLP over HTTP.
+	ExporterOTLP Exporter = "otlp"
+)
+
+const (
+
+	// The instrumentation scope prefix of all tracers of this module.
+	scopePrefix = "github.com/gostream-official/albums/"
+)
+
+// Description:
+//
+//	The tracing configuration.
+type Config struct {
+
+	// The span exporter.
+	Exporter Exporter
+
+	// The service name attached to all spans.
+	ServiceName string
+
+	// The OTLP collector endpoint, host and port, e.g. localhost:4318.
+	// Only used by the otlp exporter.
+	Endpoint string
+
+	// Whether the OTLP collector is reached via plain HTTP.
+	// Only used by the otlp exporter.
+	Insecure bool
+
+	// The ratio of root spans which are sampled, between 0 and 1.
+	// Child spans follow the sampling decision of their parent.
+	SampleRatio float64
+}
+
+// Description:
+//
+//	Function definition for the shutdown of the tracer provider.
+//	Flushes all pending spans.
+type ShutdownFunc = func(ctx context.Context) error
+
+// Description:
+//
+//	Sets up the global tracer provider and the W3C trace context propagator.
+//	With the none exporter, spans are not recorded, but incoming trace context is still propagated.
+//
+// Parameters:
+//
+//	config The tracing configuration.
+//
+// Returns:
+//
+//	The shutdown function, flushing pending spans, or an error if the exporter cannot be created.
+func Setup(config Config) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch config.Exporter {
+	case ExporterNone, "":
+		return func(ctx context.Context) error { return nil }, nil
+
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+
+	case ExporterOTLP:
+		options := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(config.Endpoint),
+		}
+
+		if config.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err = otlptracehttp.New(context.Background(), options...)
+
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter: %s", config.Exporter)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("tracing: cannot create exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(config.ServiceName))),
+	)
+
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Description:
+//
+//	Gets a tracer of this module.
+//	Resolved on every call, so that tracers created before Setup use the configured provider.
+//
+// Parameters:
+//
+//	name The name of the instrumented package, e.g. router or store.
+//
+// Returns:
+//
+//	The tracer.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(scopePrefix + name)
+}
+
+// Description:
+//
+//	Starts a span for a single step of a handler.
+//	The span must be ended via End.
+//
+// Parameters:
+//
+//	ctx 		The parent context, usually the request context.
+//	name 		The name of the step, e.g. createalbum.decode.
+//	attributes 	Additional span attributes.
+//
+// Returns:
+//
+//	The context holding the span, and the span.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer("handler").Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// Description:
+//
+//	Ends a span, recording the given error if it is not nil.
+//
+// Parameters:
+//
+//	span 	The span to end.
+//	err 	The error of the traced step, may be nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}